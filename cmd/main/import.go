@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/repository/db"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+const importActor = "cli-import"
+
+// runImport loads incidents from a file straight into the database and prints the report.
+//
+//	incidents_service import -file incidents.geojson [-format geojson|csv|kml] [-mode atomic|best_effort] [-tenant default]
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "path to GeoJSON, CSV or KML file")
+	format := fs.String("format", "", "file format, detected by extension when empty")
+	mode := fs.String("mode", service.ImportModeAtomic, "atomic or best_effort")
+	tenant := fs.String("tenant", identity.DefaultTenant, "tenant that owns imported incidents")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("file cannot be empty")
+	}
+	if *format == "" {
+		*format = geoformat.FormatFromFileName(*file)
+	}
+	ctx, err := importContext(*tenant)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.NewConfig(true)
+	if err != nil {
+		return err
+	}
+	database, err := db.NewDB(cfg.ConnectionStr)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	serv := service.NewService(database, nil, cfg, nil)
+	report, err := serv.ImportIncidents(ctx, *format, *mode, f)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("import finished with %d failed rows", report.Failed)
+	}
+	return nil
+}
+
+// importContext gives the CLI the rights of an admin of the tenant: whoever runs it already
+// has the database credentials, and the service still checks permissions of every caller with identity.
+func importContext(tenant string) (context.Context, error) {
+	if !identity.IsValidTenant(tenant) {
+		return nil, fmt.Errorf("invalid tenant %q: must be a lowercase slug of latin letters, digits, - and _", tenant)
+	}
+	return identity.WithIdentity(context.Background(), &identity.Identity{
+		Subject: importActor,
+		Role:    identity.RoleAdmin,
+		Tenant:  tenant,
+		Scopes:  identity.Scopes,
+	}), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+func TestImportContext(t *testing.T) {
+	ctx, err := importContext("moscow")
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	mockDb := repository.NewMockDb()
+	svc := service.NewService(mockDb, nil, &config.Config{MaxRadius: 5000, DefaultRadius: 100}, nil)
+
+	report, err := svc.ImportIncidents(ctx, "csv", service.ImportModeAtomic, strings.NewReader("lat,lon,name,type\n55.75,37.61,Пожар,fire\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if report.Created != 1 {
+		t.Errorf("created: got: %d, expect: 1\n", report.Created)
+	}
+	for _, incident := range mockDb.Storage {
+		if incident.TenantID != "moscow" {
+			t.Errorf("tenant: got: %s, expect: moscow\n", incident.TenantID)
+		}
+	}
+	for _, record := range mockDb.Audit {
+		if record.Actor != importActor {
+			t.Errorf("actor: got: %s, expect: %s\n", record.Actor, importActor)
+		}
+	}
+
+	if _, err := importContext("Moscow!"); err == nil {
+		t.Errorf("expected error for invalid tenant\n")
+	}
+}
@@ -2,11 +2,18 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/Piccadilly98/incidents_service/internal/server"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	ch, err := server.ServerStart()
 	if err != nil {
 		log.Fatal(err)
@@ -1,13 +1,17 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Piccadilly98/incidents_service/internal/identity"
 	"github.com/joho/godotenv"
 )
 
@@ -28,9 +32,50 @@ const (
 	EnvRedisPassword             = "REDIS_PASSWORD"
 	EnvRedisTTL                  = "REDIS_TTL"
 
+	EnvNameRequireIfMatch           = "REQUIRE_IF_MATCH"
+	EnvNameTrashRetentionDays       = "TRASH_RETENTION_DAYS"
+	EnvNameRetentionIntervalMinutes = "RETENTION_INTERVAL_MINUTES"
+
+	// EnvNameBootstrapAPIKey is stored as a key with every scope on start, it is never printed
+	EnvNameBootstrapAPIKey = "API_KEY"
+
+	// bearer tokens are accepted only when EnvNameJWTJWKS is set
+	EnvNameJWTJWKS               = "JWT_JWKS"
+	EnvNameJWTIssuer             = "JWT_ISSUER"
+	EnvNameJWTAudience           = "JWT_AUDIENCE"
+	EnvNameJWTRolesClaim         = "JWT_ROLES_CLAIM"
+	EnvNameJWTRoleScopes         = "JWT_ROLE_SCOPES"
+	EnvNameJWTRoles              = "JWT_ROLES"
+	EnvNameJWTTenantClaim        = "JWT_TENANT_CLAIM"
+	EnvNameJWTJWKSRefreshMinutes = "JWT_JWKS_REFRESH_MINUTES"
+
 	EnvNameStatsTime        = "STATS_TIME_WINDOW_MINUTES"
 	EnvNameLoggingUserError = "LOGGING_USER_ERROR"
 
+	// EnvNameTenantsFile points to a json file with per tenant overrides of the settings above
+	EnvNameTenantsFile = "TENANTS_FILE"
+
+	EnvNameRateLimits              = "RATE_LIMITS"
+	EnvNameRateLimitTrustForwarded = "RATE_LIMIT_TRUST_FORWARDED"
+
+	// EnvNameAppSigningKeys holds HMAC secrets of mobile apps, they are never printed
+	EnvNameAppSigningKeys         = "APP_SIGNING_KEYS"
+	EnvNameRequireSignedChecks    = "REQUIRE_SIGNED_CHECKS"
+	EnvNameSignatureMaxSkewSecond = "SIGNATURE_MAX_SKEW_SECONDS"
+
+	EnvNameHotspotIntervalMinutes = "HOTSPOT_INTERVAL_MINUTES"
+	EnvNameHotspotWindowMinutes   = "HOTSPOT_WINDOW_MINUTES"
+	EnvNameHotspotRadiusMeters    = "HOTSPOT_RADIUS_METERS"
+	EnvNameHotspotMinChecks       = "HOTSPOT_MIN_CHECKS"
+	EnvNameHotspotMinUsers        = "HOTSPOT_MIN_USERS"
+
+	EnvNameChecksPartitionInterval = "CHECKS_PARTITION_INTERVAL"
+	EnvNameChecksPartitionsAhead   = "CHECKS_PARTITIONS_AHEAD"
+	EnvNameChecksRetentionDays     = "CHECKS_RETENTION_DAYS"
+	EnvNameChecksRetentionMode     = "CHECKS_RETENTION_MODE"
+	EnvNameChecksCoarsenAfterDays  = "CHECKS_COARSEN_AFTER_DAYS"
+	EnvNameChecksCoarsenDecimals   = "CHECKS_COARSEN_DECIMALS"
+
 	EnvNameDbName     = "DB_NAME"
 	EnvNameDbSSlMode  = "DB_SSLMODE"
 	EnvNameDbPort     = "DB_PORT"
@@ -54,6 +99,36 @@ const (
 	DefaultServerAddr      = "localhost"
 	DefaultServerPort      = "8080"
 
+	DefaultRequireIfMatch           = false
+	DefaultTrashRetentionDays       = 30
+	DefaultRetentionIntervalMinutes = 60
+
+	DefaultJWTRolesClaim         = "roles"
+	DefaultJWTTenantClaim        = "tenant"
+	DefaultJWTJWKSRefreshMinutes = 60
+
+	DefaultRateLimits              = RateLimitRouteLocationCheck + "=60/1m"
+	DefaultRateLimitTrustForwarded = false
+
+	DefaultRequireSignedChecks     = false
+	DefaultSignatureMaxSkewSeconds = 300
+	MinAppSigningSecretLen         = 32
+
+	DefaultHotspotIntervalMinutes = 15
+	DefaultHotspotWindowMinutes   = 60
+	DefaultHotspotRadiusMeters    = 100
+	DefaultHotspotMinChecks       = 20
+	DefaultHotspotMinUsers        = 5
+
+	DefaultChecksPartitionInterval = PartitionIntervalMonth
+	DefaultChecksPartitionsAhead   = 3
+	// zero keeps checks forever and disables coarsening
+	DefaultChecksRetentionDays    = 0
+	DefaultChecksRetentionMode    = RetentionModeArchive
+	DefaultChecksCoarsenAfterDays = 0
+	DefaultChecksCoarsenDecimals  = 3
+	MaxChecksCoarsenDecimals      = 7
+
 	DefaultStatsTime        = 100
 	MaxStatsTime            = 999_999_999
 	DefaultLoggingUserError = false
@@ -74,6 +149,113 @@ type Config struct {
 	WebhookMaxReTry  int
 	ServerAddr       string
 	ServerPort       string
+
+	TrashRetentionDays       int
+	RetentionIntervalMinutes int
+	RequireIfMatch           bool
+	BootstrapAPIKey          string
+
+	JWTJWKS               string
+	JWTIssuer             string
+	JWTAudience           string
+	JWTRolesClaim         string
+	JWTRoleScopes         map[string][]string
+	JWTRoles              map[string]string
+	JWTTenantClaim        string
+	JWTJWKSRefreshMinutes int
+
+	Tenants map[string]*TenantConfig
+
+	RateLimits              map[string]RateLimit
+	RateLimitTrustForwarded bool
+
+	AppSigningKeys          map[string]AppSigningKey
+	RequireSignedChecks     bool
+	SignatureMaxSkewSeconds int
+
+	HotspotIntervalMinutes int
+	HotspotWindowMinutes   int
+	HotspotRadiusMeters    int
+	HotspotMinChecks       int
+	HotspotMinUsers        int
+
+	ChecksPartitionInterval string
+	ChecksPartitionsAhead   int
+	ChecksRetentionDays     int
+	ChecksRetentionMode     string
+	ChecksCoarsenAfterDays  int
+	ChecksCoarsenDecimals   int
+}
+
+// checks partitions cover one calendar month or one day in UTC
+const (
+	PartitionIntervalMonth = "month"
+	PartitionIntervalDay   = "day"
+)
+
+// partitions older than the retention period are dropped, or detached and kept in the archive schema
+const (
+	RetentionModeDrop    = "drop"
+	RetentionModeArchive = "archive"
+)
+
+// routes that can be limited with RATE_LIMITS
+const (
+	RateLimitRouteLocationCheck = "location_check"
+)
+
+var RateLimitRoutes = []string{RateLimitRouteLocationCheck}
+
+// RateLimit allows Requests per Period for every client, unused requests do not pile up over Requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// AppSigningKey is the HMAC secret of a mobile app, the app can sign checks of its tenant only.
+type AppSigningKey struct {
+	Secret string
+	Tenant string
+}
+
+// TenantConfig overrides the global settings for one tenant, zero values keep the global ones.
+type TenantConfig struct {
+	MaxRadius       int    `json:"max_radius"`
+	DefaultRadius   int    `json:"default_radius"`
+	StatsTimeWindow int    `json:"stats_time_window_minutes"`
+	WebhookURL      string `json:"webhook_url"`
+	WebhookMethod   string `json:"webhook_method"`
+}
+
+// ForTenant returns a copy of the config with the overrides of the tenant applied.
+func (c *Config) ForTenant(tenant string) *Config {
+	if c == nil {
+		return nil
+	}
+	res := *c
+	override, ok := c.Tenants[tenant]
+	if !ok {
+		return &res
+	}
+	if override.MaxRadius != 0 {
+		res.MaxRadius = override.MaxRadius
+	}
+	if override.DefaultRadius != 0 {
+		res.DefaultRadius = override.DefaultRadius
+	}
+	if res.DefaultRadius > res.MaxRadius {
+		res.DefaultRadius = res.MaxRadius
+	}
+	if override.StatsTimeWindow != 0 {
+		res.StatsTimeWindow = override.StatsTimeWindow
+	}
+	if override.WebhookURL != "" {
+		res.WebhookURL = override.WebhookURL
+	}
+	if override.WebhookMethod != "" {
+		res.WebhookMethod = override.WebhookMethod
+	}
+	return &res
 }
 
 func NewConfig(envCfg bool) (*Config, error) {
@@ -183,6 +365,7 @@ func NewConfig(envCfg bool) (*Config, error) {
 		log.Printf("invalid MAX_ROWS_IN_PAGE on env: <%s>, change to default: %d\n", maxRowsPageStr, maxRowsPage)
 	}
 	loggingUserError := getBoolEnv(EnvNameLoggingUserError, DefaultLoggingUserError)
+	requireIfMatch := getBoolEnv(EnvNameRequireIfMatch, DefaultRequireIfMatch)
 
 	statsTimeWindow := DefaultStatsTime
 	statsTimeWindowStr := os.Getenv(EnvNameStatsTime)
@@ -241,6 +424,121 @@ func NewConfig(envCfg bool) (*Config, error) {
 		log.Printf("invalid WEBHOOK_MAX_RETRY on env: <%s>, change to default: %d\n", webhookMaxReTryStr, DefaultWebhookMaxReTry)
 	}
 
+	trashRetentionDays, err := getPositiveIntEnv(EnvNameTrashRetentionDays, DefaultTrashRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	retentionInterval, err := getPositiveIntEnv(EnvNameRetentionIntervalMinutes, DefaultRetentionIntervalMinutes)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtJWKS := os.Getenv(EnvNameJWTJWKS)
+	jwtIssuer := os.Getenv(EnvNameJWTIssuer)
+	jwtAudience := os.Getenv(EnvNameJWTAudience)
+	if jwtJWKS != "" && (jwtIssuer == "" || jwtAudience == "") {
+		return nil, fmt.Errorf("%s and %s must be set with %s", EnvNameJWTIssuer, EnvNameJWTAudience, EnvNameJWTJWKS)
+	}
+	jwtRolesClaim := os.Getenv(EnvNameJWTRolesClaim)
+	if jwtRolesClaim == "" {
+		jwtRolesClaim = DefaultJWTRolesClaim
+	}
+	jwtRoleScopes, err := parseRoleScopes(os.Getenv(EnvNameJWTRoleScopes))
+	if err != nil {
+		return nil, err
+	}
+	jwtRoles, err := parseRoles(os.Getenv(EnvNameJWTRoles))
+	if err != nil {
+		return nil, err
+	}
+	jwtTenantClaim := os.Getenv(EnvNameJWTTenantClaim)
+	if jwtTenantClaim == "" {
+		jwtTenantClaim = DefaultJWTTenantClaim
+	}
+	jwtRefresh := DefaultJWTJWKSRefreshMinutes
+	if jwtJWKS != "" {
+		jwtRefresh, err = getPositiveIntEnv(EnvNameJWTJWKSRefreshMinutes, DefaultJWTJWKSRefreshMinutes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tenants, err := loadTenants(os.Getenv(EnvNameTenantsFile))
+	if err != nil {
+		return nil, err
+	}
+	rateLimitsStr, ok := os.LookupEnv(EnvNameRateLimits)
+	if !ok {
+		rateLimitsStr = DefaultRateLimits
+	}
+	rateLimits, err := parseRateLimits(rateLimitsStr)
+	if err != nil {
+		return nil, err
+	}
+	appSigningKeys, err := parseAppSigningKeys(os.Getenv(EnvNameAppSigningKeys))
+	if err != nil {
+		return nil, err
+	}
+	requireSignedChecks := getBoolEnv(EnvNameRequireSignedChecks, DefaultRequireSignedChecks)
+	if requireSignedChecks && len(appSigningKeys) == 0 {
+		return nil, fmt.Errorf("%s must be set with %s", EnvNameAppSigningKeys, EnvNameRequireSignedChecks)
+	}
+	signatureMaxSkew := DefaultSignatureMaxSkewSeconds
+	if len(appSigningKeys) != 0 {
+		signatureMaxSkew, err = getPositiveIntEnv(EnvNameSignatureMaxSkewSecond, DefaultSignatureMaxSkewSeconds)
+		if err != nil {
+			return nil, err
+		}
+	}
+	hotspotInterval, err := getPositiveIntEnv(EnvNameHotspotIntervalMinutes, DefaultHotspotIntervalMinutes)
+	if err != nil {
+		return nil, err
+	}
+	hotspotWindow, err := getPositiveIntEnv(EnvNameHotspotWindowMinutes, DefaultHotspotWindowMinutes)
+	if err != nil {
+		return nil, err
+	}
+	hotspotRadius, err := getPositiveIntEnv(EnvNameHotspotRadiusMeters, DefaultHotspotRadiusMeters)
+	if err != nil {
+		return nil, err
+	}
+	hotspotMinChecks, err := getPositiveIntEnv(EnvNameHotspotMinChecks, DefaultHotspotMinChecks)
+	if err != nil {
+		return nil, err
+	}
+	hotspotMinUsers, err := getPositiveIntEnv(EnvNameHotspotMinUsers, DefaultHotspotMinUsers)
+	if err != nil {
+		return nil, err
+	}
+	partitionInterval, err := getOneOfEnv(EnvNameChecksPartitionInterval, DefaultChecksPartitionInterval,
+		PartitionIntervalMonth, PartitionIntervalDay)
+	if err != nil {
+		return nil, err
+	}
+	partitionsAhead, err := getPositiveIntEnv(EnvNameChecksPartitionsAhead, DefaultChecksPartitionsAhead)
+	if err != nil {
+		return nil, err
+	}
+	checksRetentionDays, err := getNonNegativeIntEnv(EnvNameChecksRetentionDays, DefaultChecksRetentionDays)
+	if err != nil {
+		return nil, err
+	}
+	checksRetentionMode, err := getOneOfEnv(EnvNameChecksRetentionMode, DefaultChecksRetentionMode,
+		RetentionModeDrop, RetentionModeArchive)
+	if err != nil {
+		return nil, err
+	}
+	coarsenAfterDays, err := getNonNegativeIntEnv(EnvNameChecksCoarsenAfterDays, DefaultChecksCoarsenAfterDays)
+	if err != nil {
+		return nil, err
+	}
+	coarsenDecimals, err := getNonNegativeIntEnv(EnvNameChecksCoarsenDecimals, DefaultChecksCoarsenDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if coarsenDecimals > MaxChecksCoarsenDecimals {
+		return nil, fmt.Errorf("invalid %s: > %d\n", EnvNameChecksCoarsenDecimals, MaxChecksCoarsenDecimals)
+	}
+
 	conf := &Config{
 		ConnectionStr:    fmt.Sprintf("user=%s port=%s password=%s dbname=%s host=%s sslmode=%s", dbUser, dbPort, dbPassword, nameDb, dbHost, dbSsl),
 		WebhookURL:       webhookURL,
@@ -256,6 +554,42 @@ func NewConfig(envCfg bool) (*Config, error) {
 		WebhookMaxReTry:  webhookMaxReTry,
 		ServerAddr:       serverAddr,
 		ServerPort:       serverPort,
+
+		TrashRetentionDays:       trashRetentionDays,
+		RetentionIntervalMinutes: retentionInterval,
+		RequireIfMatch:           requireIfMatch,
+		BootstrapAPIKey:          os.Getenv(EnvNameBootstrapAPIKey),
+
+		JWTJWKS:               jwtJWKS,
+		JWTIssuer:             jwtIssuer,
+		JWTAudience:           jwtAudience,
+		JWTRolesClaim:         jwtRolesClaim,
+		JWTRoleScopes:         jwtRoleScopes,
+		JWTRoles:              jwtRoles,
+		JWTTenantClaim:        jwtTenantClaim,
+		JWTJWKSRefreshMinutes: jwtRefresh,
+
+		Tenants: tenants,
+
+		RateLimits:              rateLimits,
+		RateLimitTrustForwarded: getBoolEnv(EnvNameRateLimitTrustForwarded, DefaultRateLimitTrustForwarded),
+
+		AppSigningKeys:          appSigningKeys,
+		RequireSignedChecks:     requireSignedChecks,
+		SignatureMaxSkewSeconds: signatureMaxSkew,
+
+		HotspotIntervalMinutes: hotspotInterval,
+		HotspotWindowMinutes:   hotspotWindow,
+		HotspotRadiusMeters:    hotspotRadius,
+		HotspotMinChecks:       hotspotMinChecks,
+		HotspotMinUsers:        hotspotMinUsers,
+
+		ChecksPartitionInterval: partitionInterval,
+		ChecksPartitionsAhead:   partitionsAhead,
+		ChecksRetentionDays:     checksRetentionDays,
+		ChecksRetentionMode:     checksRetentionMode,
+		ChecksCoarsenAfterDays:  coarsenAfterDays,
+		ChecksCoarsenDecimals:   coarsenDecimals,
 	}
 	return conf, nil
 }
@@ -299,6 +633,49 @@ func validationPort(key, defaultValue string) (string, error) {
 	return port, nil
 }
 
+func getPositiveIntEnv(key string, defaultValue int) (int, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		log.Printf("invalid %s on env: <%s>, change to default: %d\n", key, val, defaultValue)
+		return defaultValue, nil
+	}
+	res, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: not integer\n", key)
+	}
+	if res <= 0 {
+		return 0, fmt.Errorf("invalid %s: <= 0\n", key)
+	}
+	return res, nil
+}
+
+// getNonNegativeIntEnv is getPositiveIntEnv for settings where zero turns the feature off.
+func getNonNegativeIntEnv(key string, defaultValue int) (int, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue, nil
+	}
+	res, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: not integer\n", key)
+	}
+	if res < 0 {
+		return 0, fmt.Errorf("invalid %s: < 0\n", key)
+	}
+	return res, nil
+}
+
+func getOneOfEnv(key, defaultValue string, allowed ...string) (string, error) {
+	val := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	if val == "" {
+		return defaultValue, nil
+	}
+	if !slices.Contains(allowed, val) {
+		return "", fmt.Errorf("invalid %s: must be one of %s\n", key, strings.Join(allowed, ", "))
+	}
+	return val, nil
+}
+
 func getBoolEnv(key string, defaultValue bool) bool {
 	val := os.Getenv(key)
 	if val == "" {
@@ -314,3 +691,152 @@ func getBoolEnv(key string, defaultValue bool) bool {
 		return defaultValue
 	}
 }
+
+// parseRoleScopes reads mapping in format "role=scope,scope;role=scope".
+func parseRoleScopes(val string) (map[string][]string, error) {
+	res := map[string][]string{}
+	for _, item := range strings.Split(val, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		role, scopes, ok := strings.Cut(item, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid %s: expected role=scope,scope\n", EnvNameJWTRoleScopes)
+		}
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				res[role] = append(res[role], scope)
+			}
+		}
+	}
+	return res, nil
+}
+
+// parseRoles reads mapping of provider roles to service roles in format "provider-role=role;provider-role=role".
+func parseRoles(val string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, item := range strings.Split(val, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		providerRole, role, ok := strings.Cut(item, "=")
+		providerRole = strings.TrimSpace(providerRole)
+		role = strings.TrimSpace(role)
+		if !ok || providerRole == "" || role == "" {
+			return nil, fmt.Errorf("invalid %s: expected provider-role=role\n", EnvNameJWTRoles)
+		}
+		res[providerRole] = role
+	}
+	return res, nil
+}
+
+// loadTenants reads the tenant overrides in format {"tenant": {"max_radius": 1000, ...}}.
+func loadTenants(path string) (map[string]*TenantConfig, error) {
+	if path == "" {
+		return map[string]*TenantConfig{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvNameTenantsFile, err)
+	}
+	return parseTenants(data)
+}
+
+func parseTenants(data []byte) (map[string]*TenantConfig, error) {
+	res := map[string]*TenantConfig{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvNameTenantsFile, err)
+	}
+	for tenant, cfg := range res {
+		if !identity.IsValidTenant(tenant) {
+			return nil, fmt.Errorf("invalid %s: tenant %q must match [a-z0-9][a-z0-9_-]*", EnvNameTenantsFile, tenant)
+		}
+		if cfg == nil {
+			res[tenant] = &TenantConfig{}
+			continue
+		}
+		if cfg.MaxRadius < 0 || cfg.DefaultRadius < 0 || cfg.StatsTimeWindow < 0 {
+			return nil, fmt.Errorf("invalid %s: tenant %s: values cannot be < 0", EnvNameTenantsFile, tenant)
+		}
+		if cfg.StatsTimeWindow > MaxStatsTime {
+			return nil, fmt.Errorf("invalid %s: tenant %s: stats_time_window_minutes > %d", EnvNameTenantsFile, tenant, MaxStatsTime)
+		}
+		if cfg.MaxRadius != 0 && cfg.DefaultRadius > cfg.MaxRadius {
+			return nil, fmt.Errorf("invalid %s: tenant %s: default_radius > max_radius", EnvNameTenantsFile, tenant)
+		}
+		if cfg.WebhookMethod != "" && cfg.WebhookMethod != http.MethodPost && cfg.WebhookMethod != http.MethodGet {
+			return nil, fmt.Errorf("invalid %s: tenant %s: webhook_method must be POST or GET", EnvNameTenantsFile, tenant)
+		}
+	}
+	return res, nil
+}
+
+// parseRateLimits reads limits in format "route=requests/period;route=off", period is a duration like 1s, 1m or 1h.
+func parseRateLimits(val string) (map[string]RateLimit, error) {
+	res := map[string]RateLimit{}
+	for _, item := range strings.Split(val, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		route, limit, ok := strings.Cut(item, "=")
+		route = strings.TrimSpace(route)
+		limit = strings.TrimSpace(limit)
+		if !ok || route == "" || limit == "" {
+			return nil, fmt.Errorf("invalid %s: expected route=requests/period\n", EnvNameRateLimits)
+		}
+		if !slices.Contains(RateLimitRoutes, route) {
+			return nil, fmt.Errorf("invalid %s: unknown route %s, must be one of %v\n", EnvNameRateLimits, route, RateLimitRoutes)
+		}
+		if limit == "off" {
+			continue
+		}
+		requestsStr, periodStr, ok := strings.Cut(limit, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: route %s: expected requests/period\n", EnvNameRateLimits, route)
+		}
+		requests, err := strconv.Atoi(strings.TrimSpace(requestsStr))
+		if err != nil || requests <= 0 {
+			return nil, fmt.Errorf("invalid %s: route %s: requests must be positive integer\n", EnvNameRateLimits, route)
+		}
+		period, err := time.ParseDuration(strings.TrimSpace(periodStr))
+		if err != nil || period < time.Second {
+			return nil, fmt.Errorf("invalid %s: route %s: period must be a duration of at least 1s\n", EnvNameRateLimits, route)
+		}
+		res[route] = RateLimit{Requests: requests, Period: period}
+	}
+	return res, nil
+}
+
+// parseAppSigningKeys reads secrets of mobile apps in format "key-id=secret;key-id@tenant=secret",
+// keys without tenant belong to the default tenant.
+func parseAppSigningKeys(val string) (map[string]AppSigningKey, error) {
+	res := map[string]AppSigningKey{}
+	for _, item := range strings.Split(val, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		keyID, secret, ok := strings.Cut(item, "=")
+		keyID = strings.TrimSpace(keyID)
+		secret = strings.TrimSpace(secret)
+		if !ok || keyID == "" {
+			return nil, fmt.Errorf("invalid %s: expected key-id=secret\n", EnvNameAppSigningKeys)
+		}
+		keyID, tenant, bound := strings.Cut(keyID, "@")
+		if !bound {
+			tenant = identity.DefaultTenant
+		}
+		if keyID == "" || !identity.IsValidTenant(tenant) {
+			return nil, fmt.Errorf("invalid %s: expected key-id@tenant=secret\n", EnvNameAppSigningKeys)
+		}
+		if len(secret) < MinAppSigningSecretLen {
+			return nil, fmt.Errorf("invalid %s: secret of %s is shorter than %d characters\n", EnvNameAppSigningKeys, keyID, MinAppSigningSecretLen)
+		}
+		res[keyID] = AppSigningKey{Secret: secret, Tenant: tenant}
+	}
+	return res, nil
+}
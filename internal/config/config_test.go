@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidtionPort(t *testing.T) {
@@ -632,3 +634,431 @@ func TestNewConfig_AdditionalFields(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPositiveIntEnv(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		value                 string
+		expected              int
+		expectedErrorContains string
+	}{
+		{
+			name:     "empty_uses_default",
+			value:    "",
+			expected: DefaultTrashRetentionDays,
+		},
+		{
+			name:     "valid_value",
+			value:    "7",
+			expected: 7,
+		},
+		{
+			name:                  "not_integer",
+			value:                 "week",
+			expectedErrorContains: "invalid TRASH_RETENTION_DAYS: not integer",
+		},
+		{
+			name:                  "zero",
+			value:                 "0",
+			expectedErrorContains: "invalid TRASH_RETENTION_DAYS: <= 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				os.Setenv(EnvNameTrashRetentionDays, tc.value)
+			} else {
+				os.Unsetenv(EnvNameTrashRetentionDays)
+			}
+			defer os.Unsetenv(EnvNameTrashRetentionDays)
+
+			got, err := getPositiveIntEnv(EnvNameTrashRetentionDays, DefaultTrashRetentionDays)
+			if tc.expectedErrorContains != "" {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.expectedErrorContains) {
+					t.Errorf("error message\ngot:  %s\nwant: %s", err.Error(), tc.expectedErrorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %d, want %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetNonNegativeIntEnv(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		value                 string
+		expected              int
+		expectedErrorContains string
+	}{
+		{name: "empty_uses_default", value: "", expected: DefaultChecksRetentionDays},
+		{name: "zero_turns_off", value: "0", expected: 0},
+		{name: "valid_value", value: "90", expected: 90},
+		{name: "negative", value: "-1", expectedErrorContains: "invalid CHECKS_RETENTION_DAYS: < 0"},
+		{name: "not_integer", value: "year", expectedErrorContains: "invalid CHECKS_RETENTION_DAYS: not integer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv(EnvNameChecksRetentionDays, tc.value)
+			defer os.Unsetenv(EnvNameChecksRetentionDays)
+
+			got, err := getNonNegativeIntEnv(EnvNameChecksRetentionDays, DefaultChecksRetentionDays)
+			if tc.expectedErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErrorContains) {
+					t.Fatalf("error: got %v, want %s", err, tc.expectedErrorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %d, want %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetOneOfEnv(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		value                 string
+		expected              string
+		expectedErrorContains string
+	}{
+		{name: "empty_uses_default", value: "", expected: RetentionModeArchive},
+		{name: "valid_value", value: " Drop ", expected: RetentionModeDrop},
+		{name: "unknown", value: "truncate", expectedErrorContains: "invalid CHECKS_RETENTION_MODE: must be one of drop, archive"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv(EnvNameChecksRetentionMode, tc.value)
+			defer os.Unsetenv(EnvNameChecksRetentionMode)
+
+			got, err := getOneOfEnv(EnvNameChecksRetentionMode, DefaultChecksRetentionMode, RetentionModeDrop, RetentionModeArchive)
+			if tc.expectedErrorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErrorContains) {
+					t.Fatalf("error: got %v, want %s", err, tc.expectedErrorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("got %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseRoleScopes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[string][]string
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[string][]string{},
+		},
+		{
+			name:  "several_roles",
+			value: "incident-admin=incidents:read,incidents:write, keys:admin; viewer = incidents:read;",
+			expected: map[string][]string{
+				"incident-admin": {"incidents:read", "incidents:write", "keys:admin"},
+				"viewer":         {"incidents:read"},
+			},
+		},
+		{
+			name:        "missing_separator",
+			value:       "viewer",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseRoleScopes(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("got %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseRoles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:  "several_roles",
+			value: "incident-admin=admin; duty = operator;",
+			expected: map[string]string{
+				"incident-admin": "admin",
+				"duty":           "operator",
+			},
+		},
+		{
+			name:        "missing_role",
+			value:       "duty=",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseRoles(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("got %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseTenants(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[string]*TenantConfig
+		expectError bool
+	}{
+		{
+			name:  "valid",
+			value: `{"moscow": {"max_radius": 1000, "default_radius": 500, "webhook_method": "GET"}, "kazan": null}`,
+			expected: map[string]*TenantConfig{
+				"moscow": {MaxRadius: 1000, DefaultRadius: 500, WebhookMethod: http.MethodGet},
+				"kazan":  {},
+			},
+		},
+		{
+			name:        "invalid_slug",
+			value:       `{"Moscow City": {}}`,
+			expectError: true,
+		},
+		{
+			name:        "default_more_than_max",
+			value:       `{"moscow": {"max_radius": 100, "default_radius": 500}}`,
+			expectError: true,
+		},
+		{
+			name:        "negative_window",
+			value:       `{"moscow": {"stats_time_window_minutes": -1}}`,
+			expectError: true,
+		},
+		{
+			name:        "invalid_method",
+			value:       `{"moscow": {"webhook_method": "PUT"}}`,
+			expectError: true,
+		},
+		{
+			name:        "not_json",
+			value:       `moscow`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseTenants([]byte(tc.value))
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("got %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_ForTenant(t *testing.T) {
+	cfg := &Config{
+		MaxRadius:       50000,
+		DefaultRadius:   5000,
+		StatsTimeWindow: 100,
+		WebhookURL:      DefaultWebhookURL,
+		WebhookMethod:   http.MethodPost,
+		Tenants: map[string]*TenantConfig{
+			"moscow": {MaxRadius: 1000, StatsTimeWindow: 60, WebhookURL: "http://moscow/hook"},
+		},
+	}
+
+	res := cfg.ForTenant("moscow")
+	if res.MaxRadius != 1000 || res.StatsTimeWindow != 60 || res.WebhookURL != "http://moscow/hook" {
+		t.Errorf("overrides not applied: %+v", res)
+	}
+	if res.DefaultRadius != 1000 {
+		t.Errorf("default radius must be cut to max radius, got %d", res.DefaultRadius)
+	}
+	if res.WebhookMethod != http.MethodPost {
+		t.Errorf("webhook method must stay global, got %s", res.WebhookMethod)
+	}
+	if cfg.MaxRadius != 50000 {
+		t.Errorf("global config changed")
+	}
+
+	res = cfg.ForTenant("kazan")
+	if res.MaxRadius != 50000 || res.DefaultRadius != 5000 || res.StatsTimeWindow != 100 {
+		t.Errorf("unknown tenant must use global config: %+v", res)
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[string]RateLimit
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[string]RateLimit{},
+		},
+		{
+			name:     "default",
+			value:    DefaultRateLimits,
+			expected: map[string]RateLimit{RateLimitRouteLocationCheck: {Requests: 60, Period: time.Minute}},
+		},
+		{
+			name:     "off",
+			value:    "location_check=off",
+			expected: map[string]RateLimit{},
+		},
+		{
+			name:        "unknown_route",
+			value:       "incidents=10/1s",
+			expectError: true,
+		},
+		{
+			name:        "zero_requests",
+			value:       "location_check=0/1m",
+			expectError: true,
+		},
+		{
+			name:        "short_period",
+			value:       "location_check=10/100ms",
+			expectError: true,
+		},
+		{
+			name:        "missing_period",
+			value:       "location_check=10",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseRateLimits(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("got %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseAppSigningKeys(t *testing.T) {
+	secret := strings.Repeat("s", MinAppSigningSecretLen)
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[string]AppSigningKey
+		expectError bool
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[string]AppSigningKey{},
+		},
+		{
+			name:  "several_apps",
+			value: "ios-1=" + secret + "; android-1@moscow = " + secret + ";",
+			expected: map[string]AppSigningKey{
+				"ios-1":     {Secret: secret, Tenant: "default"},
+				"android-1": {Secret: secret, Tenant: "moscow"},
+			},
+		},
+		{
+			name:        "invalid_tenant",
+			value:       "ios-1@Moscow=" + secret,
+			expectError: true,
+		},
+		{
+			name:        "short_secret",
+			value:       "ios-1=secret",
+			expectError: true,
+		},
+		{
+			name:        "missing_key_id",
+			value:       "=" + secret,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseAppSigningKeys(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if !reflect.DeepEqual(res, tc.expected) {
+				t.Errorf("got %v, want %v", res, tc.expected)
+			}
+		})
+	}
+}
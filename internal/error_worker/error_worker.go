@@ -42,6 +42,8 @@ func NewErrorWorker(isLoggingUserError bool) *ErrorWorker {
 }
 
 func (ew *ErrorWorker) initErrors() {
+	//ACCESS
+	ew.AddNewUserError("permission denied", http.StatusForbidden)
 	//DTO VALIDATION
 	ew.AddNewUserError("cannot be", http.StatusBadRequest)
 	ew.AddNewUserError("latitude incorrect", http.StatusBadRequest)
@@ -60,7 +62,19 @@ func (ew *ErrorWorker) initErrors() {
 	ew.AddNewUserError("invalid incident_id", http.StatusNotFound)
 	ew.AddNewUserError("unable to update archived incident", http.StatusConflict)
 	ew.AddNewUserError("incident already archived", http.StatusConflict)
+	ew.AddNewUserError("status transition not allowed", http.StatusConflict)
+	ew.AddNewUserError("restore not allowed", http.StatusConflict)
+	ew.AddNewUserError("api key already", http.StatusConflict)
+	ew.AddNewUserError("hotspot already", http.StatusConflict)
+	ew.AddNewUserError("precondition failed", http.StatusPreconditionFailed)
+	ew.AddNewUserError("patch test failed", http.StatusConflict)
+	ew.AddNewUserError("invalid patch", http.StatusBadRequest)
+	ew.AddNewUserError("cannot be patched", http.StatusBadRequest)
+	ew.AddNewUserError("request body too large", http.StatusRequestEntityTooLarge)
+	ew.AddNewUserError("invalid import document", http.StatusBadRequest)
+	ew.AddNewUserError("precondition required", http.StatusPreconditionRequired)
 	ew.AddNewUserError("invalid page_num", http.StatusBadRequest)
+	ew.AddNewUserError("invalid cursor", http.StatusBadRequest)
 	ew.AddNewUserError("must be", http.StatusBadRequest)
 	ew.AddNewUserError("invalid page", http.StatusBadRequest)
 
@@ -224,6 +224,13 @@ func TestErrorWorker_ProcessingError(t *testing.T) {
 			expectedCode: http.StatusBadRequest,
 			expectedErr:  fmt.Errorf("invalid status"),
 		},
+		// ===== ДОСТУП (403 Forbidden) =====
+		{
+			name:         "permission denied",
+			err:          fmt.Errorf("permission denied: role operator cannot incidents.force_delete"),
+			expectedCode: http.StatusForbidden,
+			expectedErr:  fmt.Errorf("permission denied: role operator cannot incidents.force_delete"),
+		},
 		// ===== КОНФЛИКТЫ (409 Conflict) =====
 		{
 			name:         "incident already archived",
@@ -237,6 +244,49 @@ func TestErrorWorker_ProcessingError(t *testing.T) {
 			expectedCode: http.StatusConflict,
 			expectedErr:  fmt.Errorf("unable to update archived incident"),
 		},
+		{
+			name:         "status transition not allowed",
+			err:          fmt.Errorf("status transition not allowed: resolved -> scheduled, allowed: active, monitoring, archived"),
+			expectedCode: http.StatusConflict,
+			expectedErr:  fmt.Errorf("status transition not allowed: resolved -> scheduled, allowed: active, monitoring, archived"),
+		},
+		{
+			name:         "restore not allowed",
+			err:          fmt.Errorf("restore not allowed: incident is not archived or deleted"),
+			expectedCode: http.StatusConflict,
+			expectedErr:  fmt.Errorf("restore not allowed: incident is not archived or deleted"),
+		},
+		{
+			name:         "hotspot already promoted",
+			err:          fmt.Errorf("hotspot already promoted"),
+			expectedCode: http.StatusConflict,
+			expectedErr:  fmt.Errorf("hotspot already promoted"),
+		},
+		// ===== PRECONDITIONS (412/428) =====
+		{
+			name:         "precondition failed",
+			err:          fmt.Errorf("precondition failed: incident version is 4"),
+			expectedCode: http.StatusPreconditionFailed,
+			expectedErr:  fmt.Errorf("precondition failed: incident version is 4"),
+		},
+		{
+			name:         "precondition required",
+			err:          fmt.Errorf("precondition required: If-Match header with incident ETag"),
+			expectedCode: http.StatusPreconditionRequired,
+			expectedErr:  fmt.Errorf("precondition required: If-Match header with incident ETag"),
+		},
+		{
+			name:         "patch test failed",
+			err:          fmt.Errorf("invalid patch: operation 0 (test): patch test failed: /radius"),
+			expectedCode: http.StatusConflict,
+			expectedErr:  fmt.Errorf("invalid patch: operation 0 (test): patch test failed: /radius"),
+		},
+		{
+			name:         "invalid patch",
+			err:          fmt.Errorf("invalid patch: operation 1 (replace): path /status not found"),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  fmt.Errorf("invalid patch: operation 1 (replace): path /status not found"),
+		},
 		// ===== NOT FOUND (404 Not Found) =====
 		{
 			name:         "invalid incident_id",
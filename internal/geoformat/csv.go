@@ -0,0 +1,107 @@
+package geoformat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+// csvColumns maps accepted header names to request fields.
+var csvColumns = map[string]string{
+	"latitude":    "latitude",
+	"lat":         "latitude",
+	"longitude":   "longitude",
+	"lon":         "longitude",
+	"lng":         "longitude",
+	"name":        "name",
+	"type":        "type",
+	"radius":      "radius",
+	"description": "description",
+	"status":      "status",
+}
+
+func parseCSV(r io.Reader) ([]*Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if detectSemicolon(data) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid import document: csv: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := csvColumns[name]; ok {
+			columns[field] = i
+		}
+	}
+	for _, required := range []string{"latitude", "longitude", "name", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid import document: csv header must contain %s column", required)
+		}
+	}
+
+	records := []*Record{}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		record := &Record{Row: line}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid import document: csv: %w", err)
+			}
+			record.Row = parseErr.Line
+			record.Err = fmt.Errorf("invalid csv row: %w", parseErr.Err)
+			records = append(records, record)
+			continue
+		}
+		record.Request, record.Err = csvRowToRequest(row, columns)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func detectSemicolon(data []byte) bool {
+	header, _, _ := strings.Cut(string(data), "\n")
+	return strings.Count(header, ";") > strings.Count(header, ",")
+}
+
+func csvRowToRequest(row []string, columns map[string]int) (*dto.RegistrationIncidentRequest, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	radius, err := parseRadius(value("radius"))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RegistrationIncidentRequest{
+		Name:           value("name"),
+		Type:           value("type"),
+		Latitude:       value("latitude"),
+		Longitude:      value("longitude"),
+		Description:    optionalString(value("description")),
+		RadiusInMeters: radius,
+		Status:         optionalString(value("status")),
+	}, nil
+}
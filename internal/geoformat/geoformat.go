@@ -0,0 +1,95 @@
+// Package geoformat reads incidents from GeoJSON, CSV and KML documents
+// and turns every feature, row or placemark into a registration request.
+package geoformat
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+const (
+	FormatGeoJSON = "geojson"
+	FormatCSV     = "csv"
+	FormatKML     = "kml"
+)
+
+var Formats = []string{FormatGeoJSON, FormatCSV, FormatKML}
+
+// Record is a single item of an imported document. Row is 1-based: the feature
+// or placemark number, or the CSV line. Err is set when the item cannot be read,
+// in that case Request is nil.
+type Record struct {
+	Row     int
+	Request *dto.RegistrationIncidentRequest
+	Err     error
+}
+
+// Parse reads all records of the document. An error is returned only when the
+// document itself is broken, problems of separate items are kept in Record.Err.
+func Parse(format string, r io.Reader) ([]*Record, error) {
+	switch format {
+	case FormatGeoJSON:
+		return parseGeoJSON(r)
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatKML:
+		return parseKML(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format: must be one of %v", Formats)
+	}
+}
+
+// FormatFromContentType maps the request media type to the import format.
+func FormatFromContentType(mediaType string) string {
+	switch mediaType {
+	case "application/geo+json", "application/json":
+		return FormatGeoJSON
+	case "text/csv":
+		return FormatCSV
+	case "application/vnd.google-earth.kml+xml", "application/xml", "text/xml":
+		return FormatKML
+	}
+	return ""
+}
+
+// FormatFromFileName detects the format by file extension.
+func FormatFromFileName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".geojson", ".json":
+		return FormatGeoJSON
+	case ".csv":
+		return FormatCSV
+	case ".kml":
+		return FormatKML
+	}
+	return ""
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func parseRadius(str string) (*int, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil, nil
+	}
+	radius, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid radius: is not integer")
+	}
+	return &radius, nil
+}
+
+func optionalString(str string) *string {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil
+	}
+	return &str
+}
@@ -0,0 +1,136 @@
+package geoformat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	doc := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [37.6173, 55.7558]},
+		 "properties": {"name": "Пожар", "type": "fire", "radius": 300, "description": "склад", "status": "monitoring"}},
+		{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]}, "properties": {"name": "line"}}
+	]}`
+	records, err := Parse(FormatGeoJSON, strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if len(records) != 2 {
+		t.Fatalf("records: got: %d, expect: 2\n", len(records))
+	}
+	req := records[0].Request
+	if records[0].Err != nil || req == nil {
+		t.Fatalf("first feature must be parsed, err: %v\n", records[0].Err)
+	}
+	if req.Name != "Пожар" || req.Type != "fire" || req.Latitude != "55.7558" || req.Longitude != "37.6173" {
+		t.Errorf("unexpected request: %+v\n", req)
+	}
+	if req.RadiusInMeters == nil || *req.RadiusInMeters != 300 || req.Status == nil || *req.Status != "monitoring" {
+		t.Errorf("unexpected radius or status: %+v\n", req)
+	}
+	if records[1].Err == nil || records[1].Row != 2 {
+		t.Errorf("second feature must fail, got: %+v\n", records[1])
+	}
+
+	_, err = Parse(FormatGeoJSON, strings.NewReader(`{"type": "Feature"}`))
+	if err == nil || !strings.Contains(err.Error(), "FeatureCollection") {
+		t.Errorf("error: got: %v, expect FeatureCollection error\n", err)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	testCases := []struct {
+		name          string
+		doc           string
+		expectedRows  int
+		expectedError string
+	}{
+		{
+			name:         "comma_with_optional_columns",
+			doc:          "lat,lon,name,type,radius,description,extra\n55.75,37.61,Пожар,fire,300,склад,x\n55.76,37.62,Потоп,flood,,,\n",
+			expectedRows: 2,
+		},
+		{
+			name:         "semicolon",
+			doc:          "\ufefflatitude;longitude;name;type\n55,75;37,61;Пожар;fire\n",
+			expectedRows: 1,
+		},
+		{
+			name:          "missing_required_column",
+			doc:           "lat,lon,name\n55.75,37.61,Пожар\n",
+			expectedError: "invalid import document: csv header must contain type column",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := Parse(FormatCSV, strings.NewReader(tc.doc))
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("error: got: %v, expect: %s\n", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			if len(records) != tc.expectedRows {
+				t.Fatalf("records: got: %d, expect: %d\n", len(records), tc.expectedRows)
+			}
+			for _, record := range records {
+				if record.Err != nil {
+					t.Errorf("row %d: unexpected error: %s\n", record.Row, record.Err.Error())
+				}
+			}
+		})
+	}
+
+	records, err := Parse(FormatCSV, strings.NewReader("lat,lon,name,type,radius\n55.75,37.61,Пожар,fire,big\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if records[0].Row != 2 || records[0].Err == nil {
+		t.Errorf("row with bad radius must fail on line 2, got: %+v\n", records[0])
+	}
+}
+
+func TestParseKML(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2"><Document><Folder>
+	<Placemark>
+		<name>Пожар</name>
+		<description>склад</description>
+		<ExtendedData><Data name="type"><value>fire</value></Data><Data name="radius"><value>250</value></Data></ExtendedData>
+		<Point><coordinates>37.6173,55.7558,0</coordinates></Point>
+	</Placemark>
+	<Placemark><name>Без точки</name></Placemark>
+</Folder></Document></kml>`
+	records, err := Parse(FormatKML, strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if len(records) != 2 {
+		t.Fatalf("records: got: %d, expect: 2\n", len(records))
+	}
+	req := records[0].Request
+	if req == nil || req.Name != "Пожар" || req.Type != "fire" || req.Latitude != "55.7558" || req.Longitude != "37.6173" {
+		t.Errorf("unexpected request: %+v\n", req)
+	}
+	if req != nil && (req.RadiusInMeters == nil || *req.RadiusInMeters != 250) {
+		t.Errorf("radius must be read from extended data\n")
+	}
+	if records[1].Err == nil {
+		t.Errorf("placemark without point must fail\n")
+	}
+
+	_, err = Parse(FormatKML, strings.NewReader("<kml><Placemark></kml>"))
+	if err == nil || !strings.Contains(err.Error(), "invalid import document") {
+		t.Errorf("error: got: %v, expect malformed document\n", err)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := Parse("shp", strings.NewReader(""))
+	if err == nil || !strings.Contains(err.Error(), "unsupported import format") {
+		t.Errorf("error: got: %v, expect unsupported import format\n", err)
+	}
+}
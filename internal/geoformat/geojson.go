@@ -0,0 +1,72 @@
+package geoformat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+type featureCollection struct {
+	Type     string            `json:"type"`
+	Features []json.RawMessage `json:"features"`
+}
+
+type feature struct {
+	Type     string `json:"type"`
+	Geometry *struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		Name        string  `json:"name"`
+		Type        string  `json:"type"`
+		Description *string `json:"description"`
+		Radius      *int    `json:"radius"`
+		Status      *string `json:"status"`
+	} `json:"properties"`
+}
+
+func parseGeoJSON(r io.Reader) ([]*Record, error) {
+	collection := featureCollection{}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("invalid import document: geojson: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("invalid import document: geojson type must be FeatureCollection")
+	}
+
+	records := make([]*Record, 0, len(collection.Features))
+	for i, raw := range collection.Features {
+		record := &Record{Row: i + 1}
+		record.Request, record.Err = parseFeature(raw)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseFeature(raw json.RawMessage) (*dto.RegistrationIncidentRequest, error) {
+	f := feature{}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("invalid feature: %w", err)
+	}
+	if f.Type != "Feature" {
+		return nil, fmt.Errorf("invalid feature: type must be Feature")
+	}
+	if f.Geometry == nil || f.Geometry.Type != "Point" {
+		return nil, fmt.Errorf("invalid feature: geometry must be Point")
+	}
+	if len(f.Geometry.Coordinates) < 2 {
+		return nil, fmt.Errorf("invalid feature: point must have longitude and latitude")
+	}
+	return &dto.RegistrationIncidentRequest{
+		Name:           f.Properties.Name,
+		Type:           f.Properties.Type,
+		Latitude:       formatCoordinate(f.Geometry.Coordinates[1]),
+		Longitude:      formatCoordinate(f.Geometry.Coordinates[0]),
+		Description:    f.Properties.Description,
+		RadiusInMeters: f.Properties.Radius,
+		Status:         f.Properties.Status,
+	}, nil
+}
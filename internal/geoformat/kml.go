@@ -0,0 +1,105 @@
+package geoformat
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+type placemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Point       *struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point"`
+	Data []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	} `xml:"ExtendedData>Data"`
+}
+
+// parseKML collects placemarks at any depth, so documents with nested folders are supported.
+func parseKML(r io.Reader) ([]*Record, error) {
+	decoder := xml.NewDecoder(r)
+	records := []*Record{}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, kmlDocumentError(err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "Placemark" {
+			continue
+		}
+		p := placemark{}
+		if err := decoder.DecodeElement(&p, &start); err != nil {
+			return nil, kmlDocumentError(err)
+		}
+		record := &Record{Row: len(records) + 1}
+		record.Request, record.Err = placemarkToRequest(&p)
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("invalid import document: kml has no placemarks")
+	}
+	return records, nil
+}
+
+// kmlDocumentError hides the xml parser wording, only the broken line is reported.
+// Errors of the underlying reader are returned as is.
+func kmlDocumentError(err error) error {
+	var xmlErr *xml.SyntaxError
+	if errors.As(err, &xmlErr) {
+		return fmt.Errorf("invalid import document: kml is malformed at line %d", xmlErr.Line)
+	}
+	var unmarshalErr xml.UnmarshalError
+	if errors.As(err, &unmarshalErr) {
+		return fmt.Errorf("invalid import document: kml is malformed")
+	}
+	return err
+}
+
+func placemarkToRequest(p *placemark) (*dto.RegistrationIncidentRequest, error) {
+	if p.Point == nil {
+		return nil, fmt.Errorf("invalid placemark: geometry must be Point")
+	}
+	// KML coordinates are "longitude,latitude[,altitude]"
+	parts := strings.Split(strings.TrimSpace(p.Point.Coordinates), ",")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid placemark: point must have longitude and latitude")
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid placemark: longitude is not number")
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid placemark: latitude is not number")
+	}
+
+	data := map[string]string{}
+	for _, d := range p.Data {
+		data[strings.ToLower(d.Name)] = strings.TrimSpace(d.Value)
+	}
+	radius, err := parseRadius(data["radius"])
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RegistrationIncidentRequest{
+		Name:           strings.TrimSpace(p.Name),
+		Type:           data["type"],
+		Latitude:       formatCoordinate(latitude),
+		Longitude:      formatCoordinate(longitude),
+		Description:    optionalString(p.Description),
+		RadiusInMeters: radius,
+		Status:         optionalString(data["status"]),
+	}, nil
+}
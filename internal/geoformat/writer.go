@@ -0,0 +1,207 @@
+package geoformat
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// FormatNDJSON is newline delimited GeoJSON: one Feature per line, export only.
+const FormatNDJSON = "ndjson"
+
+var ExportFormats = []string{FormatGeoJSON, FormatCSV, FormatNDJSON}
+
+// Feature is a point with flat properties, Properties are aligned with the writer columns.
+// Only strings, numbers, booleans, times and nil are used so that the output can be loaded
+// into GIS tools (QGIS, GeoPackage) without nested attributes.
+type Feature struct {
+	Latitude   string
+	Longitude  string
+	Properties []any
+}
+
+// FeatureWriter streams features to w without keeping them in memory.
+// Close must be called after the last feature to finish the document.
+type FeatureWriter interface {
+	Write(f *Feature) error
+	Close() error
+}
+
+func NewFeatureWriter(format string, w io.Writer, columns []string) (FeatureWriter, error) {
+	switch format {
+	case FormatGeoJSON:
+		return &geoJSONWriter{w: w, columns: columns}, nil
+	case FormatNDJSON:
+		return &geoJSONWriter{w: w, columns: columns, sequence: true}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w), columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: must be one of %v", ExportFormats)
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// FileExtension returns the extension QGIS recognizes for the format.
+func FileExtension(format string) string {
+	switch format {
+	case FormatGeoJSON:
+		return ".geojson"
+	case FormatNDJSON:
+		return ".geojsonl"
+	case FormatCSV:
+		return ".csv"
+	}
+	return ""
+}
+
+type geoJSONWriter struct {
+	w        io.Writer
+	columns  []string
+	sequence bool
+	count    int
+}
+
+func (gw *geoJSONWriter) Write(f *Feature) error {
+	if _, err := strconv.ParseFloat(f.Latitude, 64); err != nil {
+		return fmt.Errorf("feature latitude %q is not number", f.Latitude)
+	}
+	if _, err := strconv.ParseFloat(f.Longitude, 64); err != nil {
+		return fmt.Errorf("feature longitude %q is not number", f.Longitude)
+	}
+	properties := make(map[string]any, len(gw.columns))
+	for i, column := range gw.columns {
+		if i < len(f.Properties) {
+			properties[column] = f.Properties[i]
+		}
+	}
+	b, err := json.Marshal(map[string]any{
+		"type": "Feature",
+		"geometry": map[string]any{
+			"type":        "Point",
+			"coordinates": []json.Number{json.Number(f.Longitude), json.Number(f.Latitude)},
+		},
+		"properties": properties,
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	switch {
+	case gw.sequence:
+	case gw.count == 0:
+		prefix = `{"type":"FeatureCollection","features":[`
+	default:
+		prefix = ","
+	}
+	gw.count++
+	if _, err := io.WriteString(gw.w, prefix); err != nil {
+		return err
+	}
+	if _, err := gw.w.Write(b); err != nil {
+		return err
+	}
+	if gw.sequence {
+		_, err = io.WriteString(gw.w, "\n")
+	}
+	return err
+}
+
+func (gw *geoJSONWriter) Close() error {
+	if gw.sequence {
+		return nil
+	}
+	suffix := "]}"
+	if gw.count == 0 {
+		suffix = `{"type":"FeatureCollection","features":[]}`
+	}
+	_, err := io.WriteString(gw.w, suffix)
+	return err
+}
+
+// csvFlushRows limits how many rows the csv writer keeps in its buffer.
+const csvFlushRows = 100
+
+type csvWriter struct {
+	w         *csv.Writer
+	columns   []string
+	wroteHead bool
+	count     int
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.wroteHead {
+		return nil
+	}
+	cw.wroteHead = true
+	return cw.w.Write(append([]string{"latitude", "longitude"}, cw.columns...))
+}
+
+func (cw *csvWriter) Write(f *Feature) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, 0, len(cw.columns)+2)
+	row = append(row, f.Latitude, f.Longitude)
+	for i := range cw.columns {
+		var value any
+		if i < len(f.Properties) {
+			value = f.Properties[i]
+		}
+		row = append(row, csvValue(value))
+	}
+	if err := cw.w.Write(row); err != nil {
+		return err
+	}
+	cw.count++
+	if cw.count%csvFlushRows == 0 {
+		cw.w.Flush()
+		return cw.w.Error()
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
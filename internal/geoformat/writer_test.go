@@ -0,0 +1,120 @@
+package geoformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeatureWriter(t *testing.T) {
+	created := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	description := "склад"
+	features := []*Feature{
+		{Latitude: "55.7558", Longitude: "37.6173", Properties: []any{"a", &description, 300, created}},
+		{Latitude: "55.76", Longitude: "37.62", Properties: []any{"b", (*string)(nil), 100, created}},
+	}
+	columns := []string{"id", "description", "radius", "created_date"}
+
+	testCases := []struct {
+		format   string
+		features []*Feature
+		check    func(t *testing.T, out string)
+	}{
+		{
+			format:   FormatGeoJSON,
+			features: features,
+			check: func(t *testing.T, out string) {
+				doc := struct {
+					Type     string `json:"type"`
+					Features []struct {
+						Geometry struct {
+							Coordinates []float64 `json:"coordinates"`
+						} `json:"geometry"`
+						Properties map[string]any `json:"properties"`
+					} `json:"features"`
+				}{}
+				if err := json.Unmarshal([]byte(out), &doc); err != nil {
+					t.Fatalf("output must be valid json: %s\n%s", err.Error(), out)
+				}
+				if doc.Type != "FeatureCollection" || len(doc.Features) != 2 {
+					t.Fatalf("unexpected document: %s", out)
+				}
+				if doc.Features[0].Geometry.Coordinates[0] != 37.6173 || doc.Features[0].Geometry.Coordinates[1] != 55.7558 {
+					t.Errorf("coordinates must be [lon, lat], got: %v", doc.Features[0].Geometry.Coordinates)
+				}
+				if doc.Features[0].Properties["description"] != "склад" || doc.Features[1].Properties["description"] != nil {
+					t.Errorf("unexpected properties: %v", doc.Features)
+				}
+			},
+		},
+		{
+			format:   FormatGeoJSON,
+			features: nil,
+			check: func(t *testing.T, out string) {
+				if out != `{"type":"FeatureCollection","features":[]}` {
+					t.Errorf("empty collection: got: %s", out)
+				}
+			},
+		},
+		{
+			format:   FormatNDJSON,
+			features: features,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+				if len(lines) != 2 {
+					t.Fatalf("lines: got: %d, expect: 2", len(lines))
+				}
+				for _, line := range lines {
+					if !json.Valid([]byte(line)) || !strings.Contains(line, `"type":"Feature"`) {
+						t.Errorf("line must be a feature: %s", line)
+					}
+				}
+			},
+		},
+		{
+			format:   FormatCSV,
+			features: features,
+			check: func(t *testing.T, out string) {
+				expected := "latitude,longitude,id,description,radius,created_date\n" +
+					"55.7558,37.6173,a,склад,300,2026-10-19T09:00:00Z\n" +
+					"55.76,37.62,b,,100,2026-10-19T09:00:00Z\n"
+				if out != expected {
+					t.Errorf("csv:\ngot:\n%s\nexpect:\n%s", out, expected)
+				}
+			},
+		},
+		{
+			format:   FormatCSV,
+			features: nil,
+			check: func(t *testing.T, out string) {
+				if out != "latitude,longitude,id,description,radius,created_date\n" {
+					t.Errorf("empty csv must contain header, got: %s", out)
+				}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			fw, err := NewFeatureWriter(tc.format, buf, columns)
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			for _, f := range tc.features {
+				if err := fw.Write(f); err != nil {
+					t.Fatalf("unexpected error: %s\n", err.Error())
+				}
+			}
+			if err := fw.Close(); err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			tc.check(t, buf.String())
+		})
+	}
+
+	if _, err := NewFeatureWriter("shp", &bytes.Buffer{}, columns); err == nil {
+		t.Errorf("unknown format must be rejected\n")
+	}
+}
@@ -0,0 +1,91 @@
+// Package geohash encodes points to geohash cells, the same way as ST_GeoHash of PostGIS does.
+package geohash
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	alphabet     = "0123456789bcdefghjkmnpqrstuvwxyz"
+	MaxPrecision = 12
+)
+
+// Box is the area of a cell in degrees.
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Encode returns the cell of the given precision containing the point.
+func Encode(lat, lon float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	var sb strings.Builder
+	even := true
+	bit, ch := 0, 0
+	for sb.Len() < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch |= 1 << (4 - bit)
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bit < 4 {
+			bit++
+			continue
+		}
+		sb.WriteByte(alphabet[ch])
+		bit, ch = 0, 0
+	}
+	return sb.String()
+}
+
+// Bounds returns the area of the cell.
+func Bounds(hash string) (Box, error) {
+	box := Box{MinLat: -90, MinLon: -180, MaxLat: 90, MaxLon: 180}
+	if hash == "" || len(hash) > MaxPrecision {
+		return box, fmt.Errorf("invalid geohash %q", hash)
+	}
+	even := true
+	for _, c := range hash {
+		idx := strings.IndexRune(alphabet, c)
+		if idx < 0 {
+			return box, fmt.Errorf("invalid geohash %q", hash)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx&(1<<bit) != 0
+			if even {
+				mid := (box.MinLon + box.MaxLon) / 2
+				if set {
+					box.MinLon = mid
+				} else {
+					box.MaxLon = mid
+				}
+			} else {
+				mid := (box.MinLat + box.MaxLat) / 2
+				if set {
+					box.MinLat = mid
+				} else {
+					box.MaxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return box, nil
+}
@@ -0,0 +1,47 @@
+package geohash
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lat, lon  float64
+		precision int
+		expected  string
+	}{
+		{name: "moscow", lat: 55.7558, lon: 37.6173, precision: 6, expected: "ucfv0n"},
+		{name: "new_york", lat: 40.7128, lon: -74.0060, precision: 5, expected: "dr5re"},
+		{name: "south_west", lat: -33.8688, lon: 151.2093, precision: 7, expected: "r3gx2f7"},
+		{name: "origin", lat: 0, lon: 0, precision: 1, expected: "s"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Encode(tc.lat, tc.lon, tc.precision)
+			if got != tc.expected {
+				t.Errorf("got: %s, expect: %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	box, err := Bounds("ucfv0n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if box.MinLat > 55.7558 || box.MaxLat < 55.7558 || box.MinLon > 37.6173 || box.MaxLon < 37.6173 {
+		t.Errorf("point is outside of its cell: %+v", box)
+	}
+	if math.Abs(box.MaxLon-box.MinLon-360/math.Pow(2, 15)) > 1e-9 || math.Abs(box.MaxLat-box.MinLat-180/math.Pow(2, 15)) > 1e-9 {
+		t.Errorf("unexpected cell size: %+v", box)
+	}
+
+	for _, hash := range []string{"", "uca", "ucfv0nucfv0nu"} {
+		if _, err := Bounds(hash); err == nil {
+			t.Errorf("expected error for %q", hash)
+		}
+	}
+}
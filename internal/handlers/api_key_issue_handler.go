@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type APIKeyIssueHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewAPIKeyIssueHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*APIKeyIssueHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &APIKeyIssueHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ah *APIKeyIssueHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	if !checkHeaderJson(w, r) {
+		return
+	}
+	req := &dto.APIKeyIssueRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+
+	res, err := ah.serv.IssueAPIKey(r.Context(), req)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type APIKeyListHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewAPIKeyListHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*APIKeyListHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &APIKeyListHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ah *APIKeyListHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	res, err := ah.serv.ListAPIKeys(r.Context())
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type APIKeyRevokeHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewAPIKeyRevokeHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*APIKeyRevokeHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &APIKeyRevokeHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ah *APIKeyRevokeHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, ah.ew)
+	if id == "" {
+		return
+	}
+
+	err := ah.serv.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type APIKeyRotateHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewAPIKeyRotateHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*APIKeyRotateHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &APIKeyRotateHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ah *APIKeyRotateHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, ah.ew)
+	if id == "" {
+		return
+	}
+
+	// body is optional, without it the old key is revoked at once
+	req := &dto.APIKeyRotateRequest{}
+	if r.ContentLength != 0 {
+		if !checkHeaderJson(w, r) {
+			return
+		}
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			processingError(w, err, ah.ew)
+			return
+		}
+	}
+
+	res, err := ah.serv.RotateAPIKey(r.Context(), id, req)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
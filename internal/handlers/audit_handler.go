@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type AuditHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewAuditHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*AuditHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &AuditHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ah *AuditHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	params, err := getAuditQueryDTO(r)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+
+	res, err := ah.serv.SearchAudit(r.Context(), params)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, ah.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
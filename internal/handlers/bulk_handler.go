@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type BulkHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewBulkHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*BulkHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &BulkHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (bh *BulkHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	if !checkHeaderJson(w, r) {
+		return
+	}
+	req := &dto.BulkRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		processingError(w, err, bh.ew)
+		return
+	}
+
+	res, err := bh.serv.BulkIncidents(r.Context(), req)
+	if err != nil {
+		processingError(w, err, bh.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, bh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
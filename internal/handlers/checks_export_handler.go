@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type ChecksExportHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewChecksExportHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*ChecksExportHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &ChecksExportHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (eh *ChecksExportHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	from, err := parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		processingError(w, err, eh.ew)
+		return
+	}
+	to, err := parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		processingError(w, err, eh.ew)
+		return
+	}
+	format := getExportFormat(r)
+	stream := newExportWriter(w, format, "checks")
+	err = eh.serv.ExportChecks(r.Context(), format, from, to, stream)
+	finishExport(w, stream, err, eh.ew)
+}
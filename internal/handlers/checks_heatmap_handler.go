@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type ChecksHeatmapHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewChecksHeatmapHandler(serv *service.Service, ew *error_worker.ErrorWorker) (*ChecksHeatmapHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &ChecksHeatmapHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (hh *ChecksHeatmapHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	params, err := getHeatmapQueryDTO(r)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	result, err := hh.serv.GetChecksHeatmap(r.Context(), params)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	w.Header().Set(HeaderContentType, geoformat.ContentType(geoformat.FormatGeoJSON))
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func getHeatmapQueryDTO(r *http.Request) (*dto.HeatmapQueryParams, error) {
+	res := &dto.HeatmapQueryParams{
+		Layer: r.URL.Query().Get(QueryParamLayer),
+	}
+	from, err := parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	res.From = from
+	to, err := parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	res.To = to
+	res.Precision, err = parseIntQueryParam(r, QueryParamPrecision)
+	if err != nil {
+		return nil, err
+	}
+	if str := r.URL.Query().Get(QueryParamBBox); str != "" {
+		res.BBox = []float64{}
+		for _, item := range strings.Split(str, ",") {
+			num, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+			if err != nil {
+				return nil, fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat in degrees")
+			}
+			res.BBox = append(res.BBox, num)
+		}
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/error_worker"
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
@@ -52,3 +55,123 @@ func checkURLParam(w http.ResponseWriter, r *http.Request, ew *error_worker.Erro
 	}
 	return id
 }
+
+func getAuditQueryDTO(r *http.Request) (*dto.AuditQueryParams, error) {
+	res := &dto.AuditQueryParams{
+		IncidentID: r.URL.Query().Get(QueryParamAuditIncidentID),
+		Actor:      r.URL.Query().Get(QueryParamActor),
+		Action:     r.URL.Query().Get(QueryParamAction),
+		RequestID:  r.URL.Query().Get(QueryParamRequestID),
+	}
+	if str := r.URL.Query().Get(QueryParamPageNum); str != "" {
+		num, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page_num: is not integer")
+		}
+		if num < 1 {
+			return nil, fmt.Errorf("page cannot be < 1")
+		}
+		res.PageNum = &num
+	}
+	from, err := parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	res.From = from
+	to, err := parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	res.To = to
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func parseTimeQueryParam(r *http.Request, name string) (*time.Time, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be RFC3339 time", name)
+	}
+	return &t, nil
+}
+
+func parseIntQueryParam(r *http.Request, name string) (*int, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return nil, nil
+	}
+	num, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: is not integer", name)
+	}
+	return &num, nil
+}
+
+func parseFloatQueryParam(r *http.Request, name string) (*float64, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return nil, nil
+	}
+	num, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be number", name)
+	}
+	return &num, nil
+}
+
+// getListQueryParam collects values of a repeated or comma separated query parameter.
+func getListQueryParam(r *http.Request, name string) []string {
+	var res []string
+	for _, value := range r.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				res = append(res, item)
+			}
+		}
+	}
+	return res
+}
+
+func incidentETag(version int) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// getIfMatchVersion returns incident version from If-Match header,
+// nil when header is absent or matches any version. If-Match uses strong comparison (RFC 9110),
+// a weak tag never matches.
+func getIfMatchVersion(r *http.Request) (*int, error) {
+	header := strings.TrimSpace(r.Header.Get(HeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, fmt.Errorf("precondition failed: weak ETag never matches If-Match")
+	}
+	version, err := strconv.Atoi(strings.Trim(header, "\""))
+	if err != nil {
+		return nil, fmt.Errorf("If-Match must be incident ETag")
+	}
+	return &version, nil
+}
+
+// matchIfNoneMatch reports whether one of If-None-Match tags equals the current etag.
+func matchIfNoneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get(HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,12 @@ const (
 	HeaderDeactivateMode  = "Deactivate-Mode"
 	HeaderDeactivateForce = "force"
 	URLParam              = "id"
+	URLParamUserID        = "user_id"
+	HeaderETag            = "ETag"
+	HeaderIfMatch         = "If-Match"
+	HeaderIfNoneMatch     = "If-None-Match"
+
+	HeaderContentDisposition = "Content-Disposition"
 
 	QueryParamIncidentID = "id"
 	QueryParamPageNum    = "page"
@@ -15,4 +21,39 @@ const (
 	QueryParamName       = "name"
 	QueryParamRadius     = "radius"
 	QueryParamStatus     = "status"
+	QueryParamLimit      = "limit"
+	QueryParamCursor     = "cursor"
+	QueryParamSort       = "sort"
+	QueryParamSearch     = "q"
+	QueryParamFormat     = "format"
+	QueryParamMode       = "mode"
+
+	QueryParamNameContains  = "name_contains"
+	QueryParamRadiusMin     = "radius_min"
+	QueryParamRadiusMax     = "radius_max"
+	QueryParamIsActive      = "is_active"
+	QueryParamCreatedFrom   = "created_from"
+	QueryParamCreatedTo     = "created_to"
+	QueryParamUpdatedFrom   = "updated_from"
+	QueryParamUpdatedTo     = "updated_to"
+	QueryParamResolvedFrom  = "resolved_from"
+	QueryParamResolvedTo    = "resolved_to"
+	QueryParamNearLatitude  = "near_lat"
+	QueryParamNearLongitude = "near_lon"
+	QueryParamNearMeters    = "near_meters"
+
+	QueryParamAuditIncidentID = "incident_id"
+	QueryParamActor           = "actor"
+	QueryParamAction          = "action"
+	QueryParamRequestID       = "request_id"
+	QueryParamFrom            = "from"
+	QueryParamTo              = "to"
+	QueryParamBucket          = "bucket"
+	QueryParamWindowMinutes   = "window_minutes"
+	QueryParamIncludeInactive = "include_inactive"
+	QueryParamSeverity        = "severity"
+	QueryParamPrecision       = "precision"
+	QueryParamBBox            = "bbox"
+	QueryParamLayer           = "layer"
+	QueryParamDangerousOnly   = "dangerous_only"
 )
@@ -33,9 +33,14 @@ func (d *DeactivateHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	if id == "" {
 		return
 	}
+	expectedVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		processingError(w, err, d.ew)
+		return
+	}
 	mod := r.Header.Get(HeaderDeactivateMode)
 	if mod == HeaderDeactivateForce {
-		err := d.serv.DeleteIncidentByID(r.Context(), id)
+		err := d.serv.DeleteIncidentByID(r.Context(), id, expectedVersion)
 		if err != nil {
 			processingError(w, err, d.ew)
 			return
@@ -43,7 +48,7 @@ func (d *DeactivateHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	res, err := d.serv.DeactivateIncidentByID(r.Context(), id)
+	res, err := d.serv.DeactivateIncidentByID(r.Context(), id, expectedVersion)
 	if err != nil {
 		processingError(w, err, d.ew)
 		return
@@ -54,6 +59,7 @@ func (d *DeactivateHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		processingError(w, err, d.ew)
 		return
 	}
+	w.Header().Set(HeaderETag, incidentETag(res.Version))
 	w.Header().Set(HeaderContentType, HeaderJson)
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+)
+
+// finishExport reports errors found before the first byte as a regular error response,
+// once streaming started the status is already sent and the error is only logged.
+func finishExport(w http.ResponseWriter, stream *exportWriter, err error, ew *error_worker.ErrorWorker) {
+	if err == nil {
+		if !stream.started {
+			stream.start()
+		}
+		return
+	}
+	if !stream.started {
+		processingError(w, err, ew)
+		return
+	}
+	ew.ProcessError(fmt.Errorf("export interrupted: %w", err))
+}
+
+func getExportFormat(r *http.Request) string {
+	format := r.URL.Query().Get(QueryParamFormat)
+	if format == "" {
+		return geoformat.FormatGeoJSON
+	}
+	return format
+}
+
+// exportWriter sets export headers right before the first byte of the document.
+type exportWriter struct {
+	w        http.ResponseWriter
+	format   string
+	fileName string
+	started  bool
+}
+
+func newExportWriter(w http.ResponseWriter, format, name string) *exportWriter {
+	fileName := fmt.Sprintf("%s_%s%s", name, time.Now().UTC().Format("20060102T150405Z"), geoformat.FileExtension(format))
+	return &exportWriter{w: w, format: format, fileName: fileName}
+}
+
+func (ew *exportWriter) start() {
+	ew.started = true
+	ew.w.Header().Set(HeaderContentType, geoformat.ContentType(ew.format))
+	ew.w.Header().Set(HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", ew.fileName))
+	ew.w.WriteHeader(http.StatusOK)
+}
+
+func (ew *exportWriter) Write(p []byte) (int, error) {
+	if !ew.started {
+		ew.start()
+	}
+	return ew.w.Write(p)
+}
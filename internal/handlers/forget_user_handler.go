@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type ForgetUserHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewForgetUserHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*ForgetUserHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &ForgetUserHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (fh *ForgetUserHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserIDURLParam(r)
+	if err != nil {
+		processingError(w, err, fh.ew)
+		return
+	}
+
+	res, err := fh.serv.ForgetUser(r.Context(), userID, r.URL.Query().Get(QueryParamMode))
+	if err != nil {
+		processingError(w, err, fh.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, fh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -39,6 +39,12 @@ func (g *GetHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		processingError(w, err, g.ew)
 		return
 	}
+	etag := incidentETag(res.Version)
+	w.Header().Set(HeaderETag, etag)
+	if matchIfNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	b, err := json.Marshal(res)
 	if err != nil {
 		processingError(w, err, g.ew)
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type HotspotDismissHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewHotspotDismissHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*HotspotDismissHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &HotspotDismissHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (hh *HotspotDismissHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, hh.ew)
+	if id == "" {
+		return
+	}
+
+	res, err := hh.serv.DismissHotspot(r.Context(), id)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
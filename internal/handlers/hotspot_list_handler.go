@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type HotspotListHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewHotspotListHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*HotspotListHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &HotspotListHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (hh *HotspotListHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	res, err := hh.serv.GetHotspots(r.Context(), r.URL.Query().Get(QueryParamStatus))
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type HotspotPromoteHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewHotspotPromoteHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*HotspotPromoteHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &HotspotPromoteHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (hh *HotspotPromoteHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, hh.ew)
+	if id == "" {
+		return
+	}
+
+	// body is optional, empty body creates incident with defaults taken from hotspot
+	req := &dto.PromoteHotspotRequest{}
+	if r.ContentLength != 0 {
+		if !checkHeaderJson(w, r) {
+			return
+		}
+		err := json.NewDecoder(r.Body).Decode(req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			processingError(w, err, hh.ew)
+			return
+		}
+	}
+
+	res, err := hh.serv.PromoteHotspot(r.Context(), id, req)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, hh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(b)
+}
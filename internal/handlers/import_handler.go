@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+const maxImportBodySize = 10 << 20
+
+type ImportHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewImportHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*ImportHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &ImportHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (ih *ImportHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get(QueryParamFormat)
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+		format = geoformat.FormatFromContentType(mediaType)
+	}
+	if format == "" {
+		ErrorResponse(w, fmt.Errorf("unknown import format: set %s query parameter to one of %v", QueryParamFormat, geoformat.Formats), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	res, err := ih.serv.ImportIncidents(r.Context(), format, r.URL.Query().Get(QueryParamMode), body)
+	if err != nil {
+		processingError(w, err, ih.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, ih.ew)
+		return
+	}
+	code := http.StatusOK
+	if res.Mode == service.ImportModeAtomic && res.Failed > 0 {
+		code = http.StatusUnprocessableEntity
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(code)
+	w.Write(b)
+}
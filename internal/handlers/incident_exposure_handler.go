@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type IncidentExposureHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewIncidentExposureHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*IncidentExposureHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &IncidentExposureHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+// Handler returns the report as JSON, with format query parameter it is streamed as a file for export.
+func (eh *IncidentExposureHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, eh.ew)
+	if id == "" {
+		return
+	}
+
+	format := r.URL.Query().Get(QueryParamFormat)
+	if format != "" {
+		stream := newExportWriter(w, format, "exposure")
+		err := eh.serv.ExportIncidentExposure(r.Context(), id, format, stream)
+		finishExport(w, stream, err, eh.ew)
+		return
+	}
+
+	res, err := eh.serv.GetIncidentExposure(r.Context(), id)
+	if err != nil {
+		processingError(w, err, eh.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, eh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type IncidentsExportHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewIncidentsExportHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*IncidentsExportHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &IncidentsExportHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+// Handler exports incidents with the same filters as the list endpoint.
+func (eh *IncidentsExportHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	params, err := getPaginationQueryDTO(r)
+	if err != nil {
+		processingError(w, err, eh.ew)
+		return
+	}
+	format := getExportFormat(r)
+	stream := newExportWriter(w, format, "incidents")
+	err = eh.serv.ExportIncidents(r.Context(), format, params, stream)
+	finishExport(w, stream, err, eh.ew)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/error_worker"
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
@@ -32,7 +33,7 @@ func NewPaginationHandler(serv *service.Service,
 }
 
 func (gh *PaginationHandler) Handler(w http.ResponseWriter, r *http.Request) {
-	params, err := gh.getValidQueryDTO(r)
+	params, err := getPaginationQueryDTO(r)
 	if err != nil {
 		processingError(w, err, gh.ew)
 		return
@@ -53,8 +54,10 @@ func (gh *PaginationHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
-func (gh *PaginationHandler) getValidQueryDTO(r *http.Request) (*dto.PaginationQueryParams, error) {
+// getPaginationQueryDTO parses list filters, it is shared by the list and search endpoints.
+func getPaginationQueryDTO(r *http.Request) (*dto.PaginationQueryParams, error) {
 	res := &dto.PaginationQueryParams{}
+	var err error
 
 	if str := r.URL.Query().Get(QueryParamIncidentID); str != "" {
 		res.ID = &str
@@ -69,28 +72,63 @@ func (gh *PaginationHandler) getValidQueryDTO(r *http.Request) (*dto.PaginationQ
 		}
 		res.PageNum = &num
 	}
-	if str := r.URL.Query().Get(QueryParamRadius); str != "" {
-		num, err := strconv.Atoi(str)
+	if res.Radius, err = parseIntQueryParam(r, QueryParamRadius); err != nil {
+		return nil, err
+	}
+	if res.Radius != nil && *res.Radius <= 0 {
+		return nil, fmt.Errorf("radius cannot be <= 0")
+	}
+	if res.RadiusMin, err = parseIntQueryParam(r, QueryParamRadiusMin); err != nil {
+		return nil, err
+	}
+	if res.RadiusMax, err = parseIntQueryParam(r, QueryParamRadiusMax); err != nil {
+		return nil, err
+	}
+	res.Types = getListQueryParam(r, QueryParamType)
+	res.Statuses = getListQueryParam(r, QueryParamStatus)
+	res.Name = r.URL.Query().Get(QueryParamName)
+	res.NameContains = r.URL.Query().Get(QueryParamNameContains)
+
+	if str := r.URL.Query().Get(QueryParamIsActive); str != "" {
+		isActive, err := strconv.ParseBool(str)
 		if err != nil {
-			return nil, fmt.Errorf("invalid radius: is not integer")
+			return nil, fmt.Errorf("%s must be boolean", QueryParamIsActive)
 		}
-		num++
-		if num <= 0 {
-			return nil, fmt.Errorf("radius cannot be <= 0")
+		res.IsActive = &isActive
+	}
+	timeParams := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{QueryParamCreatedFrom, &res.CreatedFrom},
+		{QueryParamCreatedTo, &res.CreatedTo},
+		{QueryParamUpdatedFrom, &res.UpdatedFrom},
+		{QueryParamUpdatedTo, &res.UpdatedTo},
+		{QueryParamResolvedFrom, &res.ResolvedFrom},
+		{QueryParamResolvedTo, &res.ResolvedTo},
+	}
+	for _, param := range timeParams {
+		if *param.dst, err = parseTimeQueryParam(r, param.name); err != nil {
+			return nil, err
 		}
-		res.Radius = &num
 	}
-	if str := r.URL.Query().Get(QueryParamType); str != "" {
-		res.Type = str
+	if res.NearLatitude, err = parseFloatQueryParam(r, QueryParamNearLatitude); err != nil {
+		return nil, err
+	}
+	if res.NearLongitude, err = parseFloatQueryParam(r, QueryParamNearLongitude); err != nil {
+		return nil, err
 	}
-	if str := r.URL.Query().Get(QueryParamName); str != "" {
-		res.Name = str
+	if res.NearMeters, err = parseIntQueryParam(r, QueryParamNearMeters); err != nil {
+		return nil, err
 	}
-	if str := r.URL.Query().Get(QueryParamStatus); str != "" {
-		res.Status = str
+
+	if res.Limit, err = parseIntQueryParam(r, QueryParamLimit); err != nil {
+		return nil, err
 	}
+	res.Cursor = r.URL.Query().Get(QueryParamCursor)
+	res.Sort = r.URL.Query().Get(QueryParamSort)
 
-	err := res.Validate()
+	err = res.Validate()
 	if err != nil {
 		return nil, err
 	}
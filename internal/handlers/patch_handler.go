@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+const maxPatchBodySize = 1 << 20
+
+type PatchHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewPatchHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*PatchHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &PatchHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (p *PatchHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil || (mediaType != service.PatchTypeMerge && mediaType != service.PatchTypeJSON) {
+		ErrorResponse(w, fmt.Errorf("invalid header content-type: expected %s or %s", service.PatchTypeMerge, service.PatchTypeJSON), http.StatusUnsupportedMediaType)
+		return
+	}
+	id := checkURLParam(w, r, p.ew)
+	if id == "" {
+		return
+	}
+	expectedVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		processingError(w, err, p.ew)
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBodySize))
+	if err != nil {
+		processingError(w, err, p.ew)
+		return
+	}
+	if len(patch) == 0 {
+		processingError(w, io.EOF, p.ew)
+		return
+	}
+
+	res, err := p.serv.PatchIncidentByID(r.Context(), id, mediaType, patch, expectedVersion)
+	if err != nil {
+		processingError(w, err, p.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, p.ew)
+		return
+	}
+	w.Header().Set(HeaderETag, incidentETag(res.Version))
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type RestoreHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewRestoreHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*RestoreHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &RestoreHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (rh *RestoreHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	id := checkURLParam(w, r, rh.ew)
+	if id == "" {
+		return
+	}
+
+	expectedVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		processingError(w, err, rh.ew)
+		return
+	}
+
+	// body is optional, empty body restores incident with default status
+	req := &dto.RestoreRequest{}
+	if r.ContentLength != 0 {
+		if !checkHeaderJson(w, r) {
+			return
+		}
+		err = json.NewDecoder(r.Body).Decode(req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			processingError(w, err, rh.ew)
+			return
+		}
+	}
+
+	res, err := rh.serv.RestoreIncidentByID(r.Context(), id, req, expectedVersion)
+	if err != nil {
+		processingError(w, err, rh.ew)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, rh.ew)
+		return
+	}
+	w.Header().Set(HeaderETag, incidentETag(res.Version))
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
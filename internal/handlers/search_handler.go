@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type SearchHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewSearchHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*SearchHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &SearchHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (sh *SearchHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	filters, err := getPaginationQueryDTO(r)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+	params := &dto.SearchQueryParams{
+		Query:                 r.URL.Query().Get(QueryParamSearch),
+		PaginationQueryParams: *filters,
+	}
+	if err := params.Validate(); err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+
+	res, err := sh.serv.SearchIncidents(r.Context(), params)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
 	"github.com/Piccadilly98/incidents_service/internal/service"
 )
 
@@ -29,7 +31,13 @@ func NewStatisticHandler(serv *service.Service, ew *error_worker.ErrorWorker) (*
 }
 
 func (sh *StatisticHandler) Handler(w http.ResponseWriter, r *http.Request) {
-	result, err := sh.serv.GetChecksStatistics(r.Context())
+	params, err := getStatsQueryDTO(r)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+
+	result, err := sh.serv.GetChecksStatistics(r.Context(), params)
 	if err != nil {
 		processingError(w, err, sh.ew)
 		return
@@ -45,3 +53,38 @@ func (sh *StatisticHandler) Handler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
 }
+
+func getStatsQueryDTO(r *http.Request) (*dto.StatsQueryParams, error) {
+	// incidents have no severity, the filter is refused instead of being silently ignored
+	if r.URL.Query().Has(QueryParamSeverity) {
+		return nil, fmt.Errorf("%s cannot be used: incidents have no severity", QueryParamSeverity)
+	}
+	res := &dto.StatsQueryParams{
+		Types: getListQueryParam(r, QueryParamType),
+	}
+	from, err := parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	res.From = from
+	to, err := parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	res.To = to
+	res.WindowMinutes, err = parseIntQueryParam(r, QueryParamWindowMinutes)
+	if err != nil {
+		return nil, err
+	}
+	if str := r.URL.Query().Get(QueryParamIncludeInactive); str != "" {
+		res.IncludeInactive, err = strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be boolean", QueryParamIncludeInactive)
+		}
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
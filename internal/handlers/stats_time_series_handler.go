@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+)
+
+type StatsTimeSeriesHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewStatsTimeSeriesHandler(serv *service.Service, ew *error_worker.ErrorWorker) (*StatsTimeSeriesHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &StatsTimeSeriesHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (sh *StatsTimeSeriesHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	params, err := getChecksTimeSeriesQueryDTO(r)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+
+	result, err := sh.serv.GetChecksTimeSeries(r.Context(), params)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		processingError(w, err, sh.ew)
+		return
+	}
+
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func getChecksTimeSeriesQueryDTO(r *http.Request) (*dto.ChecksTimeSeriesQueryParams, error) {
+	res := &dto.ChecksTimeSeriesQueryParams{
+		Bucket:     r.URL.Query().Get(QueryParamBucket),
+		IncidentID: r.URL.Query().Get(QueryParamAuditIncidentID),
+		Type:       r.URL.Query().Get(QueryParamType),
+	}
+	from, err := parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	res.From = from
+	to, err := parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	res.To = to
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
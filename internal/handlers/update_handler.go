@@ -39,15 +39,21 @@ func (u *UpdateHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		processingError(w, err, u.ew)
+		return
+	}
+
 	req := &dto.UpdateRequest{}
 
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		processingError(w, err, u.ew)
 		return
 	}
 
-	res, err := u.serv.UpdateIncidentByID(r.Context(), id, req)
+	res, err := u.serv.UpdateIncidentByID(r.Context(), id, req, expectedVersion)
 	if err != nil {
 		processingError(w, err, u.ew)
 		return
@@ -58,6 +64,7 @@ func (u *UpdateHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		processingError(w, err, u.ew)
 		return
 	}
+	w.Header().Set(HeaderETag, incidentETag(res.Version))
 	w.Header().Set(HeaderContentType, HeaderJson)
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
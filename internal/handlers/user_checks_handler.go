@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Piccadilly98/incidents_service/internal/error_worker"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type UserChecksHandler struct {
+	serv *service.Service
+	ew   *error_worker.ErrorWorker
+}
+
+func NewUserChecksHandler(serv *service.Service,
+	ew *error_worker.ErrorWorker) (*UserChecksHandler, error) {
+	if serv == nil {
+		return nil, fmt.Errorf("service cannot be nil")
+	}
+	if ew == nil {
+		return nil, fmt.Errorf("error worker cannot be nil")
+	}
+
+	return &UserChecksHandler{
+		serv: serv,
+		ew:   ew,
+	}, nil
+}
+
+func (uh *UserChecksHandler) Handler(w http.ResponseWriter, r *http.Request) {
+	params, err := getUserChecksQueryDTO(r)
+	if err != nil {
+		processingError(w, err, uh.ew)
+		return
+	}
+
+	res, err := uh.serv.GetUserChecks(r.Context(), params)
+	if err != nil {
+		processingError(w, err, uh.ew)
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		processingError(w, err, uh.ew)
+		return
+	}
+	w.Header().Set(HeaderContentType, HeaderJson)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+func getUserChecksQueryDTO(r *http.Request) (*dto.UserChecksQueryParams, error) {
+	userID, err := getUserIDURLParam(r)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.UserChecksQueryParams{UserID: userID}
+	res.PageNum, err = parseIntQueryParam(r, QueryParamPageNum)
+	if err != nil {
+		return nil, err
+	}
+	if res.PageNum != nil && *res.PageNum < 1 {
+		return nil, fmt.Errorf("page cannot be < 1")
+	}
+	res.From, err = parseTimeQueryParam(r, QueryParamFrom)
+	if err != nil {
+		return nil, err
+	}
+	res.To, err = parseTimeQueryParam(r, QueryParamTo)
+	if err != nil {
+		return nil, err
+	}
+	if str := r.URL.Query().Get(QueryParamDangerousOnly); str != "" {
+		res.DangerousOnly, err = strconv.ParseBool(str)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be boolean", QueryParamDangerousOnly)
+		}
+	}
+
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// getUserIDURLParam decodes user_id of the path, ids of mobile apps may contain characters
+// which are escaped in URL and chi keeps them escaped when the path has them.
+func getUserIDURLParam(r *http.Request) (string, error) {
+	userID := chi.URLParam(r, URLParamUserID)
+	if r.URL.RawPath == "" {
+		return userID, nil
+	}
+	res, err := url.PathUnescape(userID)
+	if err != nil {
+		return "", fmt.Errorf("user_id must be url encoded")
+	}
+	return res, nil
+}
@@ -0,0 +1,76 @@
+package identity
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	ActorSystem = "system"
+	ActorAPIKey = "api-key"
+)
+
+// Scopes grant access to groups of admin endpoints.
+const (
+	ScopeIncidentsRead  = "incidents:read"
+	ScopeIncidentsWrite = "incidents:write"
+	ScopeStatsRead      = "stats:read"
+	ScopeChecksRead     = "checks:read"
+	ScopeChecksWrite    = "checks:write"
+	ScopeKeysAdmin      = "keys:admin"
+)
+
+var Scopes = []string{
+	ScopeIncidentsRead,
+	ScopeIncidentsWrite,
+	ScopeStatsRead,
+	ScopeChecksRead,
+	ScopeChecksWrite,
+	ScopeKeysAdmin,
+}
+
+type ctxKey struct{}
+
+type requestIDKey struct{}
+
+type Identity struct {
+	Subject string
+	Role    string
+	Tenant  string
+	Scopes  []string
+}
+
+func (id *Identity) HasScope(scope string) bool {
+	return id != nil && slices.Contains(id.Scopes, scope)
+}
+
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+func FromContext(ctx context.Context) *Identity {
+	id, ok := ctx.Value(ctxKey{}).(*Identity)
+	if !ok {
+		return nil
+	}
+	return id
+}
+
+// Actor returns the subject of the caller for logs and history records,
+// calls without identity (tests, background jobs) are attributed to the system.
+func Actor(ctx context.Context) string {
+	id := FromContext(ctx)
+	if id == nil || id.Subject == "" {
+		return ActorSystem
+	}
+	return id.Subject
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,74 @@
+package identity
+
+import "slices"
+
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Roles are ordered from the least to the most privileged.
+var Roles = []string{RoleViewer, RoleOperator, RoleAdmin}
+
+// Permissions are actions over admin routes, checked by the router and again by the service
+// for actions that change incidents.
+const (
+	PermIncidentsRead         = "incidents.read"
+	PermIncidentsCreate       = "incidents.create"
+	PermIncidentsUpdate       = "incidents.update"
+	PermIncidentsArchive      = "incidents.archive"
+	PermIncidentsEditArchived = "incidents.edit_archived"
+	PermIncidentsRestore      = "incidents.restore"
+	PermIncidentsForceDelete  = "incidents.force_delete"
+	PermAuditRead             = "audit.read"
+	PermStatsRead             = "stats.read"
+	PermChecksExport          = "checks.export"
+	PermChecksForget          = "checks.forget"
+	PermKeysManage            = "keys.manage"
+)
+
+type permission struct {
+	// scope the credential must have in addition to the role
+	scope string
+	roles []string
+}
+
+var permissions = map[string]permission{
+	PermIncidentsRead:         {scope: ScopeIncidentsRead, roles: []string{RoleViewer, RoleOperator, RoleAdmin}},
+	PermIncidentsCreate:       {scope: ScopeIncidentsWrite, roles: []string{RoleOperator, RoleAdmin}},
+	PermIncidentsUpdate:       {scope: ScopeIncidentsWrite, roles: []string{RoleOperator, RoleAdmin}},
+	PermIncidentsArchive:      {scope: ScopeIncidentsWrite, roles: []string{RoleOperator, RoleAdmin}},
+	PermIncidentsEditArchived: {scope: ScopeIncidentsWrite, roles: []string{RoleAdmin}},
+	PermIncidentsRestore:      {scope: ScopeIncidentsWrite, roles: []string{RoleAdmin}},
+	PermIncidentsForceDelete:  {scope: ScopeIncidentsWrite, roles: []string{RoleAdmin}},
+	PermAuditRead:             {scope: ScopeIncidentsRead, roles: []string{RoleOperator, RoleAdmin}},
+	PermStatsRead:             {scope: ScopeStatsRead, roles: []string{RoleViewer, RoleOperator, RoleAdmin}},
+	PermChecksExport:          {scope: ScopeChecksRead, roles: []string{RoleAdmin}},
+	PermChecksForget:          {scope: ScopeChecksWrite, roles: []string{RoleAdmin}},
+	PermKeysManage:            {scope: ScopeKeysAdmin, roles: []string{RoleAdmin}},
+}
+
+func IsKnownRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// HighestRole returns the most privileged of known roles, empty string when there is none.
+func HighestRole(roles []string) string {
+	res := ""
+	for _, role := range roles {
+		if i := slices.Index(Roles, role); i > slices.Index(Roles, res) {
+			res = role
+		}
+	}
+	return res
+}
+
+// Can reports whether both the role and the credential scopes allow the permission.
+func (id *Identity) Can(perm string) bool {
+	p, ok := permissions[perm]
+	if !ok || id == nil {
+		return false
+	}
+	return slices.Contains(p.roles, id.Role) && id.HasScope(p.scope)
+}
@@ -0,0 +1,52 @@
+package identity
+
+import "testing"
+
+func TestIdentity_Can(t *testing.T) {
+	scopes := []string{ScopeIncidentsRead, ScopeIncidentsWrite, ScopeStatsRead}
+	testCases := []struct {
+		name     string
+		identity *Identity
+		perm     string
+		expected bool
+	}{
+		{name: "viewer_reads", identity: &Identity{Role: RoleViewer, Scopes: scopes}, perm: PermIncidentsRead, expected: true},
+		{name: "viewer_cannot_create", identity: &Identity{Role: RoleViewer, Scopes: scopes}, perm: PermIncidentsCreate},
+		{name: "viewer_cannot_read_audit", identity: &Identity{Role: RoleViewer, Scopes: scopes}, perm: PermAuditRead},
+		{name: "operator_creates", identity: &Identity{Role: RoleOperator, Scopes: scopes}, perm: PermIncidentsCreate, expected: true},
+		{name: "operator_archives", identity: &Identity{Role: RoleOperator, Scopes: scopes}, perm: PermIncidentsArchive, expected: true},
+		{name: "operator_cannot_force_delete", identity: &Identity{Role: RoleOperator, Scopes: scopes}, perm: PermIncidentsForceDelete},
+		{name: "operator_cannot_edit_archived", identity: &Identity{Role: RoleOperator, Scopes: scopes}, perm: PermIncidentsEditArchived},
+		{name: "admin_force_deletes", identity: &Identity{Role: RoleAdmin, Scopes: scopes}, perm: PermIncidentsForceDelete, expected: true},
+		{name: "admin_without_scope", identity: &Identity{Role: RoleAdmin, Scopes: scopes}, perm: PermKeysManage},
+		{name: "admin_forgets_with_write_scope", identity: &Identity{Role: RoleAdmin, Scopes: []string{ScopeChecksWrite}}, perm: PermChecksForget, expected: true},
+		{name: "admin_cannot_forget_with_read_scope", identity: &Identity{Role: RoleAdmin, Scopes: []string{ScopeChecksRead}}, perm: PermChecksForget},
+		{name: "unknown_permission", identity: &Identity{Role: RoleAdmin, Scopes: Scopes}, perm: "incidents.purge"},
+		{name: "no_role", identity: &Identity{Scopes: scopes}, perm: PermIncidentsRead},
+		{name: "nil_identity", perm: PermIncidentsRead},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.identity.Can(tc.perm); got != tc.expected {
+				t.Errorf("Can(%s): got %v, expect %v", tc.perm, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestHighestRole(t *testing.T) {
+	testCases := []struct {
+		roles    []string
+		expected string
+	}{
+		{roles: nil, expected: ""},
+		{roles: []string{"unknown"}, expected: ""},
+		{roles: []string{RoleViewer, RoleAdmin, RoleOperator}, expected: RoleAdmin},
+		{roles: []string{RoleOperator, RoleViewer}, expected: RoleOperator},
+	}
+	for _, tc := range testCases {
+		if got := HighestRole(tc.roles); got != tc.expected {
+			t.Errorf("HighestRole(%v): got %q, expect %q", tc.roles, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultTenant owns the data created before tenants were introduced and every call
+// that has no tenant of its own: background jobs, tests, public requests without a tenant header.
+const DefaultTenant = "default"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+type tenantKey struct{}
+
+func IsValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// WithTenant sets the tenant for requests without credentials, e.g. public location checks.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant of the caller: the tenant of its credential, the one set by WithTenant
+// or DefaultTenant.
+func Tenant(ctx context.Context) string {
+	if id := FromContext(ctx); id != nil && id.Tenant != "" {
+		return id.Tenant
+	}
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return DefaultTenant
+}
@@ -0,0 +1,169 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7386) and JSON Patch (RFC 6902)
+// documents to flat JSON objects, which is enough for incident resources.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+var ErrTestFailed = errors.New("patch test failed")
+
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyMergePatch returns a copy of doc with merge patch applied, null members remove fields.
+func ApplyMergePatch(doc map[string]any, patch []byte) (map[string]any, error) {
+	var members map[string]any
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil, fmt.Errorf("merge patch must be JSON object: %w", err)
+	}
+	res := copyDocument(doc)
+	for key, value := range members {
+		if value == nil {
+			delete(res, key)
+			continue
+		}
+		res[key] = mergeValue(res[key], value)
+	}
+	return res, nil
+}
+
+func mergeValue(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	res := copyDocument(targetObject)
+	for key, value := range patchObject {
+		if value == nil {
+			delete(res, key)
+			continue
+		}
+		res[key] = mergeValue(res[key], value)
+	}
+	return res
+}
+
+// Apply returns a copy of doc with all operations applied in order,
+// the first failed operation aborts the whole patch.
+func Apply(doc map[string]any, patch []byte) (map[string]any, error) {
+	var operations []Operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("json patch must be array of operations: %w", err)
+	}
+	res := copyDocument(doc)
+	for i, op := range operations {
+		if err := applyOperation(res, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return res, nil
+}
+
+func applyOperation(doc map[string]any, op Operation) error {
+	key, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case OpAdd:
+		value, err := op.value()
+		if err != nil {
+			return err
+		}
+		doc[key] = value
+	case OpRemove:
+		if _, ok := doc[key]; !ok {
+			return fmt.Errorf("path %s not found", op.Path)
+		}
+		delete(doc, key)
+	case OpReplace:
+		if _, ok := doc[key]; !ok {
+			return fmt.Errorf("path %s not found", op.Path)
+		}
+		value, err := op.value()
+		if err != nil {
+			return err
+		}
+		doc[key] = value
+	case OpMove, OpCopy:
+		fromKey, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		value, ok := doc[fromKey]
+		if !ok {
+			return fmt.Errorf("from %s not found", op.From)
+		}
+		if op.Op == OpMove {
+			delete(doc, fromKey)
+		}
+		doc[key] = value
+	case OpTest:
+		value, err := op.value()
+		if err != nil {
+			return err
+		}
+		current, ok := doc[key]
+		if !ok || !reflect.DeepEqual(current, value) {
+			return fmt.Errorf("%w: %s", ErrTestFailed, op.Path)
+		}
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return nil
+}
+
+func (o Operation) value() (any, error) {
+	if len(o.Value) == 0 {
+		return nil, fmt.Errorf("value is required")
+	}
+	var res any
+	if err := json.Unmarshal(o.Value, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// parsePointer converts JSON Pointer to a top-level member name.
+func parsePointer(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("path %q must start with /", pointer)
+	}
+	key := pointer[1:]
+	if strings.Contains(key, "/") {
+		return "", fmt.Errorf("path %q must point to top-level field", pointer)
+	}
+	key = strings.ReplaceAll(key, "~1", "/")
+	key = strings.ReplaceAll(key, "~0", "~")
+	return key, nil
+}
+
+func copyDocument(doc map[string]any) map[string]any {
+	res := make(map[string]any, len(doc))
+	for key, value := range doc {
+		res[key] = value
+	}
+	return res
+}
@@ -0,0 +1,191 @@
+package jsonpatch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testDocument() map[string]any {
+	return map[string]any{
+		"name":        "fire",
+		"type":        "danger",
+		"description": "big fire",
+		"radius":      float64(100),
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		patch       string
+		expected    map[string]any
+		containsErr string
+	}{
+		{
+			name:  "replace_and_remove",
+			patch: `{"name": "smoke", "description": null}`,
+			expected: map[string]any{
+				"name":   "smoke",
+				"type":   "danger",
+				"radius": float64(100),
+			},
+		},
+		{
+			name:  "add_new_member",
+			patch: `{"status": "resolved"}`,
+			expected: map[string]any{
+				"name":        "fire",
+				"type":        "danger",
+				"description": "big fire",
+				"radius":      float64(100),
+				"status":      "resolved",
+			},
+		},
+		{
+			name:  "nested_object_merge",
+			patch: `{"radius": {"value": 5, "unit": null}}`,
+			expected: map[string]any{
+				"name":        "fire",
+				"type":        "danger",
+				"description": "big fire",
+				"radius":      map[string]any{"value": float64(5)},
+			},
+		},
+		{
+			name:        "not_object",
+			patch:       `[{"op": "add"}]`,
+			containsErr: "merge patch must be JSON object",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := testDocument()
+			got, err := ApplyMergePatch(doc, []byte(tc.patch))
+			if tc.containsErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.containsErr) {
+					t.Fatalf("ERROR: got: %v, expect contains: %s\n", err, tc.containsErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("RESULT: got: %v, expect: %v\n", got, tc.expected)
+			}
+			if !reflect.DeepEqual(doc, testDocument()) {
+				t.Errorf("source document must not be modified\n")
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name          string
+		patch         string
+		expected      map[string]any
+		containsErr   string
+		expectTestErr bool
+	}{
+		{
+			name:  "replace_and_remove",
+			patch: `[{"op": "replace", "path": "/radius", "value": 300}, {"op": "remove", "path": "/description"}]`,
+			expected: map[string]any{
+				"name":   "fire",
+				"type":   "danger",
+				"radius": float64(300),
+			},
+		},
+		{
+			name:  "test_then_add",
+			patch: `[{"op": "test", "path": "/name", "value": "fire"}, {"op": "add", "path": "/status", "value": "resolved"}]`,
+			expected: map[string]any{
+				"name":        "fire",
+				"type":        "danger",
+				"description": "big fire",
+				"radius":      float64(100),
+				"status":      "resolved",
+			},
+		},
+		{
+			name:  "move_and_copy",
+			patch: `[{"op": "copy", "from": "/name", "path": "/type"}, {"op": "move", "from": "/description", "path": "/name"}]`,
+			expected: map[string]any{
+				"name":   "big fire",
+				"type":   "fire",
+				"radius": float64(100),
+			},
+		},
+		{
+			name:  "escaped_pointer",
+			patch: `[{"op": "add", "path": "/a~1b~0c", "value": null}]`,
+			expected: map[string]any{
+				"name":        "fire",
+				"type":        "danger",
+				"description": "big fire",
+				"radius":      float64(100),
+				"a/b~c":       nil,
+			},
+		},
+		{
+			name:          "test_failed",
+			patch:         `[{"op": "test", "path": "/radius", "value": 200}]`,
+			containsErr:   "patch test failed: /radius",
+			expectTestErr: true,
+		},
+		{
+			name:        "replace_missing_path",
+			patch:       `[{"op": "replace", "path": "/status", "value": "active"}]`,
+			containsErr: "path /status not found",
+		},
+		{
+			name:        "nested_path",
+			patch:       `[{"op": "add", "path": "/name/first", "value": "a"}]`,
+			containsErr: "must point to top-level field",
+		},
+		{
+			name:        "missing_value",
+			patch:       `[{"op": "add", "path": "/name"}]`,
+			containsErr: "value is required",
+		},
+		{
+			name:        "unknown_op",
+			patch:       `[{"op": "merge", "path": "/name", "value": 1}]`,
+			containsErr: "unknown op",
+		},
+		{
+			name:        "not_array",
+			patch:       `{"name": "a"}`,
+			containsErr: "json patch must be array of operations",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := testDocument()
+			got, err := Apply(doc, []byte(tc.patch))
+			if tc.containsErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.containsErr) {
+					t.Fatalf("ERROR: got: %v, expect contains: %s\n", err, tc.containsErr)
+				}
+				if errors.Is(err, ErrTestFailed) != tc.expectTestErr {
+					t.Errorf("ErrTestFailed: got: %v, expect: %v\n", errors.Is(err, ErrTestFailed), tc.expectTestErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("RESULT: got: %v, expect: %v\n", got, tc.expected)
+			}
+			if !reflect.DeepEqual(doc, testDocument()) {
+				t.Errorf("source document must not be modified\n")
+			}
+		})
+	}
+}
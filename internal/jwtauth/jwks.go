@@ -0,0 +1,195 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefreshInterval protects the provider from a refresh per request
+	// when tokens with unknown kid are sent
+	minRefreshInterval = 30 * time.Second
+	maxJWKSSize        = 1 << 20
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet keeps public keys of the JWKS document, location is a file path or http(s) URL.
+// Keys are reloaded after refresh interval and when a token is signed with an unknown kid.
+type keySet struct {
+	location string
+	refresh  time.Duration
+	client   *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newKeySet(location string, refresh time.Duration) *keySet {
+	return &keySet{
+		location: location,
+		refresh:  refresh,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (ks *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[kid]
+	age := time.Since(ks.fetchedAt)
+	if ks.keys != nil && age < ks.refresh && (ok || age < minRefreshInterval) {
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown kid %q", ErrInvalidToken, kid)
+		}
+		return key, nil
+	}
+
+	keys, err := ks.load(ctx)
+	if err != nil {
+		// the provider may be down for a moment, old keys are still valid
+		if ks.keys == nil {
+			return nil, err
+		}
+		keys = ks.keys
+	}
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	key, ok = keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kid %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (ks *keySet) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var b []byte
+	var err error
+	if strings.HasPrefix(ks.location, "http://") || strings.HasPrefix(ks.location, "https://") {
+		b, err = ks.download(ctx)
+	} else {
+		b, err = os.ReadFile(strings.TrimPrefix(ks.location, "file://"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load jwks: %w", err)
+	}
+	return parseJWKS(b)
+}
+
+func (ks *keySet) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// parseJWKS skips keys it cannot use (encryption keys, unsupported types),
+// a document without any usable key is an error.
+func parseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	doc := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range doc.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("parse jwks key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("parse jwks: no signing keys")
+	}
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("rsa exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("ec point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(str string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url number")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,309 @@
+// Package jwtauth validates bearer JWTs issued by an OIDC provider against its JWKS
+// and maps the roles claim to scopes of the caller identity.
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	// hash implementations used through crypto.Hash
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+)
+
+// ActorPrefix marks identities authenticated with a bearer token in audit records.
+const ActorPrefix = "oidc:"
+
+const (
+	DefaultRolesClaim  = "roles"
+	DefaultTenantClaim = "tenant"
+	DefaultRefresh     = time.Hour
+	DefaultLeeway      = 30 * time.Second
+)
+
+// ErrInvalidToken is wrapped by every error caused by the token itself,
+// other errors mean the keys could not be loaded.
+var ErrInvalidToken = errors.New("invalid token")
+
+type Config struct {
+	// JWKS is a file path or http(s) URL of the provider key set
+	JWKS     string
+	Issuer   string
+	Audience string
+	// RolesClaim is a claim name or a dotted path, e.g. realm_access.roles
+	RolesClaim string
+	// RoleScopes maps the provider roles to scopes, roles without mapping are ignored
+	RoleScopes map[string][]string
+	// Roles maps the provider roles to service roles, the most privileged one is used
+	Roles map[string]string
+	// TenantClaim holds the tenant of the caller, tokens without it belong to the default tenant
+	TenantClaim string
+	Refresh     time.Duration
+	Leeway      time.Duration
+}
+
+type Verifier struct {
+	keys        *keySet
+	issuer      string
+	audience    string
+	rolesClaim  []string
+	roleScopes  map[string][]string
+	roles       map[string]string
+	tenantClaim string
+	leeway      time.Duration
+	now         func() time.Time
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if cfg.JWKS == "" {
+		return nil, fmt.Errorf("jwks cannot be empty")
+	}
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("issuer cannot be empty")
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("audience cannot be empty")
+	}
+	for role, scopes := range cfg.RoleScopes {
+		for _, scope := range scopes {
+			if !slices.Contains(identity.Scopes, scope) {
+				return nil, fmt.Errorf("role %s: unknown scope %s", role, scope)
+			}
+		}
+	}
+	for providerRole, role := range cfg.Roles {
+		if !identity.IsKnownRole(role) {
+			return nil, fmt.Errorf("role %s: unknown service role %s", providerRole, role)
+		}
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = DefaultRolesClaim
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = DefaultTenantClaim
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = DefaultRefresh
+	}
+	if cfg.Leeway < 0 {
+		cfg.Leeway = 0
+	}
+	return &Verifier{
+		keys:        newKeySet(cfg.JWKS, cfg.Refresh),
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		rolesClaim:  strings.Split(cfg.RolesClaim, "."),
+		roleScopes:  cfg.RoleScopes,
+		roles:       cfg.Roles,
+		tenantClaim: cfg.TenantClaim,
+		leeway:      cfg.Leeway,
+		now:         time.Now,
+	}, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Authenticate verifies the token and returns the caller identity with scopes of all mapped roles
+// and the most privileged of mapped service roles.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (*identity.Identity, error) {
+	claims, err := v.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, fmt.Errorf("%w: sub is empty", ErrInvalidToken)
+	}
+	tenant := identity.DefaultTenant
+	if value, ok := claims[v.tenantClaim]; ok {
+		str, _ := value.(string)
+		if !identity.IsValidTenant(str) {
+			return nil, fmt.Errorf("%w: invalid tenant", ErrInvalidToken)
+		}
+		tenant = str
+	}
+	scopes := []string{}
+	serviceRoles := []string{}
+	for _, role := range v.claimRoles(claims) {
+		for _, scope := range v.roleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		if serviceRole, ok := v.roles[role]; ok {
+			serviceRoles = append(serviceRoles, serviceRole)
+		}
+	}
+	return &identity.Identity{
+		Subject: ActorPrefix + sub,
+		Role:    identity.HighestRole(serviceRoles),
+		Tenant:  tenant,
+		Scopes:  scopes,
+	}, nil
+}
+
+func (v *Verifier) verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	h := header{}
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	hashFunc, ok := algorithms[h.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, h.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	key, err := v.keys.key(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	hasher := hashFunc.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(h.Alg, key, hashFunc, hasher, signature); err != nil {
+		return nil, err
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+}
+
+// verifySignature checks that the key type matches the alg, so an RSA key cannot be used with ES tokens.
+func verifySignature(alg string, key crypto.PublicKey, hashFunc crypto.Hash, hasher hash.Hash, signature []byte) error {
+	digest := hasher.Sum(nil)
+	switch alg[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key does not match alg %s", ErrInvalidToken, alg)
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, hashFunc, digest, signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key does not match alg %s", ErrInvalidToken, alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, alg)
+}
+
+func (v *Verifier) validateClaims(claims map[string]any) error {
+	now := v.now()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+	if !now.Before(exp.Add(v.leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.leeway).Before(nbf) {
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	}
+	if iss, _ := claims["iss"].(string); iss != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	// aud is a string or an array of strings
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud == v.audience {
+			return nil
+		}
+	case []any:
+		if slices.Contains(aud, any(v.audience)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+}
+
+// claimRoles reads the roles claim by path, the value is an array of strings or a space separated string.
+func (v *Verifier) claimRoles(claims map[string]any) []string {
+	var value any = claims
+	for _, name := range v.rolesClaim {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = obj[name]
+	}
+	switch roles := value.(type) {
+	case string:
+		return strings.Fields(roles)
+	case []any:
+		res := []string{}
+		for _, role := range roles {
+			if str, ok := role.(string); ok {
+				res = append(res, str)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func numericDate(value any) (time.Time, bool) {
+	num, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(num), 0), true
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+)
+
+const (
+	testIssuer   = "https://id.example.com/realms/city"
+	testAudience = "incidents-admin"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]any {
+	return map[string]any{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes())}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]any {
+	return map[string]any{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))}
+}
+
+func jwksDocument(t *testing.T, keys ...map[string]any) []byte {
+	b, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	h, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	c, _ := json.Marshal(claims)
+	input := b64(h) + "." + b64(c)
+	digest := sha256.Sum256([]byte(input))
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + b64(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "user-1",
+		"iss": testIssuer,
+		"aud": []any{"account", testAudience},
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{
+			"roles": []any{"incident-operator", "offline_access"},
+		},
+	}
+}
+
+func TestVerifier_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	doc := jwksDocument(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))
+	if err := os.WriteFile(path, doc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewVerifier(Config{
+		JWKS:       path,
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		RolesClaim: "realm_access.roles",
+		RoleScopes: map[string][]string{
+			"incident-operator": {identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite},
+		},
+		Roles:  map[string]string{"incident-operator": identity.RoleOperator},
+		Leeway: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withClaim := func(name string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	testCases := []struct {
+		name           string
+		token          string
+		expectedError  string
+		expectedScopes []string
+		expectedRole   string
+		expectedTenant string
+	}{
+		{
+			name:           "rs256",
+			token:          signToken(t, "RS256", "rsa-1", rsaKey, validClaims()),
+			expectedScopes: []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite},
+			expectedRole:   identity.RoleOperator,
+		},
+		{
+			name:           "es256",
+			token:          signToken(t, "ES256", "ec-1", ecKey, validClaims()),
+			expectedScopes: []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite},
+			expectedRole:   identity.RoleOperator,
+		},
+		{
+			name:           "tenant_claim",
+			token:          signToken(t, "RS256", "rsa-1", rsaKey, withClaim("tenant", "moscow")),
+			expectedScopes: []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite},
+			expectedRole:   identity.RoleOperator,
+			expectedTenant: "moscow",
+		},
+		{
+			name:          "invalid_tenant",
+			token:         signToken(t, "RS256", "rsa-1", rsaKey, withClaim("tenant", "Moscow City")),
+			expectedError: "invalid token: invalid tenant",
+		},
+		{
+			name:           "no_mapped_roles",
+			token:          signToken(t, "RS256", "rsa-1", rsaKey, withClaim("realm_access", nil)),
+			expectedScopes: []string{},
+		},
+		{
+			name:          "expired",
+			token:         signToken(t, "RS256", "rsa-1", rsaKey, withClaim("exp", time.Now().Add(-time.Minute).Unix())),
+			expectedError: "invalid token: token expired",
+		},
+		{
+			name:          "not_valid_yet",
+			token:         signToken(t, "RS256", "rsa-1", rsaKey, withClaim("nbf", time.Now().Add(time.Minute).Unix())),
+			expectedError: "invalid token: token not valid yet",
+		},
+		{
+			name:          "wrong_audience",
+			token:         signToken(t, "RS256", "rsa-1", rsaKey, withClaim("aud", "account")),
+			expectedError: "invalid token: unexpected audience",
+		},
+		{
+			name:          "wrong_issuer",
+			token:         signToken(t, "RS256", "rsa-1", rsaKey, withClaim("iss", "https://evil.example.com")),
+			expectedError: "invalid token: unexpected issuer",
+		},
+		{
+			name:          "signed_by_other_key",
+			token:         signToken(t, "RS256", "rsa-1", otherKey, validClaims()),
+			expectedError: "invalid token: bad signature",
+		},
+		{
+			name:          "key_of_other_type",
+			token:         signToken(t, "ES256", "rsa-1", ecKey, validClaims()),
+			expectedError: "invalid token: key does not match alg ES256",
+		},
+		{
+			name:          "unknown_kid",
+			token:         signToken(t, "RS256", "rsa-2", rsaKey, validClaims()),
+			expectedError: `invalid token: unknown kid "rsa-2"`,
+		},
+		{
+			name:          "alg_none",
+			token:         signToken(t, "none", "rsa-1", rsaKey, validClaims()),
+			expectedError: `invalid token: unsupported alg "none"`,
+		},
+		{
+			name:          "malformed",
+			token:         "abc.def",
+			expectedError: "invalid token: malformed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := verifier.Authenticate(context.Background(), tc.token)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("error: got: %v, expect: %s", err, tc.expectedError)
+				}
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("error %v does not wrap ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+			if id.Subject != "oidc:user-1" {
+				t.Errorf("subject: got %s", id.Subject)
+			}
+			if !slices.Equal(id.Scopes, tc.expectedScopes) {
+				t.Errorf("scopes: got %v, expect %v", id.Scopes, tc.expectedScopes)
+			}
+			if id.Role != tc.expectedRole {
+				t.Errorf("role: got %q, expect %q", id.Role, tc.expectedRole)
+			}
+			expectedTenant := tc.expectedTenant
+			if expectedTenant == "" {
+				expectedTenant = identity.DefaultTenant
+			}
+			if id.Tenant != expectedTenant {
+				t.Errorf("tenant: got %q, expect %q", id.Tenant, expectedTenant)
+			}
+		})
+	}
+}
+
+func TestVerifier_RefreshOnUnknownKid(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var rotated atomic.Bool
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if rotated.Load() {
+			w.Write(jwksDocument(t, rsaJWK("new", &newKey.PublicKey)))
+			return
+		}
+		w.Write(jwksDocument(t, rsaJWK("old", &oldKey.PublicKey)))
+	}))
+	defer server.Close()
+
+	verifier, err := NewVerifier(Config{JWKS: server.URL, Issuer: testIssuer, Audience: testAudience})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := validClaims()
+	if _, err := verifier.Authenticate(context.Background(), signToken(t, "RS256", "old", oldKey, claims)); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if _, err := verifier.Authenticate(context.Background(), signToken(t, "RS256", "old", oldKey, claims)); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if requests.Load() != 1 {
+		t.Errorf("keys must be cached, got %d requests", requests.Load())
+	}
+
+	rotated.Store(true)
+	// the provider rotated keys right after the last fetch, refresh waits for minRefreshInterval
+	verifier.keys.fetchedAt = time.Now().Add(-minRefreshInterval)
+	if _, err := verifier.Authenticate(context.Background(), signToken(t, "RS256", "new", newKey, claims)); err != nil {
+		t.Fatalf("unexpected error after rotation: %s", err.Error())
+	}
+	if requests.Load() != 2 {
+		t.Errorf("unknown kid must reload keys, got %d requests", requests.Load())
+	}
+}
+
+func TestNewVerifier_UnknownScope(t *testing.T) {
+	_, err := NewVerifier(Config{
+		JWKS:       "jwks.json",
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		RoleScopes: map[string][]string{"admin": {"incidents:delete"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown scope incidents:delete") {
+		t.Errorf("error: got: %v", err)
+	}
+}
+
+func TestNewVerifier_UnknownRole(t *testing.T) {
+	_, err := NewVerifier(Config{
+		JWKS:     "jwks.json",
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Roles:    map[string]string{"incident-admin": "root"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown service role root") {
+		t.Errorf("error: got: %v", err)
+	}
+}
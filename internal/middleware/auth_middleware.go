@@ -1,23 +1,91 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Piccadilly98/incidents_service/internal/handlers"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/jwtauth"
+	"github.com/Piccadilly98/incidents_service/internal/service"
 )
 
-const headerAPI = "X-API-Key"
+const (
+	headerAPI             = "X-API-Key"
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+	bearerPrefix          = "Bearer "
+)
+
+// APIKeyAuthenticator resolves the key from the request header, *service.Service implements it.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*identity.Identity, error)
+}
+
+// BearerAuthenticator validates the token from the Authorization header, *jwtauth.Verifier implements it.
+type BearerAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*identity.Identity, error)
+}
+
+// CheckMiddleware accepts either a bearer token or an API key, the bearer token is checked
+// first when both are sent. bearer is nil when tokens are not configured.
+func CheckMiddleware(keys APIKeyAuthenticator, bearer BearerAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authorization := r.Header.Get(headerAuthorization); authorization != "" {
+				checkBearer(w, r, next, bearer, authorization)
+				return
+			}
+			id, err := keys.AuthenticateAPIKey(r.Context(), r.Header.Get(headerAPI))
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				handlers.ErrorResponse(w, err, http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				handlers.ErrorResponse(w, fmt.Errorf("service unavailable"), http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(identity.WithIdentity(r.Context(), id)))
+		})
+	}
+}
+
+func checkBearer(w http.ResponseWriter, r *http.Request, next http.Handler, bearer BearerAuthenticator, authorization string) {
+	if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		w.Header().Set(headerWWWAuthenticate, `Bearer error="invalid_request"`)
+		handlers.ErrorResponse(w, fmt.Errorf("invalid authorization header: bearer token expected"), http.StatusUnauthorized)
+		return
+	}
+	if bearer == nil {
+		handlers.ErrorResponse(w, fmt.Errorf("bearer tokens are not enabled"), http.StatusUnauthorized)
+		return
+	}
+	id, err := bearer.Authenticate(r.Context(), strings.TrimSpace(authorization[len(bearerPrefix):]))
+	if errors.Is(err, jwtauth.ErrInvalidToken) {
+		w.Header().Set(headerWWWAuthenticate, `Bearer error="invalid_token"`)
+		handlers.ErrorResponse(w, err, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		handlers.ErrorResponse(w, fmt.Errorf("service unavailable"), http.StatusServiceUnavailable)
+		return
+	}
+	next.ServeHTTP(w, r.WithContext(identity.WithIdentity(r.Context(), id)))
+}
 
-func CheckMiddleware(validApiKey string) func(http.Handler) http.Handler {
+// RequirePermission checks the role and the scopes of the caller against the permission matrix,
+// must be used after CheckMiddleware.
+func RequirePermission(perm string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get(headerAPI)
-			if apiKey == validApiKey {
-				next.ServeHTTP(w, r)
+			if !identity.FromContext(r.Context()).Can(perm) {
+				handlers.ErrorResponse(w, fmt.Errorf("permission denied: %s required", perm), http.StatusForbidden)
 				return
 			}
-			handlers.ErrorResponse(w, fmt.Errorf("invalid api-key"), http.StatusForbidden)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
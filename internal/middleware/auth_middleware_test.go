@@ -1,51 +1,92 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/jwtauth"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/Piccadilly98/incidents_service/internal/service"
 )
 
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (*identity.Identity, error) {
+	return nil, fmt.Errorf("dial tcp: connection refused")
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	validApiKey := "valid-api-key"
+	serv := service.NewService(repository.NewMockDb(), nil, nil, nil)
+	if err := serv.BootstrapAPIKey(context.Background(), validApiKey); err != nil {
+		t.Fatalf("bootstrap: %s", err.Error())
+	}
+	revoked, err := serv.IssueAPIKey(context.Background(), &dto.APIKeyIssueRequest{Name: "old", Role: identity.RoleViewer, Scopes: []string{identity.ScopeStatsRead}})
+	if err != nil {
+		t.Fatalf("issue: %s", err.Error())
+	}
+	if err := serv.RevokeAPIKey(context.Background(), revoked.ID); err != nil {
+		t.Fatalf("revoke: %s", err.Error())
+	}
+
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintln(w, `{"status": "success"}`)
 	})
 
-	middleware := CheckMiddleware(validApiKey)
-	handler := middleware(nextHandler)
-
 	testCases := []struct {
 		name           string
+		auth           APIKeyAuthenticator
 		apiKey         string
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
 			name:           "valid_api_key_passes_through",
+			auth:           serv,
 			apiKey:         validApiKey,
 			expectedStatus: http.StatusOK,
 			expectedBody:   `"status": "success"`,
 		},
 		{
 			name:           "invalid_api_key_returns_403",
+			auth:           serv,
 			apiKey:         "wrong-key",
 			expectedStatus: http.StatusForbidden,
 			expectedBody:   "invalid api-key",
 		},
 		{
 			name:           "missing_api_key_returns_403",
+			auth:           serv,
 			apiKey:         "",
 			expectedStatus: http.StatusForbidden,
 			expectedBody:   "invalid api-key",
 		},
+		{
+			name:           "revoked_api_key_returns_403",
+			auth:           serv,
+			apiKey:         revoked.Key,
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "invalid api-key",
+		},
+		{
+			name:           "storage_error_returns_503",
+			auth:           failingAuthenticator{},
+			apiKey:         validApiKey,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "service unavailable",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
+			handler := CheckMiddleware(tc.auth, nil)(nextHandler)
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			if tc.apiKey != "" {
 				req.Header.Set(headerAPI, tc.apiKey)
@@ -65,3 +106,148 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 
 }
+
+type fakeBearer struct{}
+
+func (fakeBearer) Authenticate(ctx context.Context, token string) (*identity.Identity, error) {
+	switch token {
+	case "good":
+		return &identity.Identity{Subject: "oidc:user-1"}, nil
+	case "down":
+		return nil, fmt.Errorf("load jwks: connection refused")
+	}
+	return nil, fmt.Errorf("%w: token expired", jwtauth.ErrInvalidToken)
+}
+
+func TestAuthMiddleware_Bearer(t *testing.T) {
+	var subject string
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject = identity.Actor(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name                 string
+		bearer               BearerAuthenticator
+		authorization        string
+		expectedStatus       int
+		expectedBody         string
+		expectedAuthenticate string
+		expectedSubject      string
+	}{
+		{
+			name:            "valid_token",
+			bearer:          fakeBearer{},
+			authorization:   "Bearer good",
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "oidc:user-1",
+		},
+		{
+			name:                 "invalid_token_returns_401",
+			bearer:               fakeBearer{},
+			authorization:        "bearer expired",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         "token expired",
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:           "jwks_unavailable_returns_503",
+			bearer:         fakeBearer{},
+			authorization:  "Bearer down",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "service unavailable",
+		},
+		{
+			name:                 "basic_auth_rejected",
+			bearer:               fakeBearer{},
+			authorization:        "Basic dXNlcjpwYXNz",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         "bearer token expected",
+			expectedAuthenticate: `Bearer error="invalid_request"`,
+		},
+		{
+			name:           "bearer_not_configured",
+			authorization:  "Bearer good",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "bearer tokens are not enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subject = ""
+			handler := CheckMiddleware(failingAuthenticator{}, tc.bearer)(nextHandler)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(headerAuthorization, tc.authorization)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("Status code: got %d, want %d", rr.Code, tc.expectedStatus)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBody) {
+				t.Errorf("Response body:\nGOT:  %s\nWANT to contain: %s", rr.Body.String(), tc.expectedBody)
+			}
+			if got := rr.Header().Get(headerWWWAuthenticate); got != tc.expectedAuthenticate {
+				t.Errorf("WWW-Authenticate: got %q, want %q", got, tc.expectedAuthenticate)
+			}
+			if subject != tc.expectedSubject {
+				t.Errorf("Subject: got %q, want %q", subject, tc.expectedSubject)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequirePermission(identity.PermIncidentsCreate)(nextHandler)
+	writeScopes := []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite}
+
+	testCases := []struct {
+		name           string
+		identity       *identity.Identity
+		expectedStatus int
+	}{
+		{
+			name:           "operator_allowed",
+			identity:       &identity.Identity{Subject: "api-key:ops", Role: identity.RoleOperator, Scopes: writeScopes},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "viewer_denied",
+			identity:       &identity.Identity{Subject: "api-key:viewer", Role: identity.RoleViewer, Scopes: writeScopes},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin_without_scope",
+			identity:       &identity.Identity{Subject: "api-key:admin", Role: identity.RoleAdmin, Scopes: []string{identity.ScopeIncidentsRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no_role",
+			identity:       &identity.Identity{Subject: "oidc:user", Scopes: writeScopes},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no_identity",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.identity != nil {
+				req = req.WithContext(identity.WithIdentity(req.Context(), tc.identity))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("Status code: got %d, want %d", rr.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// maxPeekBodyLen is far above any location check, larger bodies are not inspected by middlewares
+const maxPeekBodyLen = 64 << 10
+
+// peekBody reads up to maxPeekBodyLen bytes of the body and puts them back, so the handler reads
+// the whole body again. complete is false when the body is longer than the read part.
+func peekBody(r *http.Request) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBodyLen+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) > maxPeekBodyLen {
+		return b[:maxPeekBodyLen], false, nil
+	}
+	return b, true, nil
+}
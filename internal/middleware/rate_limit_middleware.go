@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/handlers"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+const (
+	headerRetryAfter   = "Retry-After"
+	headerForwardedFor = "X-Forwarded-For"
+)
+
+var rateLimitLogger = log.New(os.Stderr, "[RATE LIMIT] ", log.Ldate|log.Ltime)
+
+// RateLimitMiddleware keeps a token bucket per client IP and API key, a request is rejected with 429
+// when any of its buckets is empty. Limiter errors let the request through: a broken Redis must not
+// stop location checks.
+func RateLimitMiddleware(limiter repository.RateLimiter, route string, limit config.RateLimit, trustForwarded bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil || limit.Requests <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if takeTokens(w, r, limiter, route, limit, clientKeys(r, trustForwarded)) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// UserRateLimitMiddleware keeps a token bucket per user_id of the JSON body. It runs after
+// SignatureMiddleware: unsigned requests use their own buckets, so requests with a forged user_id
+// cannot use up the bucket of the signed app of that user.
+func UserRateLimitMiddleware(limiter repository.RateLimiter, route string, limit config.RateLimit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil || limit.Requests <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := peekUserID(r)
+			if userID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			prefix := "unsigned-user:"
+			if signedKeyID(r.Context()) != "" {
+				prefix = "user:"
+			}
+			if takeTokens(w, r, limiter, route, limit, []string{prefix + identity.Tenant(r.Context()) + ":" + userID}) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// takeTokens takes a token from every bucket and writes 429 when one of them is empty.
+func takeTokens(w http.ResponseWriter, r *http.Request, limiter repository.RateLimiter, route string, limit config.RateLimit, keys []string) bool {
+	for _, key := range keys {
+		allowed, retryAfter, err := limiter.Take(r.Context(), route+":"+key, limit.Requests, limit.Period)
+		if err != nil {
+			rateLimitLogger.Printf("ERROR: route %s: %s, request is let through", route, err.Error())
+			return true
+		}
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set(headerRetryAfter, strconv.Itoa(seconds))
+			handlers.ErrorResponse(w, fmt.Errorf("too many requests: retry after %d seconds", seconds), http.StatusTooManyRequests)
+			return false
+		}
+	}
+	return true
+}
+
+// clientKeys returns bucket keys of the client, the API key is hashed so that secrets do not end up in Redis.
+func clientKeys(r *http.Request, trustForwarded bool) []string {
+	keys := []string{"ip:" + clientIP(r, trustForwarded)}
+	if apiKey := r.Header.Get(headerAPI); apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		keys = append(keys, "key:"+hex.EncodeToString(sum[:8]))
+	}
+	return keys
+}
+
+// clientIP takes the address added by the nearest proxy when the service runs behind one,
+// the leftmost entries of X-Forwarded-For are set by the client and cannot be trusted.
+func clientIP(r *http.Request, trustForwarded bool) string {
+	if trustForwarded {
+		if forwarded := r.Header.Get(headerForwardedFor); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// peekUserID reads user_id from the JSON body, the body stays readable for the handler.
+func peekUserID(r *http.Request) string {
+	if r.Header.Get(handlers.HeaderContentType) != handlers.HeaderJson {
+		return ""
+	}
+	b, complete, err := peekBody(r)
+	if err != nil || !complete {
+		return ""
+	}
+	body := struct {
+		UserID string `json:"user_id"`
+	}{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return ""
+	}
+	return body.UserID
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limit := config.RateLimit{Requests: 2, Period: 10 * time.Second}
+	body := `{"user_id": "user-1", "latitude": "55.75", "longitude": "37.61"}`
+	signedRequest := func(remoteAddr, userBody string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/location/check", strings.NewReader(userBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		return req.WithContext(context.WithValue(req.Context(), signedKeyIDKey{}, "ios-1"))
+	}
+	newRequest := func(remoteAddr, apiKey, userBody string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/location/check", strings.NewReader(userBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set(headerAPI, apiKey)
+		}
+		return req
+	}
+
+	testCases := []struct {
+		name           string
+		limiterErr     error
+		requests       []*http.Request
+		expectedStatus []int
+	}{
+		{
+			name: "same_user_from_different_ips",
+			requests: []*http.Request{
+				newRequest("10.0.0.1:1000", "", body),
+				newRequest("10.0.0.2:1000", "", body),
+				newRequest("10.0.0.3:1000", "", body),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "same_ip_for_different_users",
+			requests: []*http.Request{
+				newRequest("10.0.0.1:1000", "", `{"user_id": "a"}`),
+				newRequest("10.0.0.1:1001", "", `{"user_id": "b"}`),
+				newRequest("10.0.0.1:1002", "", `{"user_id": "c"}`),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "same_api_key",
+			requests: []*http.Request{
+				newRequest("10.0.0.1:1000", "partner", `{"user_id": "a"}`),
+				newRequest("10.0.0.2:1000", "partner", `{"user_id": "b"}`),
+				newRequest("10.0.0.3:1000", "partner", `{"user_id": "c"}`),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "forged_user_id_does_not_lock_out_signed_app",
+			requests: []*http.Request{
+				newRequest("10.0.0.1:1000", "", body),
+				newRequest("10.0.0.2:1000", "", body),
+				newRequest("10.0.0.3:1000", "", body),
+				signedRequest("10.0.0.4:1000", body),
+				signedRequest("10.0.0.5:1000", body),
+				signedRequest("10.0.0.6:1000", body),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:       "limiter_failure_lets_through",
+			limiterErr: fmt.Errorf("dial tcp: connection refused"),
+			requests: []*http.Request{
+				newRequest("10.0.0.1:1000", "", body),
+				newRequest("10.0.0.1:1000", "", body),
+				newRequest("10.0.0.1:1000", "", body),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := repository.NewRateLimiterMock()
+			limiter.Err = tc.limiterErr
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := RateLimitMiddleware(limiter, config.RateLimitRouteLocationCheck, limit, false)(
+				UserRateLimitMiddleware(limiter, config.RateLimitRouteLocationCheck, limit)(next))
+			for i, req := range tc.requests {
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if rr.Code != tc.expectedStatus[i] {
+					t.Fatalf("request %d: status: got %d, expect %d", i, rr.Code, tc.expectedStatus[i])
+				}
+				if rr.Code == http.StatusTooManyRequests && rr.Header().Get(headerRetryAfter) != "5" {
+					t.Errorf("Retry-After: got %q, expect 5", rr.Header().Get(headerRetryAfter))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_KeepsBody(t *testing.T) {
+	body := `{"user_id": "user-1", "latitude": "55.75", "longitude": "37.61"}`
+	got := ""
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = string(b)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/location/check", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	limiter := repository.NewRateLimiterMock()
+	UserRateLimitMiddleware(limiter, config.RateLimitRouteLocationCheck, config.RateLimit{Requests: 1, Period: time.Second})(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != body {
+		t.Errorf("body: got %q, expect %q", got, body)
+	}
+	if limiter.Taken[config.RateLimitRouteLocationCheck+":unsigned-user:default:user-1"] != 1 {
+		t.Errorf("user bucket not used: %v", limiter.Taken)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1000"
+	req.Header.Set(headerForwardedFor, "1.1.1.1, 192.168.0.5")
+
+	if ip := clientIP(req, false); ip != "10.0.0.1" {
+		t.Errorf("without trusted proxy: got %s", ip)
+	}
+	if ip := clientIP(req, true); ip != "192.168.0.5" {
+		t.Errorf("with trusted proxy: got %s", ip)
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/google/uuid"
+)
+
+const (
+	headerRequestID = "X-Request-ID"
+	maxLenRequestID = 100
+)
+
+// RequestIDMiddleware takes request id from the client header or generates a new one,
+// puts it into the context for audit records and returns it in the response header.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(headerRequestID)
+		if requestID == "" || len(requestID) > maxLenRequestID {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(headerRequestID, requestID)
+		next.ServeHTTP(w, r.WithContext(identity.WithRequestID(r.Context(), requestID)))
+	})
+}
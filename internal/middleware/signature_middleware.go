@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/handlers"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/Piccadilly98/incidents_service/internal/signing"
+)
+
+const maxNonceLen = 128
+
+var signatureLogger = log.New(os.Stderr, "[SIGNATURE] ", log.Ldate|log.Ltime)
+
+type signedKeyIDKey struct{}
+
+// signedKeyID returns the app key id of a request with a verified signature.
+func signedKeyID(ctx context.Context) string {
+	keyID, _ := ctx.Value(signedKeyIDKey{}).(string)
+	return keyID
+}
+
+// SignatureMiddleware checks the app signature of public requests, see package signing for the scheme.
+// Unsigned requests pass only when signatures are not required, a wrong signature is always rejected.
+// The nonce is remembered for twice the allowed clock skew, so a captured request cannot be sent again
+// while its timestamp is still accepted. Nonce store errors let signed requests through.
+// A key signs checks of its own tenant only: the X-Tenant-ID header must be empty or match it.
+func SignatureMiddleware(keys map[string]config.AppSigningKey, nonces repository.NonceStore, required bool, maxSkew time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keyID := r.Header.Get(signing.HeaderKeyID)
+			if keyID == "" {
+				if required {
+					handlers.ErrorResponse(w, fmt.Errorf("signature required"), http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			key, ok := keys[keyID]
+			if !ok {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature: unknown key id"), http.StatusUnauthorized)
+				return
+			}
+			timestamp := r.Header.Get(signing.HeaderTimestamp)
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature: timestamp must be unix seconds"), http.StatusUnauthorized)
+				return
+			}
+			skew := time.Since(time.Unix(unix, 0))
+			if skew > maxSkew || skew < -maxSkew {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature: request expired"), http.StatusUnauthorized)
+				return
+			}
+			nonce := r.Header.Get(signing.HeaderNonce)
+			if nonce == "" || len(nonce) > maxNonceLen {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature: nonce must be 1-%d characters", maxNonceLen), http.StatusUnauthorized)
+				return
+			}
+			body, complete, err := peekBody(r)
+			if err != nil {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid request body"), http.StatusBadRequest)
+				return
+			}
+			if !complete {
+				handlers.ErrorResponse(w, fmt.Errorf("request body too large"), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if !signing.Verify(key.Secret, r.Header.Get(signing.HeaderSignature), r.Method, r.URL.RequestURI(), timestamp, nonce, body) {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature"), http.StatusUnauthorized)
+				return
+			}
+			if tenant := r.Header.Get(headerTenantID); tenant != "" && tenant != key.Tenant {
+				handlers.ErrorResponse(w, fmt.Errorf("permission denied: key %s cannot sign checks of tenant %s", keyID, tenant), http.StatusForbidden)
+				return
+			}
+			fresh, err := nonces.UseNonce(r.Context(), keyID+":"+nonce, 2*maxSkew)
+			if err != nil {
+				signatureLogger.Printf("ERROR: nonce of %s not checked: %s", keyID, err.Error())
+			} else if !fresh {
+				handlers.ErrorResponse(w, fmt.Errorf("invalid signature: nonce already used"), http.StatusUnauthorized)
+				return
+			}
+			ctx := context.WithValue(r.Context(), signedKeyIDKey{}, keyID)
+			next.ServeHTTP(w, r.WithContext(identity.WithTenant(ctx, key.Tenant)))
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/Piccadilly98/incidents_service/internal/signing"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	secret := strings.Repeat("s", 32)
+	keys := map[string]config.AppSigningKey{
+		"ios-1":     {Secret: secret, Tenant: "default"},
+		"android-1": {Secret: secret, Tenant: "moscow"},
+	}
+	body := `{"user_id": "user-1", "latitude": "55.75", "longitude": "37.61"}`
+	path := "/api/v1/location/check"
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	signed := func(keyID, timestamp, nonce, signBody, sendBody string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(sendBody))
+		req.Header.Set(signing.HeaderKeyID, keyID)
+		req.Header.Set(signing.HeaderTimestamp, timestamp)
+		req.Header.Set(signing.HeaderNonce, nonce)
+		req.Header.Set(signing.HeaderSignature, signing.Sign(secret, http.MethodPost, path, timestamp, nonce, []byte(signBody)))
+		return req
+	}
+	withTenant := func(req *http.Request, tenant string) *http.Request {
+		req.Header.Set(headerTenantID, tenant)
+		return req
+	}
+	unsigned := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	expired := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	testCases := []struct {
+		name           string
+		required       bool
+		nonceErr       error
+		requests       []*http.Request
+		expectedTenant string
+		expectedStatus []int
+	}{
+		{
+			name:           "valid_signature",
+			required:       true,
+			requests:       []*http.Request{signed("ios-1", now, "n1", body, body)},
+			expectedStatus: []int{http.StatusOK},
+		},
+		{
+			name:           "unsigned_when_required",
+			required:       true,
+			requests:       []*http.Request{unsigned},
+			expectedStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:           "unsigned_when_optional",
+			requests:       []*http.Request{httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))},
+			expectedStatus: []int{http.StatusOK},
+		},
+		{
+			name:           "wrong_signature_when_optional",
+			requests:       []*http.Request{signed("ios-1", now, "n1", body, `{"user_id": "other"}`)},
+			expectedStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:           "unknown_key_id",
+			required:       true,
+			requests:       []*http.Request{signed("web-1", now, "n1", body, body)},
+			expectedStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:           "key_of_tenant",
+			required:       true,
+			requests:       []*http.Request{withTenant(signed("android-1", now, "n1", body, body), "moscow")},
+			expectedTenant: "moscow",
+			expectedStatus: []int{http.StatusOK},
+		},
+		{
+			name:           "tenant_taken_from_key",
+			required:       true,
+			requests:       []*http.Request{signed("android-1", now, "n1", body, body)},
+			expectedTenant: "moscow",
+			expectedStatus: []int{http.StatusOK},
+		},
+		{
+			name:           "key_of_other_tenant",
+			required:       true,
+			requests:       []*http.Request{withTenant(signed("ios-1", now, "n1", body, body), "moscow")},
+			expectedStatus: []int{http.StatusForbidden},
+		},
+		{
+			name:           "expired_timestamp",
+			required:       true,
+			requests:       []*http.Request{signed("ios-1", expired, "n1", body, body)},
+			expectedStatus: []int{http.StatusUnauthorized},
+		},
+		{
+			name:     "replayed_nonce",
+			required: true,
+			requests: []*http.Request{
+				signed("ios-1", now, "n1", body, body),
+				signed("ios-1", now, "n1", body, body),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusUnauthorized},
+		},
+		{
+			name:     "nonce_store_failure_lets_through",
+			required: true,
+			nonceErr: fmt.Errorf("dial tcp: connection refused"),
+			requests: []*http.Request{
+				signed("ios-1", now, "n1", body, body),
+				signed("ios-1", now, "n1", body, body),
+			},
+			expectedStatus: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nonces := repository.NewNonceStoreMock()
+			nonces.Err = tc.nonceErr
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				if err != nil || len(b) == 0 {
+					t.Errorf("handler cannot read body: %v", err)
+				}
+				if tc.expectedTenant != "" && identity.Tenant(r.Context()) != tc.expectedTenant {
+					t.Errorf("tenant: got %s, expect %s", identity.Tenant(r.Context()), tc.expectedTenant)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := SignatureMiddleware(keys, nonces, tc.required, 5*time.Minute)(next)
+			for i, req := range tc.requests {
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				if rr.Code != tc.expectedStatus[i] {
+					t.Fatalf("request %d: status: got %d, expect %d, body: %s", i, rr.Code, tc.expectedStatus[i], rr.Body.String())
+				}
+			}
+		})
+	}
+}
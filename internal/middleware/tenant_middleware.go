@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Piccadilly98/incidents_service/internal/handlers"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+)
+
+const headerTenantID = "X-Tenant-ID"
+
+// TenantMiddleware sets the tenant of public requests from the client header,
+// requests without the header belong to the default tenant.
+func TenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(headerTenantID)
+		if tenant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !identity.IsValidTenant(tenant) {
+			handlers.ErrorResponse(w, fmt.Errorf("invalid %s header", headerTenantID), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(identity.WithTenant(r.Context(), tenant)))
+	})
+}
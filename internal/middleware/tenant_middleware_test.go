@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	testCases := []struct {
+		name           string
+		header         string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			name:           "no_header",
+			expectedStatus: http.StatusOK,
+			expectedTenant: identity.DefaultTenant,
+		},
+		{
+			name:           "valid_tenant",
+			header:         "moscow",
+			expectedStatus: http.StatusOK,
+			expectedTenant: "moscow",
+		},
+		{
+			name:           "invalid_tenant",
+			header:         "../moscow",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenant := ""
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tenant = identity.Tenant(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/location/check", nil)
+			if tc.header != "" {
+				req.Header.Set(headerTenantID, tc.header)
+			}
+			rr := httptest.NewRecorder()
+			TenantMiddleware(next).ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("status: got %d, expect %d", rr.Code, tc.expectedStatus)
+			}
+			if tenant != tc.expectedTenant {
+				t.Errorf("tenant: got %q, expect %q", tenant, tc.expectedTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+type APIKeyIssueRequest struct {
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Tenant defaults to the tenant of the caller, keys for other tenants are issued from the default one
+	Tenant string `json:"tenant"`
+}
+
+func (a *APIKeyIssueRequest) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if utf8.RuneCountInString(a.Name) > 100 {
+		return fmt.Errorf("name cannot be longer than 100 characters")
+	}
+	if a.Role == "" {
+		return fmt.Errorf("role cannot be empty")
+	}
+	if len(a.Scopes) == 0 {
+		return fmt.Errorf("scopes cannot be empty")
+	}
+	return nil
+}
+
+type APIKeyRotateRequest struct {
+	// GraceMinutes keeps the old key working for a while so that clients can switch,
+	// the old key is revoked at once when it is not set
+	GraceMinutes *int `json:"grace_minutes"`
+}
+
+func (a *APIKeyRotateRequest) Validate() error {
+	if a.GraceMinutes != nil && *a.GraceMinutes < 0 {
+		return fmt.Errorf("grace_minutes cannot be < 0")
+	}
+	return nil
+}
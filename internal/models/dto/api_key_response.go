@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusExpired = "expired"
+	APIKeyStatusRevoked = "revoked"
+)
+
+type APIKeyResponse struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Role        string     `json:"role"`
+	Tenant      string     `json:"tenant"`
+	Scopes      []string   `json:"scopes"`
+	Status      string     `json:"status"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedDate time.Time  `json:"created_date"`
+	RevokedDate *time.Time `json:"revoked_date,omitempty"`
+}
+
+// APIKeyIssuedResponse is returned only by issue and rotate, Key is shown once and cannot be read later.
+type APIKeyIssuedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func ToAPIKeyResponse(key *entities.APIKey, now time.Time) *APIKeyResponse {
+	status := APIKeyStatusActive
+	switch {
+	case key.RevokedDate != nil:
+		status = APIKeyStatusRevoked
+	case key.ExpiresAt != nil && !key.ExpiresAt.After(now):
+		status = APIKeyStatusExpired
+	}
+	return &APIKeyResponse{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Role:        key.Role,
+		Tenant:      key.TenantID,
+		Scopes:      key.Scopes,
+		Status:      status,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		CreatedDate: key.CreatedDate,
+		RevokedDate: key.RevokedDate,
+	}
+}
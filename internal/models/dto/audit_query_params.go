@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditQueryParams struct {
+	PageNum    *int
+	IncidentID string
+	Actor      string
+	Action     string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+func (a *AuditQueryParams) Validate() error {
+	if a.IncidentID != "" {
+		_, err := uuid.Parse(a.IncidentID)
+		if err != nil {
+			return fmt.Errorf("%s: is not uuid", a.IncidentID)
+		}
+	}
+	if a.From != nil && a.To != nil && a.From.After(*a.To) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+type FieldChangeResponse struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditRecordResponse struct {
+	ID          string                         `json:"id"`
+	IncidentID  string                         `json:"incident_id"`
+	Action      string                         `json:"action"`
+	Actor       string                         `json:"actor"`
+	RequestID   string                         `json:"request_id,omitempty"`
+	Changes     map[string]FieldChangeResponse `json:"changes"`
+	CreatedDate time.Time                      `json:"created_date"`
+}
+
+type AuditListResponse struct {
+	Records      []*AuditRecordResponse `json:"records"`
+	CountRecords int                    `json:"records_count"`
+	PageNum      *int                   `json:"page_num,omitempty"`
+}
+
+func ToAuditListResponse(records []*entities.AuditRecord, pageNum *int) *AuditListResponse {
+	res := &AuditListResponse{
+		Records:      []*AuditRecordResponse{},
+		CountRecords: len(records),
+		PageNum:      pageNum,
+	}
+	for _, record := range records {
+		changes := make(map[string]FieldChangeResponse, len(record.Changes))
+		for field, change := range record.Changes {
+			changes[field] = FieldChangeResponse{
+				Before: change.Before,
+				After:  change.After,
+			}
+		}
+		res.Records = append(res.Records, &AuditRecordResponse{
+			ID:          record.ID,
+			IncidentID:  record.IncidentID,
+			Action:      record.Action,
+			Actor:       record.Actor,
+			RequestID:   record.RequestID,
+			Changes:     changes,
+			CreatedDate: record.CreatedDate,
+		})
+	}
+	return res
+}
@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkRequest selects incidents either by IDs or by Filter, exactly one of them must be set.
+type BulkRequest struct {
+	Operation string      `json:"operation"`
+	IDs       []string    `json:"ids"`
+	Filter    *BulkFilter `json:"filter"`
+	// Status is the target status of update_status
+	Status *string `json:"status"`
+	// Reason is stored in status history of update_status and archive
+	Reason *string `json:"reason"`
+	// Radius is the new radius of change_radius
+	Radius *int `json:"radius"`
+	DryRun bool `json:"dry_run"`
+}
+
+// BulkFilter has the same meaning as the query parameters of GET /incidents.
+type BulkFilter struct {
+	Statuses     []string   `json:"status"`
+	Types        []string   `json:"type"`
+	Name         string     `json:"name"`
+	NameContains string     `json:"name_contains"`
+	RadiusMin    *int       `json:"radius_min"`
+	RadiusMax    *int       `json:"radius_max"`
+	IsActive     *bool      `json:"is_active"`
+	CreatedFrom  *time.Time `json:"created_from"`
+	CreatedTo    *time.Time `json:"created_to"`
+	UpdatedFrom  *time.Time `json:"updated_from"`
+	UpdatedTo    *time.Time `json:"updated_to"`
+	ResolvedFrom *time.Time `json:"resolved_from"`
+	ResolvedTo   *time.Time `json:"resolved_to"`
+	Near         *BulkNear  `json:"near"`
+}
+
+type BulkNear struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Meters    int     `json:"meters"`
+}
+
+func (b *BulkRequest) Validate() error {
+	if b.Operation == "" {
+		return fmt.Errorf("operation cannot be empty")
+	}
+	if len(b.IDs) != 0 && b.Filter != nil {
+		return fmt.Errorf("ids cannot be combined with filter")
+	}
+	if len(b.IDs) == 0 && b.Filter == nil {
+		return fmt.Errorf("ids or filter must be set")
+	}
+	for _, id := range b.IDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("%s: is not uuid", id)
+		}
+	}
+	if b.Filter != nil {
+		if b.Filter.isEmpty() {
+			return fmt.Errorf("filter cannot be empty")
+		}
+		if err := b.Filter.ToQueryParams().Validate(); err != nil {
+			return err
+		}
+	}
+	if b.Status != nil && *b.Status == "" {
+		return fmt.Errorf("status cannot be empty")
+	}
+	if b.Reason != nil && *b.Reason == "" {
+		return fmt.Errorf("reason cannot be empty")
+	}
+	return nil
+}
+
+// isEmpty reports filter without conditions, it would match every incident.
+func (f *BulkFilter) isEmpty() bool {
+	return len(f.Statuses) == 0 && len(f.Types) == 0 &&
+		f.Name == "" && f.NameContains == "" &&
+		f.RadiusMin == nil && f.RadiusMax == nil && f.IsActive == nil &&
+		f.CreatedFrom == nil && f.CreatedTo == nil &&
+		f.UpdatedFrom == nil && f.UpdatedTo == nil &&
+		f.ResolvedFrom == nil && f.ResolvedTo == nil &&
+		f.Near == nil
+}
+
+func (f *BulkFilter) ToQueryParams() *PaginationQueryParams {
+	query := &PaginationQueryParams{
+		Statuses:     f.Statuses,
+		Types:        f.Types,
+		Name:         f.Name,
+		NameContains: f.NameContains,
+		RadiusMin:    f.RadiusMin,
+		RadiusMax:    f.RadiusMax,
+		IsActive:     f.IsActive,
+		CreatedFrom:  f.CreatedFrom,
+		CreatedTo:    f.CreatedTo,
+		UpdatedFrom:  f.UpdatedFrom,
+		UpdatedTo:    f.UpdatedTo,
+		ResolvedFrom: f.ResolvedFrom,
+		ResolvedTo:   f.ResolvedTo,
+	}
+	if f.Near != nil {
+		query.NearLatitude = &f.Near.Latitude
+		query.NearLongitude = &f.Near.Longitude
+		query.NearMeters = &f.Near.Meters
+	}
+	return query
+}
@@ -0,0 +1,80 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestBulkRequest_Validate(t *testing.T) {
+	id := "00000000-0000-0000-0000-000000000001"
+	testCases := []struct {
+		name          string
+		dto           *dto.BulkRequest
+		expectedError string
+	}{
+		{
+			name: "valid_ids",
+			dto:  &dto.BulkRequest{Operation: "archive", IDs: []string{id}},
+		},
+		{
+			name: "valid_filter_with_near",
+			dto: &dto.BulkRequest{Operation: "archive", Filter: &dto.BulkFilter{
+				Near: &dto.BulkNear{Latitude: 55.75, Longitude: 37.61, Meters: 500},
+			}},
+		},
+		{
+			name:          "empty_operation",
+			dto:           &dto.BulkRequest{IDs: []string{id}},
+			expectedError: "operation cannot be empty",
+		},
+		{
+			name:          "no_selection",
+			dto:           &dto.BulkRequest{Operation: "delete"},
+			expectedError: "ids or filter must be set",
+		},
+		{
+			name:          "ids_and_filter",
+			dto:           &dto.BulkRequest{Operation: "delete", IDs: []string{id}, Filter: &dto.BulkFilter{Types: []string{"fire"}}},
+			expectedError: "ids cannot be combined with filter",
+		},
+		{
+			name:          "bad_id",
+			dto:           &dto.BulkRequest{Operation: "delete", IDs: []string{"123"}},
+			expectedError: "123: is not uuid",
+		},
+		{
+			name:          "empty_filter",
+			dto:           &dto.BulkRequest{Operation: "delete", Filter: &dto.BulkFilter{}},
+			expectedError: "filter cannot be empty",
+		},
+		{
+			name:          "filter_validated",
+			dto:           &dto.BulkRequest{Operation: "delete", Filter: &dto.BulkFilter{RadiusMin: getIntPtr(10), RadiusMax: getIntPtr(5)}},
+			expectedError: "radius_min cannot be > radius_max",
+		},
+		{
+			name:          "empty_reason",
+			dto:           &dto.BulkRequest{Operation: "archive", IDs: []string{id}, Reason: getPtrStr("")},
+			expectedError: "reason cannot be empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s\n", tc.expectedError)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
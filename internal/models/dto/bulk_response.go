@@ -0,0 +1,14 @@
+package dto
+
+type BulkSkipped struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type BulkResponse struct {
+	Operation   string         `json:"operation"`
+	DryRun      bool           `json:"dry_run"`
+	Matched     int            `json:"matched"`
+	AffectedIDs []string       `json:"affected_ids"`
+	Skipped     []*BulkSkipped `json:"skipped"`
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/google/uuid"
+)
+
+type ChecksTimeSeriesQueryParams struct {
+	From       *time.Time
+	To         *time.Time
+	Bucket     string
+	IncidentID string
+	Type       string
+}
+
+func (c *ChecksTimeSeriesQueryParams) Validate() error {
+	if c.IncidentID != "" {
+		_, err := uuid.Parse(c.IncidentID)
+		if err != nil {
+			return fmt.Errorf("%s: is not uuid", c.IncidentID)
+		}
+	}
+	if c.From != nil && c.To != nil && !c.From.Before(*c.To) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	return nil
+}
+
+type ChecksTimeSeriesResponse struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Bucket     string         `json:"bucket"`
+	IncidentID string         `json:"incident_id,omitempty"`
+	Type       string         `json:"type,omitempty"`
+	Buckets    []ChecksBucket `json:"buckets"`
+}
+
+type ChecksBucket struct {
+	Start           time.Time `json:"start"`
+	Checks          int       `json:"checks"`
+	DangerousChecks int       `json:"dangerous_checks"`
+	UniqueUsers     int       `json:"unique_users"`
+}
+
+// ToChecksTimeSeriesResponse fills the buckets without checks with zeros, so the series has no gaps.
+func ToChecksTimeSeriesResponse(filter *entities.ChecksTimeSeriesFilter, bucket string, buckets []*entities.ChecksBucket) *ChecksTimeSeriesResponse {
+	res := &ChecksTimeSeriesResponse{
+		From:       filter.From,
+		To:         filter.To,
+		Bucket:     bucket,
+		IncidentID: filter.IncidentID,
+		Type:       filter.Type,
+		Buckets:    []ChecksBucket{},
+	}
+	next := 0
+	for start := filter.From; start.Before(filter.To); start = start.Add(filter.Bucket) {
+		item := ChecksBucket{Start: start}
+		if next < len(buckets) && buckets[next].Start.Equal(start) {
+			item.Checks = buckets[next].Checks
+			item.DangerousChecks = buckets[next].DangerousChecks
+			item.UniqueUsers = buckets[next].UniqueUsers
+			next++
+		}
+		res.Buckets = append(res.Buckets, item)
+	}
+	return res
+}
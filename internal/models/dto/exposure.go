@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+type ExposedUserResponse struct {
+	UserID      string    `json:"user_id"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Checks      int       `json:"checks"`
+	MinDistance float64   `json:"min_distance_meters"`
+}
+
+type ExposureResponse struct {
+	IncidentID string                 `json:"incident_id"`
+	UsersCount int                    `json:"users_count"`
+	Users      []*ExposedUserResponse `json:"users"`
+}
+
+func ToExposedUserResponse(user *entities.ExposedUser) *ExposedUserResponse {
+	return &ExposedUserResponse{
+		UserID:      user.UserID,
+		FirstSeen:   user.FirstSeen,
+		LastSeen:    user.LastSeen,
+		Checks:      user.Checks,
+		MinDistance: user.MinDistance,
+	}
+}
@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/geohash"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	HeatmapLayerAll       = "all"
+	HeatmapLayerDangerous = "dangerous"
+
+	MaxHeatmapPrecision = 8
+)
+
+var HeatmapLayers = []string{HeatmapLayerAll, HeatmapLayerDangerous}
+
+// HeatmapQueryParams holds BBox as minLon, minLat, maxLon, maxLat.
+type HeatmapQueryParams struct {
+	From      *time.Time
+	To        *time.Time
+	Precision *int
+	BBox      []float64
+	Layer     string
+}
+
+func (h *HeatmapQueryParams) Validate() error {
+	if h.Precision != nil && (*h.Precision < 1 || *h.Precision > MaxHeatmapPrecision) {
+		return fmt.Errorf("precision must be between 1 and %d", MaxHeatmapPrecision)
+	}
+	if h.BBox != nil {
+		if len(h.BBox) != 4 || h.BBox[0] < -180 || h.BBox[2] > 180 || h.BBox[1] < -90 || h.BBox[3] > 90 ||
+			h.BBox[0] > h.BBox[2] || h.BBox[1] > h.BBox[3] {
+			return fmt.Errorf("bbox must be minLon,minLat,maxLon,maxLat in degrees")
+		}
+	}
+	if h.Layer != "" && !slices.Contains(HeatmapLayers, h.Layer) {
+		return fmt.Errorf("invalid layer: must be one of %v", HeatmapLayers)
+	}
+	if h.From != nil && h.To != nil && !h.From.Before(*h.To) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	return nil
+}
+
+// HeatmapResponse is a GeoJSON FeatureCollection of geohash cells.
+type HeatmapResponse struct {
+	Type     string           `json:"type"`
+	Features []HeatmapFeature `json:"features"`
+}
+
+type HeatmapFeature struct {
+	Type       string            `json:"type"`
+	Geometry   HeatmapGeometry   `json:"geometry"`
+	Properties HeatmapProperties `json:"properties"`
+}
+
+type HeatmapGeometry struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+type HeatmapProperties struct {
+	Geohash         string `json:"geohash"`
+	Checks          int    `json:"checks"`
+	DangerousChecks int    `json:"dangerous_checks"`
+}
+
+func ToHeatmapResponse(cells []*entities.HeatmapCell) (*HeatmapResponse, error) {
+	res := &HeatmapResponse{
+		Type:     "FeatureCollection",
+		Features: []HeatmapFeature{},
+	}
+	for _, cell := range cells {
+		box, err := geohash.Bounds(cell.Geohash)
+		if err != nil {
+			return nil, err
+		}
+		res.Features = append(res.Features, HeatmapFeature{
+			Type: "Feature",
+			Geometry: HeatmapGeometry{
+				Type: "Polygon",
+				Coordinates: [][][2]float64{{
+					{box.MinLon, box.MinLat},
+					{box.MaxLon, box.MinLat},
+					{box.MaxLon, box.MaxLat},
+					{box.MinLon, box.MaxLat},
+					{box.MinLon, box.MinLat},
+				}},
+			},
+			Properties: HeatmapProperties{
+				Geohash:         cell.Geohash,
+				Checks:          cell.Checks,
+				DangerousChecks: cell.DangerousChecks,
+			},
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestHeatmapQueryParams_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dto           *dto.HeatmapQueryParams
+		expectedError string
+	}{
+		{
+			name: "valid",
+			dto:  &dto.HeatmapQueryParams{Precision: getIntPtr(7), BBox: []float64{37.3, 55.5, 37.9, 56}, Layer: dto.HeatmapLayerDangerous},
+		},
+		{
+			name:          "precision_too_high",
+			dto:           &dto.HeatmapQueryParams{Precision: getIntPtr(9)},
+			expectedError: "precision must be between 1 and 8",
+		},
+		{
+			name:          "bbox_three_numbers",
+			dto:           &dto.HeatmapQueryParams{BBox: []float64{37.3, 55.5, 37.9}},
+			expectedError: "bbox must be minLon,minLat,maxLon,maxLat in degrees",
+		},
+		{
+			name:          "bbox_reversed",
+			dto:           &dto.HeatmapQueryParams{BBox: []float64{37.9, 55.5, 37.3, 56}},
+			expectedError: "bbox must be minLon,minLat,maxLon,maxLat in degrees",
+		},
+		{
+			name:          "bbox_out_of_range",
+			dto:           &dto.HeatmapQueryParams{BBox: []float64{-190, 0, 10, 10}},
+			expectedError: "bbox must be minLon,minLat,maxLon,maxLat in degrees",
+		},
+		{
+			name:          "unknown_layer",
+			dto:           &dto.HeatmapQueryParams{Layer: "safe"},
+			expectedError: "invalid layer: must be one of [all dangerous]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s\n", tc.expectedError)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
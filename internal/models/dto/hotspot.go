@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+type HotspotResponse struct {
+	ID             string    `json:"id"`
+	Latitude       string    `json:"latitude"`
+	Longitude      string    `json:"longitude"`
+	Radius         int       `json:"radius"`
+	Checks         int       `json:"checks"`
+	UniqueUsers    int       `json:"unique_users"`
+	FirstCheckDate time.Time `json:"first_check_date"`
+	LastCheckDate  time.Time `json:"last_check_date"`
+	Status         string    `json:"status"`
+	IncidentID     *string   `json:"incident_id,omitempty"`
+	DetectedDate   time.Time `json:"detected_date"`
+	UpdatedDate    time.Time `json:"updated_date"`
+}
+
+func ToHotspotResponse(h *entities.Hotspot) *HotspotResponse {
+	return &HotspotResponse{
+		ID:             h.ID,
+		Latitude:       h.Latitude,
+		Longitude:      h.Longitude,
+		Radius:         h.Radius,
+		Checks:         h.Checks,
+		UniqueUsers:    h.UniqueUsers,
+		FirstCheckDate: h.FirstCheckDate,
+		LastCheckDate:  h.LastCheckDate,
+		Status:         h.Status,
+		IncidentID:     h.IncidentID,
+		DetectedDate:   h.DetectedDate,
+		UpdatedDate:    h.UpdatedDate,
+	}
+}
+
+// PromoteHotspotRequest overrides fields of the incident created from hotspot,
+// coordinates and radius are taken from the hotspot when not set.
+type PromoteHotspotRequest struct {
+	Name           *string `json:"name"`
+	Type           *string `json:"type"`
+	Description    *string `json:"description"`
+	RadiusInMeters *int    `json:"radius"`
+	Status         *string `json:"status"`
+}
+
+func (p *PromoteHotspotRequest) Validate() error {
+	if p.Name != nil && *p.Name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if p.Type != nil && *p.Type == "" {
+		return fmt.Errorf("type cannot be empty")
+	}
+	if p.Status != nil && *p.Status == "" {
+		return fmt.Errorf("status cannot be empty")
+	}
+	return nil
+}
+
+type PromoteHotspotResponse struct {
+	Hotspot  *HotspotResponse       `json:"hotspot"`
+	Incident *IncidentAdminResponse `json:"incident"`
+}
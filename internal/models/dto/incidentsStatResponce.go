@@ -11,21 +11,29 @@ type IncidentsStatResponse struct {
 	TimeStatWindow  int            `json:"time_stat_window"`
 	FromDate        time.Time      `json:"from_date"`
 	ToDate          time.Time      `json:"to_date"`
+	IncludeInactive bool           `json:"include_inactive"`
+	Types           []string       `json:"types,omitempty"`
 	TotalIncidents  int            `json:"total_incidents"`
 	IncidentsStat   []IncidentStat `json:"incidents_stat"`
 }
 
 type IncidentStat struct {
 	IncidentBaseResponse
-	UserCount int `json:"user_count"`
+	Status          string    `json:"status"`
+	UserCount       int       `json:"user_count"`
+	DangerousChecks int       `json:"dangerous_checks"`
+	FirstCheckDate  time.Time `json:"first_check_date"`
+	LastCheckDate   time.Time `json:"last_check_date"`
 }
 
-func ToIncidentsStatResponse(entities []*entities.IncidentStat, timeWindow int, timeRequest time.Time, totalUniqueUsers int) *IncidentsStatResponse {
+func ToIncidentsStatResponse(entities []*entities.IncidentStat, filter *entities.IncidentStatsFilter, totalUniqueUsers int) *IncidentsStatResponse {
 	res := &IncidentsStatResponse{
 		TotalUniqueUser: totalUniqueUsers,
-		TimeStatWindow:  timeWindow,
-		FromDate:        timeRequest.UTC().Add(-(time.Duration(timeWindow) * time.Minute)),
-		ToDate:          timeRequest.UTC(),
+		TimeStatWindow:  int(filter.To.Sub(filter.From).Minutes()),
+		FromDate:        filter.From.UTC(),
+		ToDate:          filter.To.UTC(),
+		IncludeInactive: filter.IncludeInactive,
+		Types:           filter.Types,
 		TotalIncidents:  len(entities),
 	}
 
@@ -38,7 +46,11 @@ func ToIncidentsStatResponse(entities []*entities.IncidentStat, timeWindow int,
 					Name: entitie.Name,
 					Type: entitie.Type,
 				},
-				UserCount: entitie.UserCount,
+				Status:          entitie.Status,
+				UserCount:       entitie.UserCount,
+				DangerousChecks: entitie.DangerousChecks,
+				FirstCheckDate:  entitie.FirstCheckDate.UTC(),
+				LastCheckDate:   entitie.LastCheckDate.UTC(),
 			})
 	}
 
@@ -2,17 +2,34 @@ package dto
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type PaginationQueryParams struct {
-	PageNum *int
-	Status  string
-	ID      *string
-	Name    string
-	Type    string
-	Radius  *int
+	PageNum       *int
+	Statuses      []string
+	ID            *string
+	Name          string
+	NameContains  string
+	Types         []string
+	Radius        *int
+	RadiusMin     *int
+	RadiusMax     *int
+	IsActive      *bool
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	UpdatedFrom   *time.Time
+	UpdatedTo     *time.Time
+	ResolvedFrom  *time.Time
+	ResolvedTo    *time.Time
+	NearLatitude  *float64
+	NearLongitude *float64
+	NearMeters    *int
+	Limit         *int
+	Cursor        string
+	Sort          string
 }
 
 func (p *PaginationQueryParams) Validate() error {
@@ -25,5 +42,55 @@ func (p *PaginationQueryParams) Validate() error {
 			return fmt.Errorf("%s: is not uuid\n", *p.ID)
 		}
 	}
+	if p.Limit != nil && *p.Limit < 1 {
+		return fmt.Errorf("limit cannot be < 1")
+	}
+	if len(p.NameContains) > 100 {
+		return fmt.Errorf("name_contains cannot be longer than 100 characters")
+	}
+	if p.RadiusMin != nil && *p.RadiusMin < 0 {
+		return fmt.Errorf("radius_min cannot be < 0")
+	}
+	if p.RadiusMax != nil && *p.RadiusMax < 0 {
+		return fmt.Errorf("radius_max cannot be < 0")
+	}
+	if p.RadiusMin != nil && p.RadiusMax != nil && *p.RadiusMin > *p.RadiusMax {
+		return fmt.Errorf("radius_min cannot be > radius_max")
+	}
+	if err := validateTimeRange("created", p.CreatedFrom, p.CreatedTo); err != nil {
+		return err
+	}
+	if err := validateTimeRange("updated", p.UpdatedFrom, p.UpdatedTo); err != nil {
+		return err
+	}
+	if err := validateTimeRange("resolved", p.ResolvedFrom, p.ResolvedTo); err != nil {
+		return err
+	}
+	return p.validateNear()
+}
+
+func (p *PaginationQueryParams) validateNear() error {
+	if p.NearLatitude == nil && p.NearLongitude == nil && p.NearMeters == nil {
+		return nil
+	}
+	if p.NearLatitude == nil || p.NearLongitude == nil || p.NearMeters == nil {
+		return fmt.Errorf("near_lat, near_lon and near_meters must be set together")
+	}
+	if *p.NearLatitude < -90 || *p.NearLatitude > 90 {
+		return fmt.Errorf("near_lat must be between -90 and 90")
+	}
+	if *p.NearLongitude < -180 || *p.NearLongitude > 180 {
+		return fmt.Errorf("near_lon must be between -180 and 180")
+	}
+	if *p.NearMeters <= 0 {
+		return fmt.Errorf("near_meters cannot be <= 0")
+	}
+	return nil
+}
+
+func validateTimeRange(name string, from, to *time.Time) error {
+	if from != nil && to != nil && from.After(*to) {
+		return fmt.Errorf("%s_from cannot be after %s_to", name, name)
+	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package dto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestPaginationQueryParams_Validate(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	lat, lon := 55.75, 37.62
+	badLat := 91.0
+
+	testCases := []struct {
+		name          string
+		dto           *dto.PaginationQueryParams
+		expectedError string
+	}{
+		{
+			name: "valid_full",
+			dto: &dto.PaginationQueryParams{
+				Statuses:      []string{"active", "monitoring"},
+				Types:         []string{"fire"},
+				RadiusMin:     getIntPtr(100),
+				RadiusMax:     getIntPtr(500),
+				CreatedFrom:   &from,
+				CreatedTo:     &to,
+				NearLatitude:  &lat,
+				NearLongitude: &lon,
+				NearMeters:    getIntPtr(1000),
+				NameContains:  "pipe",
+				Limit:         getIntPtr(5),
+			},
+		},
+		{
+			name: "valid_empty",
+			dto:  &dto.PaginationQueryParams{},
+		},
+		{
+			name:          "invalid_id",
+			dto:           &dto.PaginationQueryParams{ID: getPtrStr("123")},
+			expectedError: "123: is not uuid\n",
+		},
+		{
+			name:          "limit_zero",
+			dto:           &dto.PaginationQueryParams{Limit: getIntPtr(0)},
+			expectedError: "limit cannot be < 1",
+		},
+		{
+			name:          "radius_min_greater_than_max",
+			dto:           &dto.PaginationQueryParams{RadiusMin: getIntPtr(500), RadiusMax: getIntPtr(100)},
+			expectedError: "radius_min cannot be > radius_max",
+		},
+		{
+			name:          "negative_radius_min",
+			dto:           &dto.PaginationQueryParams{RadiusMin: getIntPtr(-1)},
+			expectedError: "radius_min cannot be < 0",
+		},
+		{
+			name:          "created_range_reversed",
+			dto:           &dto.PaginationQueryParams{CreatedFrom: &to, CreatedTo: &from},
+			expectedError: "created_from cannot be after created_to",
+		},
+		{
+			name:          "resolved_range_reversed",
+			dto:           &dto.PaginationQueryParams{ResolvedFrom: &to, ResolvedTo: &from},
+			expectedError: "resolved_from cannot be after resolved_to",
+		},
+		{
+			name:          "near_without_meters",
+			dto:           &dto.PaginationQueryParams{NearLatitude: &lat, NearLongitude: &lon},
+			expectedError: "near_lat, near_lon and near_meters must be set together",
+		},
+		{
+			name:          "near_bad_latitude",
+			dto:           &dto.PaginationQueryParams{NearLatitude: &badLat, NearLongitude: &lon, NearMeters: getIntPtr(10)},
+			expectedError: "near_lat must be between -90 and 90",
+		},
+		{
+			name:          "near_zero_meters",
+			dto:           &dto.PaginationQueryParams{NearLatitude: &lat, NearLongitude: &lon, NearMeters: getIntPtr(0)},
+			expectedError: "near_meters cannot be <= 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s\n", tc.expectedError)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
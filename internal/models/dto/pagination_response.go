@@ -6,14 +6,18 @@ type PaginationResponse struct {
 	TotalPages     int                      `json:"total_pages"`
 	PageNum        *int                     `json:"page_num,omitempty"`
 	TotalIncidents int                      `json:"total_incidents"`
+	Sort           string                   `json:"sort"`
+	NextCursor     string                   `json:"next_cursor,omitempty"`
 }
 
-func ToPaginationResponse(incidents []*IncidentAdminResponse, totalPages, totalIncidents int, pageNum *int) *PaginationResponse {
+func ToPaginationResponse(incidents []*IncidentAdminResponse, totalPages, totalIncidents int, pageNum *int, sort, nextCursor string) *PaginationResponse {
 	return &PaginationResponse{
 		Incidents:      incidents,
 		CountIncidents: len(incidents),
 		TotalPages:     totalPages,
 		TotalIncidents: totalIncidents,
 		PageNum:        pageNum,
+		Sort:           sort,
+		NextCursor:     nextCursor,
 	}
 }
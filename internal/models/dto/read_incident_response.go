@@ -29,6 +29,7 @@ type IncidentAdminResponse struct {
 	ResolvedDate *time.Time `json:"resolved_date"`
 	CreatedDate  time.Time  `json:"created_date"`
 	Status       string     `json:"status"`
+	Version      int        `json:"version"`
 }
 
 func CreateUserResponse(entittie *entities.ReadIncident, distanceMeters *float64) *IncidentUserResponse {
@@ -55,6 +56,7 @@ func CreateAdminResponse(entittie *entities.ReadIncident, distanceMeters *float6
 		CreatedDate:          entittie.CreatedDate,
 		Status:               entittie.Status,
 		Coordinates:          entittie.Coordinates,
+		Version:              entittie.Version,
 	}
 	return res
 }
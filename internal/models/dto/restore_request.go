@@ -0,0 +1,23 @@
+package dto
+
+import "fmt"
+
+type RestoreRequest struct {
+	// Status is the target status after restore, optional
+	Status *string `json:"status"`
+	Reason *string `json:"reason"`
+}
+
+func (r *RestoreRequest) Validate() error {
+	if r.Status != nil {
+		if *r.Status == "" {
+			return fmt.Errorf("status cannot be empty")
+		}
+	}
+	if r.Reason != nil {
+		if *r.Reason == "" {
+			return fmt.Errorf("reason cannot be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type SearchQueryParams struct {
+	Query string
+	PaginationQueryParams
+}
+
+func (p *SearchQueryParams) Validate() error {
+	p.Query = strings.TrimSpace(p.Query)
+	if p.Query == "" {
+		return fmt.Errorf("q cannot be empty")
+	}
+	if utf8.RuneCountInString(p.Query) > 200 {
+		return fmt.Errorf("q cannot be longer than 200 characters")
+	}
+	if p.Cursor != "" || p.Sort != "" {
+		return fmt.Errorf("cursor and sort cannot be used in search, results are ordered by rank")
+	}
+	return p.PaginationQueryParams.Validate()
+}
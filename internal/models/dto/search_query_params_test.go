@@ -0,0 +1,59 @@
+package dto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestSearchQueryParams_Validate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dto           *dto.SearchQueryParams
+		expectedError string
+	}{
+		{
+			name: "valid",
+			dto:  &dto.SearchQueryParams{Query: "  прорыв трубы ", PaginationQueryParams: dto.PaginationQueryParams{Statuses: []string{"active"}}},
+		},
+		{
+			name:          "empty_query",
+			dto:           &dto.SearchQueryParams{Query: "   "},
+			expectedError: "q cannot be empty",
+		},
+		{
+			name:          "long_query",
+			dto:           &dto.SearchQueryParams{Query: strings.Repeat("я", 201)},
+			expectedError: "q cannot be longer than 200 characters",
+		},
+		{
+			name:          "sort_not_allowed",
+			dto:           &dto.SearchQueryParams{Query: "fire", PaginationQueryParams: dto.PaginationQueryParams{Sort: "name"}},
+			expectedError: "cursor and sort cannot be used in search, results are ordered by rank",
+		},
+		{
+			name:          "filters_validated",
+			dto:           &dto.SearchQueryParams{Query: "fire", PaginationQueryParams: dto.PaginationQueryParams{Limit: getIntPtr(0)}},
+			expectedError: "limit cannot be < 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s\n", tc.expectedError)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "github.com/Piccadilly98/incidents_service/internal/models/entities"
+
+type SearchHighlightResponse struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+type SearchResultResponse struct {
+	*IncidentAdminResponse
+	Rank       float64                 `json:"rank"`
+	Highlights SearchHighlightResponse `json:"highlights"`
+}
+
+type SearchResponse struct {
+	Results      []*SearchResultResponse `json:"results"`
+	CountResults int                     `json:"results_count"`
+	TotalPages   int                     `json:"total_pages"`
+	PageNum      *int                    `json:"page_num,omitempty"`
+	TotalResults int                     `json:"total_results"`
+}
+
+func ToSearchResponse(results []*entities.SearchResult, totalPages, totalResults int, pageNum *int) *SearchResponse {
+	res := &SearchResponse{
+		Results:      []*SearchResultResponse{},
+		TotalPages:   totalPages,
+		TotalResults: totalResults,
+		PageNum:      pageNum,
+	}
+	for _, result := range results {
+		res.Results = append(res.Results, &SearchResultResponse{
+			IncidentAdminResponse: CreateAdminResponse(&result.Incident, nil),
+			Rank:                  result.Rank,
+			Highlights: SearchHighlightResponse{
+				Name:        result.NameHighlight,
+				Description: result.DescriptionHighlight,
+			},
+		})
+	}
+	res.CountResults = len(res.Results)
+	return res
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxStatsWindowMinutes is one year, rollups of a longer window are summed too slowly for a request.
+const MaxStatsWindowMinutes = 366 * 24 * 60
+
+// StatsQueryParams sets the statistics window either by From and To or by WindowMinutes ending at To,
+// without both the configured window ending now is used.
+type StatsQueryParams struct {
+	From            *time.Time
+	To              *time.Time
+	WindowMinutes   *int
+	IncludeInactive bool
+	Types           []string
+}
+
+func (s *StatsQueryParams) Validate() error {
+	if s.WindowMinutes != nil {
+		if *s.WindowMinutes < 1 {
+			return fmt.Errorf("window_minutes cannot be < 1")
+		}
+		if *s.WindowMinutes > MaxStatsWindowMinutes {
+			return fmt.Errorf("window_minutes cannot be > %d", MaxStatsWindowMinutes)
+		}
+		if s.From != nil {
+			return fmt.Errorf("window_minutes cannot be used with from")
+		}
+	}
+	if s.From != nil && s.To != nil && !s.From.Before(*s.To) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package dto_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestStatsQueryParams_Validate(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	testCases := []struct {
+		name          string
+		dto           *dto.StatsQueryParams
+		expectedError string
+	}{
+		{
+			name: "empty",
+			dto:  &dto.StatsQueryParams{},
+		},
+		{
+			name: "from_to",
+			dto:  &dto.StatsQueryParams{From: &from, To: &to, IncludeInactive: true, Types: []string{"fire"}},
+		},
+		{
+			name: "window_with_to",
+			dto:  &dto.StatsQueryParams{To: &to, WindowMinutes: getIntPtr(30)},
+		},
+		{
+			name:          "window_with_from",
+			dto:           &dto.StatsQueryParams{From: &from, WindowMinutes: getIntPtr(30)},
+			expectedError: "window_minutes cannot be used with from",
+		},
+		{
+			name:          "zero_window",
+			dto:           &dto.StatsQueryParams{WindowMinutes: getIntPtr(0)},
+			expectedError: "window_minutes cannot be < 1",
+		},
+		{
+			name: "max_window",
+			dto:  &dto.StatsQueryParams{WindowMinutes: getIntPtr(dto.MaxStatsWindowMinutes)},
+		},
+		{
+			name:          "too_long_window",
+			dto:           &dto.StatsQueryParams{WindowMinutes: getIntPtr(dto.MaxStatsWindowMinutes + 1)},
+			expectedError: "window_minutes cannot be > 527040",
+		},
+		{
+			name:          "reversed",
+			dto:           &dto.StatsQueryParams{From: &to, To: &from},
+			expectedError: "from cannot be after to",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error: %s\n", tc.expectedError)
+			}
+			if err.Error() != tc.expectedError {
+				t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
@@ -13,11 +13,15 @@ type UpdateRequest struct {
 	Description *string `json:"description"`
 	Radius      *int    `json:"radius"`
 	Status      *string `json:"status"`
+	// StatusReason is stored in status history, allowed only with status
+	StatusReason *string `json:"status_reason"`
+	// ClearDescription sets description to null, only PATCH can express it
+	ClearDescription bool `json:"-"`
 }
 
 func (u *UpdateRequest) Validate() error {
 	if !(u.Name != nil || u.Type != nil ||
-		u.Description != nil ||
+		u.Description != nil || u.ClearDescription ||
 		u.Radius != nil ||
 		u.Status != nil) {
 		return fmt.Errorf("no data for update")
@@ -36,6 +40,9 @@ func (u *UpdateRequest) Validate() error {
 		if *u.Description == "" {
 			return fmt.Errorf("description cannot be empty")
 		}
+		if u.ClearDescription {
+			return fmt.Errorf("description cannot be set and cleared at once")
+		}
 	}
 	if u.Radius != nil {
 		if *u.Radius <= 0 {
@@ -47,17 +54,26 @@ func (u *UpdateRequest) Validate() error {
 			return fmt.Errorf("status cannot be empty")
 		}
 	}
+	if u.StatusReason != nil {
+		if u.Status == nil {
+			return fmt.Errorf("status_reason cannot be set without status")
+		}
+		if *u.StatusReason == "" {
+			return fmt.Errorf("status_reason cannot be empty")
+		}
+	}
 	return nil
 }
 
 func (u *UpdateRequest) ToEntity(resolvedTime *time.Time, isActive bool) *entities.UpdateIncident {
 	return &entities.UpdateIncident{
-		Name:         u.Name,
-		Type:         u.Type,
-		ResolvedTime: resolvedTime,
-		IsActive:     isActive,
-		Description:  u.Description,
-		Radius:       u.Radius,
-		Status:       u.Status,
+		Name:             u.Name,
+		Type:             u.Type,
+		ResolvedTime:     resolvedTime,
+		IsActive:         isActive,
+		Description:      u.Description,
+		ClearDescription: u.ClearDescription,
+		Radius:           u.Radius,
+		Status:           u.Status,
 	}
 }
@@ -120,6 +120,43 @@ func TestUpdateRequest_Validate(t *testing.T) {
 			},
 			expectedError: fmt.Errorf("name cannot be empty"),
 		},
+		{
+			name: "valid_status_with_reason",
+			dto: &dto.UpdateRequest{
+				Status:       getPtrStr("resolved"),
+				StatusReason: getPtrStr("fire extinguished"),
+			},
+		},
+		{
+			name: "invalid_reason_without_status",
+			dto: &dto.UpdateRequest{
+				Name:         getPtrStr("new"),
+				StatusReason: getPtrStr("fire extinguished"),
+			},
+			expectedError: fmt.Errorf("status_reason cannot be set without status"),
+		},
+		{
+			name: "invalid_empty_reason",
+			dto: &dto.UpdateRequest{
+				Status:       getPtrStr("resolved"),
+				StatusReason: getPtrStr(""),
+			},
+			expectedError: fmt.Errorf("status_reason cannot be empty"),
+		},
+		{
+			name: "valid_only_clear_description",
+			dto: &dto.UpdateRequest{
+				ClearDescription: true,
+			},
+		},
+		{
+			name: "invalid_set_and_clear_description",
+			dto: &dto.UpdateRequest{
+				Description:      getPtrStr("text"),
+				ClearDescription: true,
+			},
+			expectedError: fmt.Errorf("description cannot be set and cleared at once"),
+		},
 	}
 
 	for _, tc := range testCases {
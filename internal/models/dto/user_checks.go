@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+// maxLenUserID matches the size of checks.user_id
+const maxLenUserID = 100
+
+type UserChecksQueryParams struct {
+	UserID        string
+	PageNum       *int
+	From          *time.Time
+	To            *time.Time
+	DangerousOnly bool
+}
+
+func (u *UserChecksQueryParams) Validate() error {
+	if err := ValidateUserID(u.UserID); err != nil {
+		return err
+	}
+	if u.From != nil && u.To != nil && u.From.After(*u.To) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	return nil
+}
+
+func ValidateUserID(userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user_id cannot be empty")
+	}
+	if utf8.RuneCountInString(userID) > maxLenUserID {
+		return fmt.Errorf("user_id cannot be longer than %d characters", maxLenUserID)
+	}
+	return nil
+}
+
+type UserCheckResponse struct {
+	ID                  string    `json:"check_id"`
+	Latitude            string    `json:"latitude"`
+	Longitude           string    `json:"longitude"`
+	IsDanger            bool      `json:"is_danger"`
+	DetectedIncidentIDs []string  `json:"detected_incident_ids"`
+	CreatedDate         time.Time `json:"created_date"`
+}
+
+type UserChecksResponse struct {
+	UserID      string               `json:"user_id"`
+	Checks      []*UserCheckResponse `json:"checks"`
+	CountChecks int                  `json:"checks_count"`
+	PageNum     *int                 `json:"page_num,omitempty"`
+}
+
+func ToUserChecksResponse(userID string, checks []*entities.CheckRecord, pageNum *int) *UserChecksResponse {
+	res := &UserChecksResponse{
+		UserID:      userID,
+		Checks:      make([]*UserCheckResponse, 0, len(checks)),
+		CountChecks: len(checks),
+		PageNum:     pageNum,
+	}
+	for _, check := range checks {
+		ids := check.DetectedIncidentIDs
+		if ids == nil {
+			ids = []string{}
+		}
+		res.Checks = append(res.Checks, &UserCheckResponse{
+			ID:                  check.ID,
+			Latitude:            check.Latitude,
+			Longitude:           check.Longitude,
+			IsDanger:            check.IsDanger,
+			DetectedIncidentIDs: ids,
+			CreatedDate:         check.CreatedDate,
+		})
+	}
+	return res
+}
+
+type ForgetUserResponse struct {
+	UserID         string `json:"user_id"`
+	Mode           string `json:"mode"`
+	ChecksAffected int64  `json:"checks_affected"`
+}
@@ -0,0 +1,47 @@
+package dto_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func TestUserChecksQueryParams_Validate(t *testing.T) {
+	now := time.Now().UTC()
+	before := now.Add(-time.Hour)
+	testCases := []struct {
+		name          string
+		dto           *dto.UserChecksQueryParams
+		expectedError error
+	}{
+		{name: "valid", dto: &dto.UserChecksQueryParams{UserID: "user-1", From: &before, To: &now, DangerousOnly: true}},
+		{name: "empty_user", dto: &dto.UserChecksQueryParams{}, expectedError: fmt.Errorf("user_id cannot be empty")},
+		{
+			name:          "long_user",
+			dto:           &dto.UserChecksQueryParams{UserID: strings.Repeat("я", 101)},
+			expectedError: fmt.Errorf("user_id cannot be longer than 100 characters"),
+		},
+		{
+			name:          "reversed_window",
+			dto:           &dto.UserChecksQueryParams{UserID: "user-1", From: &now, To: &before},
+			expectedError: fmt.Errorf("from cannot be after to"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.dto.Validate()
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err.Error())
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedError.Error() {
+				t.Errorf("error: got: %v, expect: %s", err, tc.expectedError.Error())
+			}
+		})
+	}
+}
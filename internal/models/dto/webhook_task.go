@@ -4,19 +4,22 @@ import "time"
 
 type WebhookTask struct {
 	Dto        LocationCheckResponse
+	Tenant     string `json:"tenant"`
 	CountReTry int    `json:"count_retry"`
 	Method     string `json:"method"`
 	Url        string `json:"url"`
 }
 
 type ResultWebhookRequestDTO struct {
-	Dto  LocationCheckResponse
-	Date time.Time `json:"date_request"`
+	Dto    LocationCheckResponse
+	Tenant string    `json:"tenant"`
+	Date   time.Time `json:"date_request"`
 }
 
 func (wt *WebhookTask) ToResultWebhookDto() *ResultWebhookRequestDTO {
 	return &ResultWebhookRequestDTO{
-		Dto:  wt.Dto,
-		Date: time.Now().UTC(),
+		Dto:    wt.Dto,
+		Tenant: wt.Tenant,
+		Date:   time.Now().UTC(),
 	}
 }
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// APIKey is a stored admin key, the key itself is never saved, only its sha256 hash.
+// Prefix is the public part of the key used to tell keys apart in lists and logs.
+type APIKey struct {
+	ID          string
+	Name        string
+	Prefix      string
+	KeyHash     string
+	Role        string
+	TenantID    string
+	Scopes      []string
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	CreatedDate time.Time
+	RevokedDate *time.Time
+}
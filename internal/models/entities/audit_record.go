@@ -0,0 +1,31 @@
+package entities
+
+import "time"
+
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+type AuditRecord struct {
+	ID          string
+	TenantID    string
+	IncidentID  string
+	Action      string
+	Actor       string
+	RequestID   string
+	Changes     map[string]FieldChange
+	CreatedDate time.Time
+}
+
+type AuditFilter struct {
+	TenantID   string
+	IncidentID string
+	Actor      string
+	Action     string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
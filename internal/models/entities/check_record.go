@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+type CheckRecord struct {
+	ID                  string
+	UserID              string
+	Latitude            string
+	Longitude           string
+	IsDanger            bool
+	DetectedIncidentIDs []string
+	CreatedDate         time.Time
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// ChecksPartition is a partition of the checks table, rows with From <= created_date < To.
+// The default partition has no bounds and keeps rows of periods without a partition.
+type ChecksPartition struct {
+	Name      string
+	From      time.Time
+	To        time.Time
+	IsDefault bool
+}
+
+// ChecksCoarsening is the progress of coordinate coarsening: checks created before CoarsenedBefore
+// are already rounded to Decimals places.
+type ChecksCoarsening struct {
+	CoarsenedBefore time.Time
+	Decimals        int
+}
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// ChecksTimeSeriesFilter selects checks of the tenant created in [From, To) split into Bucket long intervals
+// starting at From. With IncidentID or Type only checks inside the matching incidents are counted.
+type ChecksTimeSeriesFilter struct {
+	TenantID   string
+	From       time.Time
+	To         time.Time
+	Bucket     time.Duration
+	IncidentID string
+	Type       string
+}
+
+type ChecksBucket struct {
+	Start           time.Time
+	Checks          int
+	DangerousChecks int
+	UniqueUsers     int
+}
@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// ExposedUser is a user whose checks detected the incident. Latitude and Longitude
+// are the coordinates of the check closest to the incident center.
+type ExposedUser struct {
+	UserID      string
+	Latitude    string
+	Longitude   string
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	Checks      int
+	MinDistance float64
+}
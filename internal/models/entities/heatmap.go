@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+type BBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// HeatmapFilter groups checks of the tenant created in [From, To) by geohash cells of Precision.
+// Nil BBox means the whole world, Limit caps the number of cells.
+type HeatmapFilter struct {
+	TenantID      string
+	From          time.Time
+	To            time.Time
+	Precision     int
+	BBox          *BBox
+	DangerousOnly bool
+	Limit         int
+}
+
+type HeatmapCell struct {
+	Geohash         string
+	Checks          int
+	DangerousChecks int
+}
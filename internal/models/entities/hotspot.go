@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+type Hotspot struct {
+	ID             string
+	TenantID       string
+	Latitude       string
+	Longitude      string
+	Radius         int
+	Checks         int
+	UniqueUsers    int
+	FirstCheckDate time.Time
+	LastCheckDate  time.Time
+	Status         string
+	IncidentID     *string
+	DetectedDate   time.Time
+	UpdatedDate    time.Time
+}
+
+// HotspotParams selects clusters of checks without detected incidents created since Since:
+// checks closer than RadiusMeters are joined, a cluster needs MinChecks checks of MinUsers users.
+type HotspotParams struct {
+	Since        time.Time
+	RadiusMeters int
+	MinChecks    int
+	MinUsers     int
+}
@@ -1,8 +1,24 @@
 package entities
 
+import "time"
+
 type IncidentStat struct {
-	ID        string
-	Name      string
-	Type      string
-	UserCount int
+	ID              string
+	Name            string
+	Type            string
+	Status          string
+	UserCount       int
+	DangerousChecks int
+	FirstCheckDate  time.Time
+	LastCheckDate   time.Time
+}
+
+// IncidentStatsFilter selects dangerous checks of the tenant created in [From, To).
+// Only active incidents are counted unless IncludeInactive is set, empty Types means any type.
+type IncidentStatsFilter struct {
+	TenantID        string
+	From            time.Time
+	To              time.Time
+	IncludeInactive bool
+	Types           []string
 }
@@ -1,11 +1,53 @@
 package entities
 
+import "time"
+
+const (
+	IncidentSortCreated = "created"
+	IncidentSortUpdated = "updated"
+	IncidentSortName    = "name"
+	IncidentSortRadius  = "radius"
+	IncidentSortStatus  = "status"
+)
+
 type PaginationIncidents struct {
-	Offset int
-	Limit  int
-	Status string
-	Name   string
-	Type   string
-	Radius *int
-	ID     string
+	TenantID     string
+	Offset       int
+	Limit        int
+	Statuses     []string
+	Name         string
+	NameContains string
+	Types        []string
+	Radius       *int
+	RadiusMin    *int
+	RadiusMax    *int
+	ID           string
+	IDs          []string
+	IsActive     *bool
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	ResolvedFrom *time.Time
+	ResolvedTo   *time.Time
+	Near         *NearPoint
+	Sort         string
+	SortDesc     bool
+	After        *IncidentCursor
+	// ForUpdate locks the selected rows until the end of the transaction.
+	ForUpdate bool
+}
+
+// NearPoint limits the list to incidents located within Meters of the point.
+type NearPoint struct {
+	Latitude  float64
+	Longitude float64
+	Meters    int
+}
+
+// IncidentCursor is the keyset position of the last incident on the previous page.
+// Value holds the sort column value of that incident.
+type IncidentCursor struct {
+	Value any
+	ID    string
 }
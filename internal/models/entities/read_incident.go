@@ -16,4 +16,7 @@ type ReadIncident struct {
 	CreatedDate  time.Time
 	UpdatedDate  *time.Time
 	ResolvedDate *time.Time
+	DeletedDate  *time.Time
+	Version      int
+	TenantID     string
 }
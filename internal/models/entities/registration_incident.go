@@ -3,6 +3,7 @@ package entities
 import "time"
 
 type RegistrationIncidentEntitie struct {
+	TenantID     string
 	Name         string
 	Type         string
 	Description  *string
@@ -0,0 +1,9 @@
+package entities
+
+import "time"
+
+type RestoreIncident struct {
+	Status       string
+	IsActive     bool
+	ResolvedTime *time.Time
+}
@@ -0,0 +1,8 @@
+package entities
+
+type SearchResult struct {
+	Incident             ReadIncident
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight *string
+}
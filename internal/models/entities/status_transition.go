@@ -0,0 +1,9 @@
+package entities
+
+type StatusTransition struct {
+	IncidentID string
+	FromStatus *string
+	ToStatus   string
+	Actor      string
+	Reason     *string
+}
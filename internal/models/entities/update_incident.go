@@ -3,11 +3,13 @@ package entities
 import "time"
 
 type UpdateIncident struct {
-	Name         *string
-	Type         *string
-	Description  *string
-	Radius       *int
-	Status       *string
-	IsActive     bool
-	ResolvedTime *time.Time
+	Name        *string
+	Type        *string
+	Description *string
+	// ClearDescription sets description to NULL, ignored when Description is set
+	ClearDescription bool
+	Radius           *int
+	Status           *string
+	IsActive         bool
+	ResolvedTime     *time.Time
 }
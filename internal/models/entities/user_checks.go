@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+type UserChecksFilter struct {
+	TenantID      string
+	UserID        string
+	From          *time.Time
+	To            *time.Time
+	DangerousOnly bool
+	Limit         int
+	Offset        int
+}
+
+// UserAnonymization replaces UserID of the tenant. Every check gets CheckPrefix with its own random
+// suffix and coordinates rounded to Decimals, so checks of the user cannot be joined into a trail.
+// Rollups get the single Pseudonym to keep counting the user once.
+type UserAnonymization struct {
+	TenantID    string
+	UserID      string
+	Pseudonym   string
+	CheckPrefix string
+	Decimals    int
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository/ratelimit"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -33,8 +35,13 @@ func NewRedisCache(client *redis.Client, ctx context.Context, ttlInSecond int) (
 	}, nil
 }
 
+// activeIncidentKey puts the tenant into the key, so an id of another tenant never hits the cache.
+func activeIncidentKey(tenantID, id string) string {
+	return ActiveIncidentPrefix + tenantID + ":" + id
+}
+
 func (rc *RedisCache) SetActiveIncident(ctx context.Context, data *entities.ReadIncident) error {
-	key := ActiveIncidentPrefix + data.Id
+	key := activeIncidentKey(data.TenantID, data.Id)
 
 	b, err := json.Marshal(data)
 	if err != nil {
@@ -44,8 +51,8 @@ func (rc *RedisCache) SetActiveIncident(ctx context.Context, data *entities.Read
 	return rc.client.Set(ctx, key, b, rc.ttlInSecond).Err()
 }
 
-func (rc *RedisCache) GetActiveIncident(ctx context.Context, id string) (*entities.ReadIncident, error) {
-	key := ActiveIncidentPrefix + id
+func (rc *RedisCache) GetActiveIncident(ctx context.Context, tenantID, id string) (*entities.ReadIncident, error) {
+	key := activeIncidentKey(tenantID, id)
 
 	data, err := rc.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
@@ -63,8 +70,8 @@ func (rc *RedisCache) GetActiveIncident(ctx context.Context, id string) (*entiti
 	return inc, nil
 }
 
-func (rc *RedisCache) DeleteActiveIncident(ctx context.Context, id string) error {
-	key := ActiveIncidentPrefix + id
+func (rc *RedisCache) DeleteActiveIncident(ctx context.Context, tenantID, id string) error {
+	key := activeIncidentKey(tenantID, id)
 
 	err := rc.client.Del(ctx, key).Err()
 	if err != nil {
@@ -74,6 +81,39 @@ func (rc *RedisCache) DeleteActiveIncident(ctx context.Context, id string) error
 	return nil
 }
 
+// DeleteUserState removes rate limit buckets of the user on every route,
+// the keys are found with SCAN so that a large keyspace does not block Redis.
+func (rc *RedisCache) DeleteUserState(ctx context.Context, tenantID, userID string) error {
+	pattern := ratelimit.KeyPrefix + "*:user:" + escapePattern(tenantID) + ":" + escapePattern(userID)
+	iter := rc.client.Scan(ctx, 0, pattern, 1000).Iterator()
+	keys := []string{}
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("redis SCAN failed: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := rc.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis DEL failed: %w", err)
+	}
+	return nil
+}
+
+// escapePattern makes glob characters of user input match literally.
+func escapePattern(str string) string {
+	var b strings.Builder
+	for _, r := range str {
+		if strings.ContainsRune(`*?[]^\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 func (rc *RedisCache) PingWithCtx(ctx context.Context) error {
 	return rc.client.Ping(ctx).Err()
 }
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = "id, name, prefix, key_hash, role, tenant_id, scopes, expires_at, last_used_at, created_date, revoked_date"
+
+func (pr *PostgresRepository) RegistrationAPIKey(ctx context.Context, entit *entities.APIKey, exec repository.Executor) (*entities.APIKey, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	row := exec.QueryRowContext(ctx, `
+	INSERT INTO api_keys(name, prefix, key_hash, role, tenant_id, scopes, expires_at)
+	VALUES($1,$2,$3,$4,$5,$6,$7)
+	RETURNING `+apiKeyColumns+`;`,
+		entit.Name,
+		entit.Prefix,
+		entit.KeyHash,
+		entit.Role,
+		entit.TenantID,
+		pq.Array(entit.Scopes),
+		entit.ExpiresAt,
+	)
+	return scanAPIKey(row)
+}
+
+func (pr *PostgresRepository) GetAPIKeyByHash(ctx context.Context, keyHash string, exec repository.Executor) (*entities.APIKey, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	row := exec.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash=$1;`, keyHash)
+	return scanAPIKey(row)
+}
+
+// GetAPIKeyByIDForUpdate locks the key so that concurrent rotations do not issue two successors.
+func (pr *PostgresRepository) GetAPIKeyByIDForUpdate(ctx context.Context, id string, exec repository.Executor) (*entities.APIKey, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	row := exec.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id=$1 FOR UPDATE;`, id)
+	return scanAPIKey(row)
+}
+
+func (pr *PostgresRepository) GetAPIKeys(ctx context.Context, tenantID string, exec repository.Executor) ([]*entities.APIKey, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id=$1 ORDER BY created_date DESC, id;`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*entities.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (pr *PostgresRepository) UpdateAPIKeyExpiry(ctx context.Context, id string, expiresAt *time.Time, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `UPDATE api_keys SET expires_at=$1 WHERE id=$2;`, expiresAt, id)
+	return err
+}
+
+func (pr *PostgresRepository) RevokeAPIKey(ctx context.Context, id string, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `UPDATE api_keys SET revoked_date=NOW() WHERE id=$1 AND revoked_date IS NULL;`, id)
+	return err
+}
+
+func (pr *PostgresRepository) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `UPDATE api_keys SET last_used_at=$1 WHERE id=$2;`, usedAt, id)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (*entities.APIKey, error) {
+	res := &entities.APIKey{}
+	err := row.Scan(
+		&res.ID,
+		&res.Name,
+		&res.Prefix,
+		&res.KeyHash,
+		&res.Role,
+		&res.TenantID,
+		pq.Array(&res.Scopes),
+		&res.ExpiresAt,
+		&res.LastUsedAt,
+		&res.CreatedDate,
+		&res.RevokedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
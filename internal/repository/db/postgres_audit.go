@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+func (pr *PostgresRepository) RegistrationAuditRecord(ctx context.Context, entit *entities.AuditRecord, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	changes, err := json.Marshal(entit.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.ExecContext(ctx, `
+	INSERT INTO incident_audit(incident_id, action, actor, request_id, changes, tenant_id)
+	VALUES($1,$2,$3,NULLIF($4, ''),$5,$6);`,
+		entit.IncidentID,
+		entit.Action,
+		entit.Actor,
+		entit.RequestID,
+		changes,
+		entit.TenantID,
+	)
+	return err
+}
+
+func (pr *PostgresRepository) GetAuditRecords(ctx context.Context, filter *entities.AuditFilter, exec repository.Executor) ([]*entities.AuditRecord, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForAudit(filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.AuditRecord{}
+	for rows.Next() {
+		res := &entities.AuditRecord{}
+		var changes []byte
+		err := rows.Scan(
+			&res.ID,
+			&res.TenantID,
+			&res.IncidentID,
+			&res.Action,
+			&res.Actor,
+			&res.RequestID,
+			&changes,
+			&res.CreatedDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &res.Changes); err != nil {
+			return nil, fmt.Errorf("unmarshal audit changes failed: %w", err)
+		}
+		result = append(result, res)
+	}
+	return result, rows.Err()
+}
+
+func (pr *PostgresRepository) getQueryAndArgsForAudit(filter *entities.AuditFilter) (string, []any) {
+	args := []any{}
+	conditions := []string{}
+
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		conditions = append(conditions, fmt.Sprintf("tenant_id=$%d", len(args)))
+	}
+	if filter.IncidentID != "" {
+		args = append(args, filter.IncidentID)
+		conditions = append(conditions, fmt.Sprintf("incident_id=$%d", len(args)))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor=$%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action=$%d", len(args)))
+	}
+	if filter.RequestID != "" {
+		args = append(args, filter.RequestID)
+		conditions = append(conditions, fmt.Sprintf("request_id=$%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_date >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_date <= $%d", len(args)))
+	}
+
+	query := "SELECT id, tenant_id, incident_id, action, actor, COALESCE(request_id, ''), changes, created_date FROM incident_audit"
+	for i, condition := range conditions {
+		if i == 0 {
+			query += " WHERE " + condition
+		} else {
+			query += " AND " + condition
+		}
+	}
+	query += " ORDER BY created_date, id"
+	if filter.Limit != 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset != 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	query += ";"
+	return query, args
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+func TestGetQueryAndArgsForAudit(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	selectPart := "SELECT id, tenant_id, incident_id, action, actor, COALESCE(request_id, ''), changes, created_date FROM incident_audit"
+	testCases := []struct {
+		name      string
+		input     *entities.AuditFilter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "no_filters",
+			input:     &entities.AuditFilter{},
+			wantQuery: selectPart + " ORDER BY created_date, id;",
+			wantArgs:  []any{},
+		},
+		{
+			name: "incident_with_page",
+			input: &entities.AuditFilter{
+				IncidentID: "uuid",
+				Limit:      10,
+				Offset:     20,
+			},
+			wantQuery: selectPart + " WHERE incident_id=$1 ORDER BY created_date, id LIMIT $2 OFFSET $3;",
+			wantArgs:  []any{"uuid", 10, 20},
+		},
+		{
+			name: "all_filters",
+			input: &entities.AuditFilter{
+				TenantID:   "moscow",
+				IncidentID: "uuid",
+				Actor:      "api-key",
+				Action:     "update",
+				RequestID:  "req",
+				From:       &from,
+				To:         &to,
+				Limit:      10,
+			},
+			wantQuery: selectPart + " WHERE tenant_id=$1 AND incident_id=$2 AND actor=$3 AND action=$4 AND request_id=$5" +
+				" AND created_date >= $6 AND created_date <= $7 ORDER BY created_date, id LIMIT $8;",
+			wantArgs: []any{"moscow", "uuid", "api-key", "update", "req", from, to, 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForAudit(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/lib/pq"
+)
+
+// ExportIncidents passes incidents to fn one by one while the rows are read,
+// Limit and Offset of entit are ignored. The value passed to fn is reused for the next row.
+func (pr *PostgresRepository) ExportIncidents(ctx context.Context, entit *entities.PaginationIncidents, fn func(*entities.ReadIncident) error, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	filter := *entit
+	filter.Limit, filter.Offset, filter.After = 0, 0, nil
+	query, args := pr.getQueryAndArgsForPagination(&filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	res := &entities.ReadIncident{}
+	for rows.Next() {
+		err := rows.Scan(&res.Id,
+			&res.Name,
+			&res.Type,
+			&res.Latitude,
+			&res.Longitude,
+			&res.Coordinates,
+			&res.Description,
+			&res.Radius,
+			&res.IsActive,
+			&res.Status,
+			&res.CreatedDate,
+			&res.UpdatedDate,
+			&res.ResolvedDate,
+			&res.Version,
+			&res.TenantID,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportIncidentExposure passes users whose checks detected the incident to fn ordered by the first check,
+// the distance is measured to the current incident center. The value passed to fn is reused for the next row.
+func (pr *PostgresRepository) ExportIncidentExposure(ctx context.Context, tenantID, incidentID string, fn func(*entities.ExposedUser) error, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `
+	WITH exposed AS (
+		SELECT c.user_id, c.latitude, c.longitude, c.created_date,
+		ST_Distance(c.coordinates, i.coordinates) AS distance
+		FROM checks c JOIN incidents i ON i.id = $2
+		WHERE c.tenant_id = $1 AND c.is_danger = true AND c.detected_incident_ids @> ARRAY[$2::uuid]
+	)
+	SELECT user_id,
+	(ARRAY_AGG(latitude::text ORDER BY distance))[1], (ARRAY_AGG(longitude::text ORDER BY distance))[1],
+	MIN(created_date), MAX(created_date), COUNT(*), ROUND(MIN(distance)::numeric, 2)::float8
+	FROM exposed
+	GROUP BY user_id
+	ORDER BY MIN(created_date), user_id;`, tenantID, incidentID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	res := &entities.ExposedUser{}
+	for rows.Next() {
+		err := rows.Scan(
+			&res.UserID,
+			&res.Latitude,
+			&res.Longitude,
+			&res.FirstSeen,
+			&res.LastSeen,
+			&res.Checks,
+			&res.MinDistance,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportChecks passes checks of the tenant created in [from, to) to fn ordered by creation time,
+// the value passed to fn is reused for the next row.
+func (pr *PostgresRepository) ExportChecks(ctx context.Context, tenantID string, from, to time.Time, fn func(*entities.CheckRecord) error, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `
+	SELECT id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date
+	FROM checks
+	WHERE tenant_id = $1 AND created_date >= $2 AND created_date < $3
+	ORDER BY created_date, id;`, tenantID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	res := &entities.CheckRecord{}
+	for rows.Next() {
+		err := rows.Scan(
+			&res.ID,
+			&res.UserID,
+			&res.Latitude,
+			&res.Longitude,
+			&res.IsDanger,
+			pq.Array(&res.DetectedIncidentIDs),
+			&res.CreatedDate,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+const hotspotColumns = `id, tenant_id, latitude, longitude, radius, checks, unique_users, first_check_date, last_check_date,
+	status, incident_id, detected_date, updated_date`
+
+// FindCheckClusters runs DBSCAN over the checks of every tenant. Points are projected to Web Mercator
+// and all points of a tenant are scaled by one factor, the cosine of the tenant's average latitude,
+// so the radius is in meters around that latitude. A per point factor would scale each point
+// about the origin differently and break distances between them.
+func (pr *PostgresRepository) FindCheckClusters(ctx context.Context, params *entities.HotspotParams, exec repository.Executor) ([]*entities.Hotspot, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `
+	WITH projected AS (
+		SELECT tenant_id, user_id, coordinates, created_date,
+		ST_Transform(coordinates::geometry, 3857) AS point,
+		cos(radians(AVG(latitude) OVER (PARTITION BY tenant_id)))::float8 AS scale
+		FROM checks
+		WHERE created_date >= $1 AND is_danger = false
+	), recent AS (
+		SELECT tenant_id, user_id, coordinates, created_date,
+		ST_ClusterDBSCAN(ST_Scale(point, scale, scale), $2, $3) OVER (PARTITION BY tenant_id) AS cluster
+		FROM projected
+	), clusters AS (
+		SELECT tenant_id, cluster, ST_Centroid(ST_Collect(coordinates::geometry)) AS center,
+		COUNT(*) AS checks, COUNT(DISTINCT user_id) AS unique_users,
+		MIN(created_date) AS first_check_date, MAX(created_date) AS last_check_date
+		FROM recent
+		WHERE cluster IS NOT NULL
+		GROUP BY tenant_id, cluster
+		HAVING COUNT(DISTINCT user_id) >= $4
+	)
+	SELECT c.tenant_id, ROUND(ST_Y(c.center)::numeric, 8)::text, ROUND(ST_X(c.center)::numeric, 8)::text,
+	GREATEST(CEIL(MAX(ST_Distance(r.coordinates, c.center::geography))), 1)::int,
+	c.checks, c.unique_users, c.first_check_date, c.last_check_date
+	FROM clusters c JOIN recent r ON r.tenant_id = c.tenant_id AND r.cluster = c.cluster
+	GROUP BY c.tenant_id, c.cluster, c.center, c.checks, c.unique_users, c.first_check_date, c.last_check_date
+	ORDER BY c.tenant_id, c.unique_users DESC;`,
+		params.Since, params.RadiusMeters, params.MinChecks, params.MinUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.Hotspot{}
+	for rows.Next() {
+		res := &entities.Hotspot{}
+		err := rows.Scan(
+			&res.TenantID,
+			&res.Latitude,
+			&res.Longitude,
+			&res.Radius,
+			&res.Checks,
+			&res.UniqueUsers,
+			&res.FirstCheckDate,
+			&res.LastCheckDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+	return result, rows.Err()
+}
+
+// GetNearbyHotspotForUpdate returns the closest hotspot of the tenant seen since seenSince whose area
+// is closer than distance to the point, sql.ErrNoRows when there is none.
+func (pr *PostgresRepository) GetNearbyHotspotForUpdate(ctx context.Context, tenantID, latitude, longitude string, distance int, seenSince time.Time, exec repository.Executor) (*entities.Hotspot, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	row := exec.QueryRowContext(ctx, `
+	SELECT `+hotspotColumns+` FROM hotspots
+	WHERE tenant_id = $1 AND last_check_date >= $5
+	AND ST_DWithin(coordinates, ST_SetSRID(ST_MakePoint($3, $2), 4326)::geography, radius + $4)
+	ORDER BY ST_Distance(coordinates, ST_SetSRID(ST_MakePoint($3, $2), 4326)::geography)
+	LIMIT 1
+	FOR UPDATE;`, tenantID, latitude, longitude, distance, seenSince)
+	return scanHotspot(row)
+}
+
+func (pr *PostgresRepository) GetHotspotByIDForUpdate(ctx context.Context, id string, exec repository.Executor) (*entities.Hotspot, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	row := exec.QueryRowContext(ctx, `SELECT `+hotspotColumns+` FROM hotspots WHERE id = $1 FOR UPDATE;`, id)
+	return scanHotspot(row)
+}
+
+func (pr *PostgresRepository) RegistrationHotspot(ctx context.Context, entit *entities.Hotspot, exec repository.Executor) (string, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	var id string
+	err := exec.QueryRowContext(ctx, `
+	INSERT INTO hotspots(tenant_id, latitude, longitude, radius, checks, unique_users, first_check_date, last_check_date)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8)
+	RETURNING id;`,
+		entit.TenantID,
+		entit.Latitude,
+		entit.Longitude,
+		entit.Radius,
+		entit.Checks,
+		entit.UniqueUsers,
+		entit.FirstCheckDate,
+		entit.LastCheckDate,
+	).Scan(&id)
+	return id, err
+}
+
+// UpdateHotspotCluster moves the hotspot to the new cluster of checks, its status is kept.
+func (pr *PostgresRepository) UpdateHotspotCluster(ctx context.Context, id string, entit *entities.Hotspot, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `
+	UPDATE hotspots
+	SET latitude = $2, longitude = $3, radius = $4, checks = $5, unique_users = $6,
+	first_check_date = LEAST(first_check_date, $7), last_check_date = GREATEST(last_check_date, $8), updated_date = NOW()
+	WHERE id = $1;`,
+		id,
+		entit.Latitude,
+		entit.Longitude,
+		entit.Radius,
+		entit.Checks,
+		entit.UniqueUsers,
+		entit.FirstCheckDate,
+		entit.LastCheckDate,
+	)
+	return err
+}
+
+func (pr *PostgresRepository) UpdateHotspotStatus(ctx context.Context, id, status string, incidentID *string, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `
+	UPDATE hotspots SET status = $2, incident_id = $3, updated_date = NOW()
+	WHERE id = $1;`, id, status, incidentID)
+	return err
+}
+
+// GetHotspots returns hotspots of the tenant with the status, the ones with more users first.
+func (pr *PostgresRepository) GetHotspots(ctx context.Context, tenantID, status string, exec repository.Executor) ([]*entities.Hotspot, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `
+	SELECT `+hotspotColumns+` FROM hotspots
+	WHERE tenant_id = $1 AND status = $2
+	ORDER BY unique_users DESC, last_check_date DESC;`, tenantID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.Hotspot{}
+	for rows.Next() {
+		res, err := scanHotspot(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+	return result, rows.Err()
+}
+
+func scanHotspot(row scanner) (*entities.Hotspot, error) {
+	res := &entities.Hotspot{}
+	err := row.Scan(
+		&res.ID,
+		&res.TenantID,
+		&res.Latitude,
+		&res.Longitude,
+		&res.Radius,
+		&res.Checks,
+		&res.UniqueUsers,
+		&res.FirstCheckDate,
+		&res.LastCheckDate,
+		&res.Status,
+		&res.IncidentID,
+		&res.DetectedDate,
+		&res.UpdatedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
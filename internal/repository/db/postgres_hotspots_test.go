@@ -0,0 +1,51 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+// webMercator is EPSG:3857 as used by ST_Transform(geom, 3857).
+func webMercator(lat, lon float64) (float64, float64) {
+	const r = 6378137
+	return r * lon * math.Pi / 180, r * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+}
+
+// TestHotspotProjectionScale checks the projection of FindCheckClusters: points 100 meters apart
+// must stay about 100 units apart after Web Mercator and scaling by the cosine of the tenant latitude.
+func TestHotspotProjectionScale(t *testing.T) {
+	const (
+		spacing      = 100.0
+		earthRadius  = 6371008.8
+		maxDeviation = 1.0
+	)
+	testCases := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{name: "greenwich", lat: 55.75, lon: 0},
+		{name: "moscow", lat: 55.75, lon: 37.62},
+		{name: "sydney", lat: -33.87, lon: 151.21},
+		{name: "equator", lat: 0.5, lon: -78.5},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dLat := spacing / earthRadius * 180 / math.Pi
+			dLon := dLat / math.Cos(tc.lat*math.Pi/180)
+			scale := math.Cos(tc.lat * math.Pi / 180)
+
+			x, y := webMercator(tc.lat, tc.lon)
+			northX, northY := webMercator(tc.lat+dLat, tc.lon)
+			eastX, eastY := webMercator(tc.lat, tc.lon+dLon)
+			north := math.Hypot(northX-x, northY-y) * scale
+			east := math.Hypot(eastX-x, eastY-y) * scale
+
+			if math.Abs(north-spacing) > maxDeviation {
+				t.Errorf("north-south: got: %.2f, expect: %.0f\n", north, spacing)
+			}
+			if math.Abs(east-spacing) > maxDeviation {
+				t.Errorf("east-west: got: %.2f, expect: %.0f\n", east, spacing)
+			}
+		})
+	}
+}
@@ -3,13 +3,15 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
 	"github.com/Piccadilly98/incidents_service/internal/repository"
 	"github.com/lib/pq"
 )
 
-// FOR UPDATE !!
+const incidentColumns = "id, name, type, latitude, longitude, coordinates, description, radius, is_active, status, created_date, updated_date, resolved_date, version, tenant_id"
 
 func (pr *PostgresRepository) RegistrationIncident(ctx context.Context, entit *entities.RegistrationIncidentEntitie, exec repository.Executor) (string, error) {
 	var id string
@@ -17,8 +19,8 @@ func (pr *PostgresRepository) RegistrationIncident(ctx context.Context, entit *e
 		exec = pr.db
 	}
 	err := exec.QueryRowContext(ctx, `
-	INSERT INTO incidents(name, type, description, latitude,longitude, radius, is_active, status, resolved_date)
-	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	INSERT INTO incidents(name, type, description, latitude,longitude, radius, is_active, status, resolved_date, tenant_id)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 	RETURNING id;
 	`,
 		entit.Name,
@@ -30,6 +32,7 @@ func (pr *PostgresRepository) RegistrationIncident(ctx context.Context, entit *e
 		entit.IsActive,
 		entit.Status,
 		entit.ResolvedTime,
+		entit.TenantID,
 	).Scan(&id)
 	if err != nil {
 		return "", err
@@ -38,13 +41,28 @@ func (pr *PostgresRepository) RegistrationIncident(ctx context.Context, entit *e
 }
 
 func (pr *PostgresRepository) GetInfoByIncidentID(ctx context.Context, id string, exec repository.Executor) (*entities.ReadIncident, error) {
+	return pr.getInfoByIncidentID(ctx, id, "", exec)
+}
+
+// GetInfoByIncidentIDForUpdate locks the row until the end of transaction,
+// so version check and update cannot interleave with another writer.
+func (pr *PostgresRepository) GetInfoByIncidentIDForUpdate(ctx context.Context, id string, exec repository.Executor) (*entities.ReadIncident, error) {
+	return pr.getInfoByIncidentID(ctx, id, " FOR UPDATE", exec)
+}
+
+// GetInfoByIncidentIDForShare keeps writers of the row waiting until the end of transaction,
+// readers are not blocked by each other.
+func (pr *PostgresRepository) GetInfoByIncidentIDForShare(ctx context.Context, id string, exec repository.Executor) (*entities.ReadIncident, error) {
+	return pr.getInfoByIncidentID(ctx, id, " FOR SHARE", exec)
+}
+
+func (pr *PostgresRepository) getInfoByIncidentID(ctx context.Context, id string, lock string, exec repository.Executor) (*entities.ReadIncident, error) {
 	if exec == nil {
 		exec = pr.db
 	}
+	query := fmt.Sprintf("SELECT %s FROM incidents WHERE id = $1 AND deleted_date IS NULL", incidentColumns) + lock
 	res := &entities.ReadIncident{}
-	err := exec.QueryRowContext(ctx, `
-	SELECT id, name, type, latitude, longitude, coordinates, description, radius, is_active, status, created_date, updated_date, resolved_date FROM incidents
-	WHERE id = $1;`, id).Scan(&res.Id, &res.Name, &res.Type, &res.Latitude, &res.Longitude, &res.Coordinates, &res.Description, &res.Radius, &res.IsActive, &res.Status, &res.CreatedDate, &res.UpdatedDate, &res.ResolvedDate)
+	err := exec.QueryRowContext(ctx, query+";", id).Scan(&res.Id, &res.Name, &res.Type, &res.Latitude, &res.Longitude, &res.Coordinates, &res.Description, &res.Radius, &res.IsActive, &res.Status, &res.CreatedDate, &res.UpdatedDate, &res.ResolvedDate, &res.Version, &res.TenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +77,7 @@ func (pr *PostgresRepository) GetExistByIncidentID(ctx context.Context, id strin
 	var exists bool
 
 	err := exec.QueryRowContext(ctx,
-		`SELECT EXISTS (SELECT 1 FROM incidents WHERE id = $1)`,
+		`SELECT EXISTS (SELECT 1 FROM incidents WHERE id = $1 AND deleted_date IS NULL)`,
 		id).Scan(&exists)
 	if err != nil {
 		return false, err
@@ -67,6 +85,23 @@ func (pr *PostgresRepository) GetExistByIncidentID(ctx context.Context, id strin
 	return exists, nil
 }
 
+// GetExistByIncidentName also looks at incidents in trash, the name stays unique inside the tenant until they are purged.
+func (pr *PostgresRepository) GetExistByIncidentName(ctx context.Context, tenantID, name string, exec repository.Executor) (bool, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+
+	var exists bool
+
+	err := exec.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM incidents WHERE tenant_id = $1 AND name = $2)`,
+		tenantID, name).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
 func (pr *PostgresRepository) UpdateIncidentByID(ctx context.Context, id string, entit *entities.UpdateIncident, exec repository.Executor) (*entities.ReadIncident, error) {
 	if exec == nil {
 		exec = pr.db
@@ -87,7 +122,9 @@ func (pr *PostgresRepository) UpdateIncidentByID(ctx context.Context, id string,
 		&res.Status,
 		&res.CreatedDate,
 		&res.UpdatedDate,
-		&res.ResolvedDate)
+		&res.ResolvedDate,
+		&res.Version,
+		&res.TenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -170,14 +207,19 @@ func (pr *PostgresRepository) getQueryAndArgsForUpdate(entit *entities.UpdateInc
 		args = append(args, entit.ResolvedTime)
 	}
 
+	// is_active and resolved_date are always set above, so NULL goes after them
+	if entit.Description == nil && entit.ClearDescription {
+		query += ", description=NULL"
+	}
+
 	if indexArg == 1 {
-		query += "SET updated_date=NOW()"
+		query += "SET updated_date=NOW(), version=version+1"
 
 	} else {
-		query += ", updated_date=NOW()"
+		query += ", updated_date=NOW(), version=version+1"
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, type, latitude, longitude, coordinates, description, radius, is_active, status, created_date, updated_date, resolved_date", indexArg)
+	query += fmt.Sprintf(" WHERE id = $%d AND deleted_date IS NULL RETURNING %s", indexArg, incidentColumns)
 	args = append(args, id)
 	return query, args
 }
@@ -187,16 +229,91 @@ func (pr *PostgresRepository) DeleteIncidentByID(ctx context.Context, id string,
 		exec = pr.db
 	}
 
-	_, err := exec.ExecContext(ctx, `DELETE FROM incidents WHERE id = $1;`, id)
+	_, err := exec.ExecContext(ctx, `
+	UPDATE incidents SET deleted_date=NOW(), is_active=false, updated_date=NOW(), version=version+1
+	WHERE id = $1 AND deleted_date IS NULL;`, id)
+	return err
+}
+
+func (pr *PostgresRepository) GetDeletedInfoByIncidentID(ctx context.Context, id string, exec repository.Executor) (*entities.ReadIncident, error) {
+	return pr.getDeletedInfoByIncidentID(ctx, id, "", exec)
+}
+
+// GetDeletedInfoByIncidentIDForUpdate locks the deleted row until the end of transaction, like GetInfoByIncidentIDForUpdate.
+func (pr *PostgresRepository) GetDeletedInfoByIncidentIDForUpdate(ctx context.Context, id string, exec repository.Executor) (*entities.ReadIncident, error) {
+	return pr.getDeletedInfoByIncidentID(ctx, id, " FOR UPDATE", exec)
+}
+
+func (pr *PostgresRepository) getDeletedInfoByIncidentID(ctx context.Context, id string, lock string, exec repository.Executor) (*entities.ReadIncident, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	res := &entities.ReadIncident{}
+	err := exec.QueryRowContext(ctx, `
+	SELECT `+incidentColumns+`, deleted_date FROM incidents
+	WHERE id = $1 AND deleted_date IS NOT NULL`+lock+`;`, id).Scan(&res.Id, &res.Name, &res.Type, &res.Latitude, &res.Longitude, &res.Coordinates, &res.Description, &res.Radius, &res.IsActive, &res.Status, &res.CreatedDate, &res.UpdatedDate, &res.ResolvedDate, &res.Version, &res.TenantID, &res.DeletedDate)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (pr *PostgresRepository) RestoreIncidentByID(ctx context.Context, id string, entit *entities.RestoreIncident, exec repository.Executor) (*entities.ReadIncident, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	res := &entities.ReadIncident{}
+	err := exec.QueryRowContext(ctx, `
+	UPDATE incidents SET deleted_date=NULL, status=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1
+	WHERE id = $4
+	RETURNING `+incidentColumns+`;`,
+		entit.Status,
+		entit.IsActive,
+		entit.ResolvedTime,
+		id,
+	).Scan(&res.Id, &res.Name, &res.Type, &res.Latitude, &res.Longitude, &res.Coordinates, &res.Description, &res.Radius, &res.IsActive, &res.Status, &res.CreatedDate, &res.UpdatedDate, &res.ResolvedDate, &res.Version, &res.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (pr *PostgresRepository) PurgeDeletedIncidents(ctx context.Context, deletedBefore time.Time, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	result, err := exec.ExecContext(ctx, `
+	DELETE FROM incidents WHERE deleted_date IS NOT NULL AND deleted_date < $1;`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (pr *PostgresRepository) RegistrationStatusTransition(ctx context.Context, entit *entities.StatusTransition, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+
+	_, err := exec.ExecContext(ctx, `
+	INSERT INTO incident_status_transitions(incident_id, from_status, to_status, actor, reason)
+	VALUES($1,$2,$3,$4,$5);`,
+		entit.IncidentID,
+		entit.FromStatus,
+		entit.ToStatus,
+		entit.Actor,
+		entit.Reason,
+	)
 	return err
 }
 
-func (pr *PostgresRepository) GetCountRows(ctx context.Context, exec repository.Executor) (int, error) {
+func (pr *PostgresRepository) GetCountRows(ctx context.Context, entit *entities.PaginationIncidents, exec repository.Executor) (int, error) {
 	if exec == nil {
 		exec = pr.db
 	}
 	var result int
-	err := exec.QueryRowContext(ctx, `SELECT COUNT(*) FROM incidents;`).Scan(&result)
+	query, args := pr.getQueryAndArgsForCount(entit)
+	err := exec.QueryRowContext(ctx, query, args...).Scan(&result)
 	if err != nil {
 		return 0, err
 	}
@@ -214,6 +331,7 @@ func (pr *PostgresRepository) GetPaginationIncidentsInfo(ctx context.Context, en
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		res := &entities.ReadIncident{}
@@ -231,6 +349,8 @@ func (pr *PostgresRepository) GetPaginationIncidentsInfo(ctx context.Context, en
 			&res.CreatedDate,
 			&res.UpdatedDate,
 			&res.ResolvedDate,
+			&res.Version,
+			&res.TenantID,
 		)
 
 		if err != nil {
@@ -242,95 +362,154 @@ func (pr *PostgresRepository) GetPaginationIncidentsInfo(ctx context.Context, en
 	return incidents, err
 }
 
+var incidentSortColumns = map[string]string{
+	entities.IncidentSortCreated: "created_date",
+	entities.IncidentSortUpdated: "COALESCE(updated_date, created_date)",
+	entities.IncidentSortName:    "name",
+	entities.IncidentSortRadius:  "radius",
+	entities.IncidentSortStatus:  "status",
+}
+
+func (pr *PostgresRepository) getQueryAndArgsForCount(entit *entities.PaginationIncidents) (string, []any) {
+	args := []any{}
+	query := "SELECT COUNT(*) FROM incidents WHERE deleted_date IS NULL"
+	query += pr.getIncidentsFilterConditions(entit, &args)
+	query += ";"
+	return query, args
+}
+
 func (pr *PostgresRepository) getQueryAndArgsForPagination(entit *entities.PaginationIncidents) (string, []any) {
 	args := []any{}
-	indexArg := 1
 
-	query := "SELECT * FROM incidents"
-	if entit.ID != "" {
-		query += fmt.Sprintf(" WHERE id=$%d", indexArg)
-		args = append(args, entit.ID)
-		indexArg++
-	}
-	if entit.Status != "" {
-		if indexArg == 1 {
-			query += fmt.Sprintf(" WHERE status=$%d", indexArg)
-			args = append(args, entit.Status)
-			indexArg++
-		} else {
-			query += fmt.Sprintf(" AND status=$%d", indexArg)
-			args = append(args, entit.Status)
-			indexArg++
-		}
-	}
+	query := fmt.Sprintf("SELECT %s FROM incidents WHERE deleted_date IS NULL", incidentColumns)
+	query += pr.getIncidentsFilterConditions(entit, &args)
 
-	if entit.Type != "" {
-		if indexArg == 1 {
-			query += fmt.Sprintf(" WHERE type=$%d", indexArg)
-			args = append(args, entit.Type)
-			indexArg++
-		} else {
-			query += fmt.Sprintf(" AND type=$%d", indexArg)
-			args = append(args, entit.Type)
-			indexArg++
-		}
+	sortColumn, ok := incidentSortColumns[entit.Sort]
+	if !ok {
+		sortColumn = incidentSortColumns[entities.IncidentSortCreated]
 	}
-
-	if entit.Name != "" {
-		if indexArg == 1 {
-			query += fmt.Sprintf(" WHERE name=$%d", indexArg)
-			args = append(args, entit.Name)
-			indexArg++
-		} else {
-			query += fmt.Sprintf(" AND name=$%d", indexArg)
-			args = append(args, entit.Name)
-			indexArg++
-		}
+	direction, compare := "ASC", ">"
+	if entit.SortDesc {
+		direction, compare = "DESC", "<"
 	}
-	if entit.Radius != nil {
-		if indexArg == 1 {
-			query += fmt.Sprintf(" WHERE radius=$%d", indexArg)
-			args = append(args, *entit.Radius)
-			indexArg++
-		} else {
-			query += fmt.Sprintf(" AND radius=$%d", indexArg)
-			args = append(args, *entit.Radius)
-			indexArg++
-		}
+	if entit.After != nil {
+		args = append(args, entit.After.Value, entit.After.ID)
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortColumn, compare, len(args)-1, len(args))
 	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortColumn, direction, direction)
+
 	if entit.Limit != 0 {
-		query += fmt.Sprintf(" LIMIT $%d", indexArg)
 		args = append(args, entit.Limit)
-		indexArg++
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
 	}
 	if entit.Offset != 0 {
-		query += fmt.Sprintf(" OFFSET $%d", indexArg)
 		args = append(args, entit.Offset)
-		indexArg++
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	if entit.ForUpdate {
+		query += " FOR UPDATE"
 	}
 	query += ";"
 	return query, args
 }
 
-func (pr *PostgresRepository) RegistrationCheck(ctx context.Context, userID, latitude, longitude string, exec repository.Executor) (string, error) {
+func (pr *PostgresRepository) getIncidentsFilterConditions(entit *entities.PaginationIncidents, args *[]any) string {
+	conditions := ""
+	addCondition := func(format string, value any) {
+		*args = append(*args, value)
+		conditions += " AND " + fmt.Sprintf(format, len(*args))
+	}
+	addList := func(column string, values []string) {
+		switch len(values) {
+		case 0:
+		case 1:
+			addCondition(column+"=$%d", values[0])
+		default:
+			addCondition(column+" = ANY($%d)", pq.Array(values))
+		}
+	}
+
+	if entit.TenantID != "" {
+		addCondition("tenant_id=$%d", entit.TenantID)
+	}
+	if entit.ID != "" {
+		addCondition("id=$%d", entit.ID)
+	}
+	if len(entit.IDs) != 0 {
+		addCondition("id = ANY($%d)", pq.Array(entit.IDs))
+	}
+	addList("status", entit.Statuses)
+	addList("type", entit.Types)
+	if entit.Name != "" {
+		addCondition("name=$%d", entit.Name)
+	}
+	if entit.NameContains != "" {
+		addCondition("name ILIKE $%d", "%"+escapeLike(entit.NameContains)+"%")
+	}
+	if entit.Radius != nil {
+		addCondition("radius=$%d", *entit.Radius)
+	}
+	if entit.RadiusMin != nil {
+		addCondition("radius >= $%d", *entit.RadiusMin)
+	}
+	if entit.RadiusMax != nil {
+		addCondition("radius <= $%d", *entit.RadiusMax)
+	}
+	if entit.IsActive != nil {
+		addCondition("is_active=$%d", *entit.IsActive)
+	}
+	if entit.CreatedFrom != nil {
+		addCondition("created_date >= $%d", *entit.CreatedFrom)
+	}
+	if entit.CreatedTo != nil {
+		addCondition("created_date <= $%d", *entit.CreatedTo)
+	}
+	if entit.UpdatedFrom != nil {
+		addCondition(incidentSortColumns[entities.IncidentSortUpdated]+" >= $%d", *entit.UpdatedFrom)
+	}
+	if entit.UpdatedTo != nil {
+		addCondition(incidentSortColumns[entities.IncidentSortUpdated]+" <= $%d", *entit.UpdatedTo)
+	}
+	if entit.ResolvedFrom != nil {
+		addCondition("resolved_date >= $%d", *entit.ResolvedFrom)
+	}
+	if entit.ResolvedTo != nil {
+		addCondition("resolved_date <= $%d", *entit.ResolvedTo)
+	}
+	if entit.Near != nil {
+		*args = append(*args, entit.Near.Longitude, entit.Near.Latitude, entit.Near.Meters)
+		conditions += fmt.Sprintf(" AND ST_DWithin(coordinates, ST_MakePoint($%d, $%d)::geography, $%d)", len(*args)-2, len(*args)-1, len(*args))
+	}
+	return conditions
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(str string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(str)
+}
+
+// RegistrationCheck returns the id and created_date of the check, together they are the primary key
+// of the partitioned table.
+func (pr *PostgresRepository) RegistrationCheck(ctx context.Context, tenantID, userID, latitude, longitude string, exec repository.Executor) (string, time.Time, error) {
 	if exec == nil {
 		exec = pr.db
 	}
 	var checkId string
+	var createdDate time.Time
 
 	err := exec.QueryRowContext(ctx,
-		`INSERT INTO checks(user_id, latitude, longitude)
-		VALUES($1, $2, $3)
-		RETURNING id;
-		`, userID, latitude, longitude).Scan(&checkId)
+		`INSERT INTO checks(user_id, latitude, longitude, tenant_id)
+		VALUES($1, $2, $3, $4)
+		RETURNING id, created_date;
+		`, userID, latitude, longitude, tenantID).Scan(&checkId, &createdDate)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return checkId, nil
+	return checkId, createdDate, nil
 }
 
-func (pr *PostgresRepository) GetDetectedIncidents(ctx context.Context, longitude, latitude string, exec repository.Executor) ([]*entities.DistanceCheck, error) {
+func (pr *PostgresRepository) GetDetectedIncidents(ctx context.Context, tenantID, longitude, latitude string, exec repository.Executor) ([]*entities.DistanceCheck, error) {
 	if exec == nil {
 		exec = pr.db
 	}
@@ -350,18 +529,21 @@ func (pr *PostgresRepository) GetDetectedIncidents(ctx context.Context, longitud
 		created_date, 
 		updated_date, 
 		resolved_date,
+		tenant_id,
 		ST_Distance(
 			coordinates,
 			ST_MakePoint($1, $2)::geography
 		)AS distance
 		FROM incidents
 	WHERE is_active = true 
+	AND tenant_id = $3
+	AND deleted_date IS NULL
 	AND ST_DWithin(
 		coordinates,
 		ST_MakePoint($1, $2)::geography,
 		radius
 		)
-	ORDER BY distance;`, longitude, latitude,
+	ORDER BY distance;`, longitude, latitude, tenantID,
 	)
 
 	if err != nil {
@@ -387,6 +569,7 @@ func (pr *PostgresRepository) GetDetectedIncidents(ctx context.Context, longitud
 			&res.Incident.CreatedDate,
 			&res.Incident.UpdatedDate,
 			&res.Incident.ResolvedDate,
+			&res.Incident.TenantID,
 			&res.Distance,
 		)
 
@@ -400,7 +583,8 @@ func (pr *PostgresRepository) GetDetectedIncidents(ctx context.Context, longitud
 	return incidents, nil
 }
 
-func (pr *PostgresRepository) UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, isDanger bool, exec repository.Executor) error {
+// UpdateCheckByID filters by created_date as well, so only the partition of the check is scanned.
+func (pr *PostgresRepository) UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, createdDate time.Time, isDanger bool, exec repository.Executor) error {
 	if exec == nil {
 		exec = pr.db
 	}
@@ -410,60 +594,27 @@ func (pr *PostgresRepository) UpdateCheckByID(ctx context.Context, dangersIds []
 	_, err := exec.ExecContext(ctx,
 		`UPDATE checks
 		SET is_danger = $1, detected_incident_ids = $2 
-		WHERE id = $3;`,
+		WHERE id = $3 AND created_date = $4;`,
 		isDanger,
 		pq.Array(dangersIds),
 		checkId,
+		createdDate,
 	)
 	return err
 }
 
-func (pr *PostgresRepository) GetCountUniqueUsers(ctx context.Context, exec repository.Executor) (int, error) {
+// GetCountUniqueUsers counts users of the tenant kept in check_users, users whose checks
+// were all removed by retention are not counted.
+func (pr *PostgresRepository) GetCountUniqueUsers(ctx context.Context, tenantID string, exec repository.Executor) (int, error) {
 	if exec == nil {
 		exec = pr.db
 	}
 	var result int
 	err := exec.QueryRowContext(ctx,
-		`SELECT COUNT(DISTINCT user_id) FROM checks;
-		`).Scan(&result)
+		`SELECT COUNT(*) FROM check_users WHERE tenant_id = $1;
+		`, tenantID).Scan(&result)
 	if err != nil {
 		return 0, err
 	}
 	return result, nil
 }
-
-func (pr *PostgresRepository) GetStaticsForIncidentsWithTimeWindow(ctx context.Context, exec repository.Executor, timeWindow int) ([]*entities.IncidentStat, error) {
-	if exec == nil {
-		exec = pr.db
-	}
-	rows, err := exec.QueryContext(ctx,
-		`SELECT 
-    i.id,
-    i.name,
-    i.type,
-	COUNT(DISTINCT c.user_id)
-	FROM incidents i, checks c
-	WHERE ST_DWithin(c.coordinates, i.coordinates, i.radius)
-	AND i.is_active = true 
-	AND c.created_date >= NOW() - ($1 * INTERVAL '1 minutes')
-	AND c.is_danger = true
-	GROUP BY i.id, i.name, i.type
-	ORDER BY count DESC;`, timeWindow)
-	if err != nil {
-		return nil, err
-	}
-
-	result := []*entities.IncidentStat{}
-	defer rows.Close()
-	for rows.Next() {
-		stat := &entities.IncidentStat{}
-
-		err := rows.Scan(&stat.ID, &stat.Name, &stat.Type, &stat.UserCount)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, stat)
-	}
-
-	return result, nil
-}
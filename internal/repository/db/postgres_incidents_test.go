@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/lib/pq"
 )
 
 const (
@@ -30,16 +31,36 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Name: getPtrStr("New Fire"),
 			},
 			id:            "123e4567-e89b-12d3-a456-426614174000",
-			expectedQuery: "UPDATE incidents SET name=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET name=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"New Fire", false, (*time.Time)(nil), "123e4567-e89b-12d3-a456-426614174000"},
 		},
+		{
+			name: "clear_description",
+			entit: &entities.UpdateIncident{
+				Name:             getPtrStr("New Fire"),
+				ClearDescription: true,
+			},
+			id:            "123e4567-e89b-12d3-a456-426614174000",
+			expectedQuery: "UPDATE incidents SET name=$1, is_active=$2, resolved_date=$3, description=NULL, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
+			expectedArgs:  []any{"New Fire", false, (*time.Time)(nil), "123e4567-e89b-12d3-a456-426614174000"},
+		},
+		{
+			name: "clear_description_ignored_when_set",
+			entit: &entities.UpdateIncident{
+				Description:      getPtrStr("text"),
+				ClearDescription: true,
+			},
+			id:            "uuid",
+			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
+			expectedArgs:  []any{"text", false, (*time.Time)(nil), "uuid"},
+		},
 		{
 			name: "only_type_update",
 			entit: &entities.UpdateIncident{
 				Type: getPtrStr("type"),
 			},
 			id:            "123e4567-e89b-12d3-a456-426614174000",
-			expectedQuery: "UPDATE incidents SET type=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET type=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"type", false, (*time.Time)(nil), "123e4567-e89b-12d3-a456-426614174000"},
 		},
 		{
@@ -52,7 +73,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				ResolvedTime: &someTime,
 			},
 			id:            "uuid",
-			expectedQuery: "UPDATE incidents SET name=$1, radius=$2, status=$3, is_active=$4, resolved_date=$5, updated_date=NOW() WHERE id = $6 RETURNING",
+			expectedQuery: "UPDATE incidents SET name=$1, radius=$2, status=$3, is_active=$4, resolved_date=$5, updated_date=NOW(), version=version+1 WHERE id = $6 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"Big Fire", 15000, StatusResolved, false, &someTime, "uuid"},
 		},
 		{
@@ -62,7 +83,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				ResolvedTime: nil,
 			},
 			id:            "uuid",
-			expectedQuery: "UPDATE incidents SET is_active=$1, resolved_date=$2, updated_date=NOW() WHERE id = $3 RETURNING",
+			expectedQuery: "UPDATE incidents SET is_active=$1, resolved_date=$2, updated_date=NOW(), version=version+1 WHERE id = $3 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{true, (*time.Time)(nil), "uuid"},
 		},
 		{
@@ -71,7 +92,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				ResolvedTime: &someTime,
 			},
 			id:            "uuid",
-			expectedQuery: "UPDATE incidents SET is_active=$1, resolved_date=$2, updated_date=NOW() WHERE id = $3 RETURNING",
+			expectedQuery: "UPDATE incidents SET is_active=$1, resolved_date=$2, updated_date=NOW(), version=version+1 WHERE id = $3 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{false, &someTime, "uuid"},
 		},
 		{
@@ -80,7 +101,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Description: getPtrStr("New detailed report"),
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"New detailed report", false, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -89,7 +110,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Radius: getIntPtr(8000),
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET radius=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET radius=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{8000, false, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -99,7 +120,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				IsActive: true,
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET status=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET status=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{StatusActive, true, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -111,7 +132,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Radius:      getIntPtr(20000),
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET name=$1, type=$2, description=$3, radius=$4, is_active=$5, resolved_date=$6, updated_date=NOW() WHERE id = $7 RETURNING",
+			expectedQuery: "UPDATE incidents SET name=$1, type=$2, description=$3, radius=$4, is_active=$5, resolved_date=$6, updated_date=NOW(), version=version+1 WHERE id = $7 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"Flood", "natural disaster", "Heavy rain", 20000, false, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -126,7 +147,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				ResolvedTime: &someTime,
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET name=$1, type=$2, description=$3, radius=$4, status=$5, is_active=$6, resolved_date=$7, updated_date=NOW() WHERE id = $8 RETURNING",
+			expectedQuery: "UPDATE incidents SET name=$1, type=$2, description=$3, radius=$4, status=$5, is_active=$6, resolved_date=$7, updated_date=NOW(), version=version+1 WHERE id = $8 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"Earthquake", "seismic", "Aftershocks continue", 30000, StatusArchived, false, &someTime, "test-id"},
 		},
 		{
@@ -135,7 +156,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Description: getPtrStr("First report"),
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"First report", false, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -144,7 +165,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				Description: getPtrStr(""),
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET description=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{"", false, (*time.Time)(nil), "test-id"},
 		},
 		{
@@ -155,7 +176,7 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 				ResolvedTime: nil,
 			},
 			id:            "test-id",
-			expectedQuery: "UPDATE incidents SET status=$1, is_active=$2, resolved_date=$3, updated_date=NOW() WHERE id = $4 RETURNING",
+			expectedQuery: "UPDATE incidents SET status=$1, is_active=$2, resolved_date=$3, updated_date=NOW(), version=version+1 WHERE id = $4 AND deleted_date IS NULL RETURNING",
 			expectedArgs:  []any{StatusActive, true, (*time.Time)(nil), "test-id"},
 		},
 	}
@@ -182,6 +203,9 @@ func TestPostgresRepository_getQueryAndArgsForUpdate(t *testing.T) {
 }
 
 func TestGetQueryAndArgsForPagination(t *testing.T) {
+	paginationSelect := "SELECT " + incidentColumns + " FROM incidents WHERE deleted_date IS NULL"
+	filterFrom := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	filterTo := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
 	testCases := []struct {
 		name      string
 		input     *entities.PaginationIncidents
@@ -191,7 +215,7 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 		{
 			name:      "empty_filters",
 			input:     &entities.PaginationIncidents{},
-			wantQuery: "SELECT * FROM incidents;",
+			wantQuery: paginationSelect + " ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{},
 		},
 		{
@@ -200,7 +224,7 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 				Limit:  10,
 				Offset: 20,
 			},
-			wantQuery: "SELECT * FROM incidents LIMIT $1 OFFSET $2;",
+			wantQuery: paginationSelect + " ORDER BY created_date ASC, id ASC LIMIT $1 OFFSET $2;",
 			wantArgs:  []any{10, 20},
 		},
 		{
@@ -208,52 +232,52 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 			input: &entities.PaginationIncidents{
 				ID: "123",
 			},
-			wantQuery: "SELECT * FROM incidents WHERE id=$1;",
+			wantQuery: paginationSelect + " AND id=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"123"},
 		},
 		{
 			name: "only_status",
 			input: &entities.PaginationIncidents{
-				Status: "active",
+				Statuses: []string{"active"},
 			},
-			wantQuery: "SELECT * FROM incidents WHERE status=$1;",
+			wantQuery: paginationSelect + " AND status=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"active"},
 		},
 		{
 			name: "status_and_type",
 			input: &entities.PaginationIncidents{
-				Status: "active",
-				Type:   "fire",
+				Statuses: []string{"active"},
+				Types:    []string{"fire"},
 			},
-			wantQuery: "SELECT * FROM incidents WHERE status=$1 AND type=$2;",
+			wantQuery: paginationSelect + " AND status=$1 AND type=$2 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"active", "fire"},
 		},
 		{
 			name: "type_first_then_status",
 			input: &entities.PaginationIncidents{
-				Type:   "flood",
-				Status: "resolved",
+				Types:    []string{"flood"},
+				Statuses: []string{"resolved"},
 			},
-			wantQuery: "SELECT * FROM incidents WHERE status=$1 AND type=$2;",
+			wantQuery: paginationSelect + " AND status=$1 AND type=$2 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"resolved", "flood"},
 		},
 		{
 			name: "skip_id_but_use_status",
 			input: &entities.PaginationIncidents{
-				ID:     "",
-				Status: "pending",
+				ID:       "",
+				Statuses: []string{"pending"},
 			},
-			wantQuery: "SELECT * FROM incidents WHERE status=$1;",
+			wantQuery: paginationSelect + " AND status=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"pending"},
 		},
 		{
 			name: "id_empty_but_type_and_name",
 			input: &entities.PaginationIncidents{
-				ID:   "",
-				Type: "accident",
-				Name: "Big crash",
+				ID:    "",
+				Types: []string{"accident"},
+				Name:  "Big crash",
 			},
-			wantQuery: "SELECT * FROM incidents WHERE type=$1 AND name=$2;",
+			wantQuery: paginationSelect + " AND type=$1 AND name=$2 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"accident", "Big crash"},
 		},
 		{
@@ -261,7 +285,7 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 			input: &entities.PaginationIncidents{
 				Radius: func(i int) *int { return &i }(5000),
 			},
-			wantQuery: "SELECT * FROM incidents WHERE radius=$1;",
+			wantQuery: paginationSelect + " AND radius=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{5000},
 		},
 		{
@@ -269,31 +293,31 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 			input: &entities.PaginationIncidents{
 				Radius: nil,
 			},
-			wantQuery: "SELECT * FROM incidents;",
+			wantQuery: paginationSelect + " ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{},
 		},
 		{
 			name: "full_combination",
 			input: &entities.PaginationIncidents{
-				ID:     "abc-123",
-				Status: "active",
-				Type:   "theft",
-				Name:   "Stolen bike",
-				Radius: func(i int) *int { return &i }(3000),
-				Limit:  25,
-				Offset: 50,
-			},
-			wantQuery: "SELECT * FROM incidents WHERE id=$1 AND status=$2 AND type=$3 AND name=$4 AND radius=$5 LIMIT $6 OFFSET $7;",
+				ID:       "abc-123",
+				Statuses: []string{"active"},
+				Types:    []string{"theft"},
+				Name:     "Stolen bike",
+				Radius:   func(i int) *int { return &i }(3000),
+				Limit:    25,
+				Offset:   50,
+			},
+			wantQuery: paginationSelect + " AND id=$1 AND status=$2 AND type=$3 AND name=$4 AND radius=$5 ORDER BY created_date ASC, id ASC LIMIT $6 OFFSET $7;",
 			wantArgs:  []any{"abc-123", "active", "theft", "Stolen bike", 3000, 25, 50},
 		},
 		{
 			name: "limit_zero_not_included",
 			input: &entities.PaginationIncidents{
-				Status: "done",
-				Limit:  0,
-				Offset: 0,
+				Statuses: []string{"done"},
+				Limit:    0,
+				Offset:   0,
 			},
-			wantQuery: "SELECT * FROM incidents WHERE status=$1;",
+			wantQuery: paginationSelect + " AND status=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"done"},
 		},
 		{
@@ -301,9 +325,101 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 			input: &entities.PaginationIncidents{
 				Name: "new",
 			},
-			wantQuery: "SELECT * FROM incidents WHERE name=$1;",
+			wantQuery: paginationSelect + " AND name=$1 ORDER BY created_date ASC, id ASC;",
 			wantArgs:  []any{"new"},
 		},
+		{
+			name: "sort_desc_by_radius",
+			input: &entities.PaginationIncidents{
+				Statuses: []string{"active"},
+				Sort:     entities.IncidentSortRadius,
+				SortDesc: true,
+				Limit:    11,
+			},
+			wantQuery: paginationSelect + " AND status=$1 ORDER BY radius DESC, id DESC LIMIT $2;",
+			wantArgs:  []any{"active", 11},
+		},
+		{
+			name: "keyset_after_cursor",
+			input: &entities.PaginationIncidents{
+				Types: []string{"fire"},
+				Sort:  entities.IncidentSortName,
+				After: &entities.IncidentCursor{Value: "alpha", ID: "abc-123"},
+				Limit: 5,
+			},
+			wantQuery: paginationSelect + " AND type=$1 AND (name, id) > ($2, $3) ORDER BY name ASC, id ASC LIMIT $4;",
+			wantArgs:  []any{"fire", "alpha", "abc-123", 5},
+		},
+		{
+			name: "keyset_desc_updated",
+			input: &entities.PaginationIncidents{
+				Sort:     entities.IncidentSortUpdated,
+				SortDesc: true,
+				After:    &entities.IncidentCursor{Value: "2026-01-01T00:00:00Z", ID: "abc-123"},
+			},
+			wantQuery: paginationSelect + " AND (COALESCE(updated_date, created_date), id) < ($1, $2) ORDER BY COALESCE(updated_date, created_date) DESC, id DESC;",
+			wantArgs:  []any{"2026-01-01T00:00:00Z", "abc-123"},
+		},
+		{
+			name: "unknown_sort_falls_back_to_created",
+			input: &entities.PaginationIncidents{
+				Sort: "unknown",
+			},
+			wantQuery: paginationSelect + " ORDER BY created_date ASC, id ASC;",
+			wantArgs:  []any{},
+		},
+		{
+			name: "status_and_type_lists",
+			input: &entities.PaginationIncidents{
+				Statuses: []string{"active", "monitoring"},
+				Types:    []string{"fire", "flood"},
+			},
+			wantQuery: paginationSelect + " AND status = ANY($1) AND type = ANY($2) ORDER BY created_date ASC, id ASC;",
+			wantArgs:  []any{pq.Array([]string{"active", "monitoring"}), pq.Array([]string{"fire", "flood"})},
+		},
+		{
+			name: "name_contains_escaped",
+			input: &entities.PaginationIncidents{
+				NameContains: `50%_off\`,
+			},
+			wantQuery: paginationSelect + " AND name ILIKE $1 ORDER BY created_date ASC, id ASC;",
+			wantArgs:  []any{`%50\%\_off\\%`},
+		},
+		{
+			name: "ranges_and_is_active",
+			input: &entities.PaginationIncidents{
+				RadiusMin:    getIntPtr(100),
+				RadiusMax:    getIntPtr(500),
+				IsActive:     func(b bool) *bool { return &b }(true),
+				CreatedFrom:  &filterFrom,
+				UpdatedTo:    &filterTo,
+				ResolvedFrom: &filterFrom,
+				ResolvedTo:   &filterTo,
+			},
+			wantQuery: paginationSelect + " AND radius >= $1 AND radius <= $2 AND is_active=$3 AND created_date >= $4" +
+				" AND COALESCE(updated_date, created_date) <= $5 AND resolved_date >= $6 AND resolved_date <= $7 ORDER BY created_date ASC, id ASC;",
+			wantArgs: []any{100, 500, true, filterFrom, filterTo, filterFrom, filterTo},
+		},
+		{
+			name: "near_point",
+			input: &entities.PaginationIncidents{
+				Statuses: []string{"active"},
+				Near:     &entities.NearPoint{Latitude: 55.75, Longitude: 37.62, Meters: 1000},
+				Limit:    10,
+			},
+			wantQuery: paginationSelect + " AND status=$1 AND ST_DWithin(coordinates, ST_MakePoint($2, $3)::geography, $4) ORDER BY created_date ASC, id ASC LIMIT $5;",
+			wantArgs:  []any{"active", 37.62, 55.75, 1000, 10},
+		},
+		{
+			name: "ids_for_update",
+			input: &entities.PaginationIncidents{
+				IDs:       []string{"1", "2"},
+				Limit:     1001,
+				ForUpdate: true,
+			},
+			wantQuery: paginationSelect + " AND id = ANY($1) ORDER BY created_date ASC, id ASC LIMIT $2 FOR UPDATE;",
+			wantArgs:  []any{pq.Array([]string{"1", "2"}), 1001},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -322,6 +438,60 @@ func TestGetQueryAndArgsForPagination(t *testing.T) {
 	}
 }
 
+func TestGetQueryAndArgsForCount(t *testing.T) {
+	countSelect := "SELECT COUNT(*) FROM incidents WHERE deleted_date IS NULL"
+	testCases := []struct {
+		name      string
+		input     *entities.PaginationIncidents
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "empty_filters",
+			input:     &entities.PaginationIncidents{},
+			wantQuery: countSelect + ";",
+			wantArgs:  []any{},
+		},
+		{
+			name: "filters_applied",
+			input: &entities.PaginationIncidents{
+				Statuses: []string{"active"},
+				Radius:   getIntPtr(300),
+			},
+			wantQuery: countSelect + " AND status=$1 AND radius=$2;",
+			wantArgs:  []any{"active", 300},
+		},
+		{
+			name: "paging_fields_ignored",
+			input: &entities.PaginationIncidents{
+				Name:     "fire",
+				Sort:     entities.IncidentSortName,
+				SortDesc: true,
+				After:    &entities.IncidentCursor{Value: "b", ID: "abc"},
+				Limit:    10,
+				Offset:   20,
+			},
+			wantQuery: countSelect + " AND name=$1;",
+			wantArgs:  []any{"fire"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForCount(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
+
 func getIntPtr(i int) *int {
 	return &i
 }
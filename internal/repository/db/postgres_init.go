@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
 	"os"
@@ -57,6 +58,32 @@ func (pr *PostgresRepository) Begin() (repository.Tx, error) {
 	return pr.db.Begin()
 }
 
+// TryAdvisoryLock takes the session advisory lock key on a connection of its own, ok is false
+// when another session holds it. unlock releases the lock and returns the connection to the pool.
+func (pr *PostgresRepository) TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error) {
+	conn, err := pr.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1);`, key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+	unlock := func() {
+		// the lock is released with the session when the unlock query fails
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1);`, key); err != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
 func (pr *PostgresRepository) Name() string {
 	return "PostgreSQL"
 }
@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/lib/pq"
+)
+
+const (
+	checksArchiveSchema    = "checks_archive"
+	checksDefaultPartition = "checks_default"
+	partitionBoundLayout   = "2006-01-02 15:04:05"
+	partitionBoundDefault  = "DEFAULT"
+	// the generated coordinates column is computed by the target table
+	checksPartitionColumns = "id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date, tenant_id"
+)
+
+var partitionBoundRegexp = regexp.MustCompile(`^FOR VALUES FROM \('([^']+)'\) TO \('([^']+)'\)$`)
+
+// GetChecksPartitions returns the attached partitions of checks ordered by name,
+// for the checks_pYYYYMM[DD] names it is the order of periods.
+func (pr *PostgresRepository) GetChecksPartitions(ctx context.Context, exec repository.Executor) ([]*entities.ChecksPartition, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	rows, err := exec.QueryContext(ctx, `
+	SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	WHERE i.inhparent = 'checks'::regclass
+	ORDER BY c.relname;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.ChecksPartition{}
+	for rows.Next() {
+		var name, bound string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, err
+		}
+		partition, err := parsePartitionBound(name, bound)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, partition)
+	}
+	return result, rows.Err()
+}
+
+// parsePartitionBound reads bounds of a range partition from pg_get_expr output,
+// such as FOR VALUES FROM ('2026-10-01 00:00:00') TO ('2026-11-01 00:00:00').
+func parsePartitionBound(name, bound string) (*entities.ChecksPartition, error) {
+	if bound == partitionBoundDefault {
+		return &entities.ChecksPartition{Name: name, IsDefault: true}, nil
+	}
+	match := partitionBoundRegexp.FindStringSubmatch(bound)
+	if len(match) != 3 {
+		return nil, fmt.Errorf("unexpected bound of partition %s: %s", name, bound)
+	}
+	from, err := time.Parse(partitionBoundLayout, match[1])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected bound of partition %s: %w", name, err)
+	}
+	to, err := time.Parse(partitionBoundLayout, match[2])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected bound of partition %s: %w", name, err)
+	}
+	return &entities.ChecksPartition{Name: name, From: from, To: to}, nil
+}
+
+// CreateChecksPartition creates partition name for [from, to). Rows of the range which already got
+// into the default partition are moved to the new one, otherwise attaching it would fail.
+// Should run in a transaction.
+func (pr *PostgresRepository) CreateChecksPartition(ctx context.Context, name string, from, to time.Time, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	table := pq.QuoteIdentifier(name)
+	fromLiteral := pq.QuoteLiteral(from.Format(partitionBoundLayout))
+	toLiteral := pq.QuoteLiteral(to.Format(partitionBoundLayout))
+	queries := []string{
+		`CREATE TABLE ` + table + ` (LIKE checks INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING GENERATED);`,
+		`WITH moved AS (
+			DELETE FROM ` + checksDefaultPartition + ` WHERE created_date >= ` + fromLiteral + ` AND created_date < ` + toLiteral + `
+			RETURNING ` + checksPartitionColumns + `
+		)
+		INSERT INTO ` + table + `(` + checksPartitionColumns + `) SELECT ` + checksPartitionColumns + ` FROM moved;`,
+		`ALTER TABLE checks ATTACH PARTITION ` + table + ` FOR VALUES FROM (` + fromLiteral + `) TO (` + toLiteral + `);`,
+	}
+	for _, query := range queries {
+		if _, err := exec.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropChecksPartition removes the partition with all its checks.
+func (pr *PostgresRepository) DropChecksPartition(ctx context.Context, name string, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `DROP TABLE `+pq.QuoteIdentifier(name)+`;`)
+	return err
+}
+
+// ArchiveChecksPartition detaches the partition and moves it to the checks_archive schema,
+// the checks are kept but no longer read by the service.
+func (pr *PostgresRepository) ArchiveChecksPartition(ctx context.Context, name string, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	table := pq.QuoteIdentifier(name)
+	queries := []string{
+		`ALTER TABLE checks DETACH PARTITION ` + table + `;`,
+		`ALTER TABLE ` + table + ` SET SCHEMA ` + checksArchiveSchema + `;`,
+	}
+	for _, query := range queries {
+		if _, err := exec.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeDefaultChecksPartition removes checks older than before from the default partition,
+// with archive they are moved to checks_archive.checks_default. Returns the number of removed checks.
+func (pr *PostgresRepository) PurgeDefaultChecksPartition(ctx context.Context, before time.Time, archive bool, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query := `DELETE FROM ` + checksDefaultPartition + ` WHERE created_date < $1;`
+	if archive {
+		archiveTable := checksArchiveSchema + "." + checksDefaultPartition
+		// coordinates stay generated like in archived partitions, so anonymizing a user updates them too
+		_, err := exec.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+archiveTable+
+			` (LIKE checks INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING GENERATED);`)
+		if err != nil {
+			return 0, err
+		}
+		query = `WITH moved AS (
+			DELETE FROM ` + checksDefaultPartition + ` WHERE created_date < $1 RETURNING ` + checksPartitionColumns + `
+		)
+		INSERT INTO ` + archiveTable + `(` + checksPartitionColumns + `) SELECT ` + checksPartitionColumns + ` FROM moved;`
+	}
+	res, err := exec.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteRollupUsersBefore removes user ids of rollup buckets older than before, the counters stay.
+// Unique users of these buckets can no longer be counted, users without later checks
+// are removed from check_users as well.
+func (pr *PostgresRepository) DeleteRollupUsersBefore(ctx context.Context, before time.Time, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	count := int64(0)
+	for _, table := range []string{"check_rollup_users", "incident_check_rollup_users"} {
+		res, err := exec.ExecContext(ctx, `DELETE FROM `+table+` WHERE bucket_start < $1;`, before)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += affected
+	}
+	res, err := exec.ExecContext(ctx, `DELETE FROM check_users WHERE last_check_date < $1;`, before)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count + affected, nil
+}
+
+// CoarsenChecks rounds coordinates of at most limit checks created in [from, before) to decimals places.
+// Rounded checks do not match the filter, so calling it until it returns less than limit coarsens
+// all of them without holding locks on the whole table. from keeps already processed history out of the scan.
+func (pr *PostgresRepository) CoarsenChecks(ctx context.Context, from, before time.Time, decimals, limit int, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	res, err := exec.ExecContext(ctx, `
+	UPDATE checks SET latitude = ROUND(latitude, $2), longitude = ROUND(longitude, $2)
+	WHERE (id, created_date) IN (
+		SELECT id, created_date FROM checks
+		WHERE created_date >= $4 AND created_date < $1 AND (latitude <> ROUND(latitude, $2) OR longitude <> ROUND(longitude, $2))
+		LIMIT $3
+	);`, before, decimals, limit, from)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CoarsenArchivedChecks rounds coordinates of checks created in [from, before) in the tables
+// of the checks_archive schema. The service does not read them, so every table is updated at once.
+func (pr *PostgresRepository) CoarsenArchivedChecks(ctx context.Context, from, before time.Time, decimals int, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	tables, err := pr.getChecksTables(ctx, exec)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, table := range tables[1:] {
+		res, err := exec.ExecContext(ctx, `
+		UPDATE `+table+` SET latitude = ROUND(latitude, $2), longitude = ROUND(longitude, $2)
+		WHERE created_date >= $3 AND created_date < $1 AND (latitude <> ROUND(latitude, $2) OR longitude <> ROUND(longitude, $2));`,
+			before, decimals, from)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += affected
+	}
+	return count, nil
+}
+
+// GetChecksCoarsening returns sql.ErrNoRows when coarsening never finished a run.
+func (pr *PostgresRepository) GetChecksCoarsening(ctx context.Context, exec repository.Executor) (*entities.ChecksCoarsening, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	res := &entities.ChecksCoarsening{}
+	err := exec.QueryRowContext(ctx, `SELECT coarsened_before, decimals FROM checks_coarsening;`).Scan(&res.CoarsenedBefore, &res.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (pr *PostgresRepository) SaveChecksCoarsening(ctx context.Context, entit *entities.ChecksCoarsening, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	_, err := exec.ExecContext(ctx, `
+	INSERT INTO checks_coarsening(id, coarsened_before, decimals) VALUES(true, $1, $2)
+	ON CONFLICT (id) DO UPDATE SET coarsened_before = EXCLUDED.coarsened_before, decimals = EXCLUDED.decimals;`,
+		entit.CoarsenedBefore, entit.Decimals)
+	return err
+}
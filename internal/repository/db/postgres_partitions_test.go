@@ -0,0 +1,55 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+func TestParsePartitionBound(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    *entities.ChecksPartition
+		wantErr bool
+	}{
+		{
+			name:  "month",
+			input: "FOR VALUES FROM ('2026-10-01 00:00:00') TO ('2026-11-01 00:00:00')",
+			want: &entities.ChecksPartition{
+				Name: "checks_p202610",
+				From: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "default",
+			input: "DEFAULT",
+			want:  &entities.ChecksPartition{Name: "checks_p202610", IsDefault: true},
+		},
+		{
+			name:    "unbounded",
+			input:   "FOR VALUES FROM (MINVALUE) TO ('2026-11-01 00:00:00')",
+			wantErr: true,
+		},
+		{
+			name:    "invalid_time",
+			input:   "FOR VALUES FROM ('2026-13-01 00:00:00') TO ('2026-11-01 00:00:00')",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePartitionBound("checks_p202610", tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("\nPartition mismatch:\nGOT:  %+v\nWANT: %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+// searchFrom joins incidents with the parsed query once so that the filter,
+// rank and headlines share it. Query text is always the first argument.
+const searchFrom = ` FROM incidents, (SELECT websearch_to_tsquery('russian', $1) || websearch_to_tsquery('english', $1) AS query) AS q` +
+	` WHERE deleted_date IS NULL AND search_vector @@ q.query`
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+func (pr *PostgresRepository) SearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec repository.Executor) ([]*entities.SearchResult, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForSearch(text, entit)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.SearchResult{}
+	for rows.Next() {
+		res := &entities.SearchResult{}
+		err := rows.Scan(
+			&res.Incident.Id,
+			&res.Incident.Name,
+			&res.Incident.Type,
+			&res.Incident.Latitude,
+			&res.Incident.Longitude,
+			&res.Incident.Coordinates,
+			&res.Incident.Description,
+			&res.Incident.Radius,
+			&res.Incident.IsActive,
+			&res.Incident.Status,
+			&res.Incident.CreatedDate,
+			&res.Incident.UpdatedDate,
+			&res.Incident.ResolvedDate,
+			&res.Incident.Version,
+			&res.Incident.TenantID,
+			&res.Rank,
+			&res.NameHighlight,
+			&res.DescriptionHighlight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+	return result, rows.Err()
+}
+
+func (pr *PostgresRepository) GetCountSearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec repository.Executor) (int, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	var result int
+	query, args := pr.getQueryAndArgsForSearchCount(text, entit)
+	err := exec.QueryRowContext(ctx, query, args...).Scan(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+func (pr *PostgresRepository) getQueryAndArgsForSearch(text string, entit *entities.PaginationIncidents) (string, []any) {
+	args := []any{text}
+	query := fmt.Sprintf("SELECT %s, ts_rank(search_vector, q.query) AS rank,"+
+		" ts_headline('russian', name, q.query, '%s'),"+
+		" ts_headline('russian', description, q.query, '%s')",
+		incidentColumns, searchHeadlineOptions, searchHeadlineOptions)
+	query += searchFrom
+	query += pr.getIncidentsFilterConditions(entit, &args)
+	query += " ORDER BY rank DESC, id"
+	if entit.Limit != 0 {
+		args = append(args, entit.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if entit.Offset != 0 {
+		args = append(args, entit.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	query += ";"
+	return query, args
+}
+
+func (pr *PostgresRepository) getQueryAndArgsForSearchCount(text string, entit *entities.PaginationIncidents) (string, []any) {
+	args := []any{text}
+	query := "SELECT COUNT(*)" + searchFrom
+	query += pr.getIncidentsFilterConditions(entit, &args)
+	query += ";"
+	return query, args
+}
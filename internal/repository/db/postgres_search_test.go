@@ -0,0 +1,72 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/lib/pq"
+)
+
+func TestGetQueryAndArgsForSearch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		text       string
+		input      *entities.PaginationIncidents
+		wantSuffix string
+		wantArgs   []any
+	}{
+		{
+			name:       "text_only",
+			text:       "прорыв трубы",
+			input:      &entities.PaginationIncidents{},
+			wantSuffix: searchFrom + " ORDER BY rank DESC, id;",
+			wantArgs:   []any{"прорыв трубы"},
+		},
+		{
+			name: "with_filters_and_paging",
+			text: "fire",
+			input: &entities.PaginationIncidents{
+				Statuses: []string{"active", "monitoring"},
+				Types:    []string{"fire"},
+				Limit:    10,
+				Offset:   20,
+			},
+			wantSuffix: searchFrom + " AND status = ANY($2) AND type=$3 ORDER BY rank DESC, id LIMIT $4 OFFSET $5;",
+			wantArgs:   []any{"fire", pq.Array([]string{"active", "monitoring"}), "fire", 10, 20},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForSearch(tc.text, tc.input)
+
+			if !strings.HasPrefix(gotQuery, "SELECT "+incidentColumns+", ts_rank(search_vector, q.query) AS rank,") {
+				t.Errorf("\nQuery must select incident columns and rank:\nGOT:  %s", gotQuery)
+			}
+			if !strings.HasSuffix(gotQuery, tc.wantSuffix) {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT SUFFIX: %s", gotQuery, tc.wantSuffix)
+			}
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGetQueryAndArgsForSearchCount(t *testing.T) {
+	pr := &PostgresRepository{}
+	gotQuery, gotArgs := pr.getQueryAndArgsForSearchCount("fire", &entities.PaginationIncidents{
+		Statuses: []string{"active"},
+		Limit:    10,
+	})
+	wantQuery := "SELECT COUNT(*)" + searchFrom + " AND status=$2;"
+	if gotQuery != wantQuery {
+		t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, wantQuery)
+	}
+	if !reflect.DeepEqual(gotArgs, []any{"fire", "active"}) {
+		t.Errorf("\nArgs mismatch:\nGOT:  %v", gotArgs)
+	}
+}
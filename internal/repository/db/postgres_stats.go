@@ -0,0 +1,230 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/lib/pq"
+)
+
+// RegistrationCheckRollup adds a check to the rollups of the current minute. It must run in the transaction
+// which created the check, then the minute matches created_date of the check.
+func (pr *PostgresRepository) RegistrationCheckRollup(ctx context.Context, tenantID, userID string, incidentIDs []string, exec repository.Executor) error {
+	if exec == nil {
+		exec = pr.db
+	}
+	dangerous := 0
+	if len(incidentIDs) != 0 {
+		dangerous = 1
+	}
+	_, err := exec.ExecContext(ctx, `
+	INSERT INTO check_rollups(tenant_id, bucket_start, checks, dangerous_checks)
+	VALUES($1, date_trunc('minute', NOW()::timestamp), 1, $2)
+	ON CONFLICT (tenant_id, bucket_start) DO UPDATE
+	SET checks = check_rollups.checks + 1, dangerous_checks = check_rollups.dangerous_checks + EXCLUDED.dangerous_checks;`,
+		tenantID, dangerous)
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+	INSERT INTO check_rollup_users(tenant_id, bucket_start, user_id)
+	VALUES($1, date_trunc('minute', NOW()::timestamp), $2)
+	ON CONFLICT DO NOTHING;`,
+		tenantID, userID)
+	if err != nil {
+		return err
+	}
+	// the last check date is kept with minute precision, so repeated checks rewrite the row once a minute
+	_, err = exec.ExecContext(ctx, `
+	INSERT INTO check_users(tenant_id, user_id, last_check_date)
+	VALUES($1, $2, date_trunc('minute', NOW()::timestamp))
+	ON CONFLICT (tenant_id, user_id) DO UPDATE
+	SET last_check_date = EXCLUDED.last_check_date
+	WHERE check_users.last_check_date < EXCLUDED.last_check_date;`,
+		tenantID, userID)
+	if err != nil || dangerous == 0 {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+	INSERT INTO incident_check_rollups(tenant_id, bucket_start, incident_id, dangerous_checks, first_check_date, last_check_date)
+	SELECT $1, date_trunc('minute', NOW()::timestamp), unnest($2::uuid[]), 1, NOW()::timestamp, NOW()::timestamp
+	ON CONFLICT (tenant_id, bucket_start, incident_id) DO UPDATE
+	SET dangerous_checks = incident_check_rollups.dangerous_checks + 1, last_check_date = EXCLUDED.last_check_date;`,
+		tenantID, pq.Array(incidentIDs))
+	if err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+	INSERT INTO incident_check_rollup_users(tenant_id, bucket_start, incident_id, user_id)
+	SELECT $1, date_trunc('minute', NOW()::timestamp), unnest($2::uuid[]), $3
+	ON CONFLICT DO NOTHING;`,
+		tenantID, pq.Array(incidentIDs), userID)
+	return err
+}
+
+// GetStatisticsForIncidents returns incidents which were reported in dangerous checks of the filter,
+// ordered by the number of warned users.
+func (pr *PostgresRepository) GetStatisticsForIncidents(ctx context.Context, filter *entities.IncidentStatsFilter, exec repository.Executor) ([]*entities.IncidentStat, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForIncidentsStatistics(filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.IncidentStat{}
+	for rows.Next() {
+		stat := &entities.IncidentStat{}
+		err := rows.Scan(
+			&stat.ID,
+			&stat.Name,
+			&stat.Type,
+			&stat.Status,
+			&stat.UserCount,
+			&stat.DangerousChecks,
+			&stat.FirstCheckDate,
+			&stat.LastCheckDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stat)
+	}
+	return result, rows.Err()
+}
+
+// getQueryAndArgsForIncidentsStatistics reads the incident rollups, which are filled from detected_incident_ids,
+// so a check is counted only for the incidents the user was actually warned about.
+func (pr *PostgresRepository) getQueryAndArgsForIncidentsStatistics(filter *entities.IncidentStatsFilter) (string, []any) {
+	args := []any{filter.TenantID, filter.From, filter.To}
+	query := "WITH totals AS (SELECT incident_id, SUM(dangerous_checks) AS dangerous_checks, MIN(first_check_date) AS first_check_date," +
+		" MAX(last_check_date) AS last_check_date FROM incident_check_rollups" +
+		" WHERE tenant_id=$1 AND bucket_start >= $2 AND bucket_start < $3 GROUP BY incident_id)," +
+		" users AS (SELECT incident_id, COUNT(DISTINCT user_id) AS user_count FROM incident_check_rollup_users" +
+		" WHERE tenant_id=$1 AND bucket_start >= $2 AND bucket_start < $3 GROUP BY incident_id)" +
+		" SELECT i.id, i.name, i.type, i.status, u.user_count, t.dangerous_checks, t.first_check_date, t.last_check_date" +
+		" FROM totals t JOIN users u ON u.incident_id = t.incident_id JOIN incidents i ON i.id = t.incident_id" +
+		" WHERE i.tenant_id=$1 AND i.deleted_date IS NULL"
+	if !filter.IncludeInactive {
+		query += " AND i.is_active = true"
+	}
+	if len(filter.Types) != 0 {
+		args = append(args, pq.Array(filter.Types))
+		query += fmt.Sprintf(" AND i.type = ANY($%d)", len(args))
+	}
+	query += " ORDER BY u.user_count DESC, i.id;"
+	return query, args
+}
+
+// GetChecksTimeSeries returns only the buckets which have checks, ordered by start.
+func (pr *PostgresRepository) GetChecksTimeSeries(ctx context.Context, filter *entities.ChecksTimeSeriesFilter, exec repository.Executor) ([]*entities.ChecksBucket, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForChecksTimeSeries(filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.ChecksBucket{}
+	for rows.Next() {
+		bucket := &entities.ChecksBucket{}
+		err := rows.Scan(&bucket.Start, &bucket.Checks, &bucket.DangerousChecks, &bucket.UniqueUsers)
+		if err != nil {
+			return nil, err
+		}
+		bucket.Start = bucket.Start.UTC()
+		result = append(result, bucket)
+	}
+	return result, rows.Err()
+}
+
+// getQueryAndArgsForChecksTimeSeries reads the minute rollups, filtered by incident it reads the incident rollups,
+// where every check is a dangerous one.
+func (pr *PostgresRepository) getQueryAndArgsForChecksTimeSeries(filter *entities.ChecksTimeSeriesFilter) (string, []any) {
+	args := []any{int64(filter.Bucket.Seconds()), filter.From, filter.TenantID, filter.To}
+	scope := []string{}
+	if filter.IncidentID != "" {
+		args = append(args, filter.IncidentID)
+		scope = append(scope, fmt.Sprintf("id=$%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		scope = append(scope, fmt.Sprintf("type=$%d", len(args)))
+	}
+
+	bucket := "date_bin($1 * INTERVAL '1 second', bucket_start, $2) AS bucket"
+	window := "tenant_id=$3 AND bucket_start >= $2 AND bucket_start < $4"
+	query := "WITH "
+	totals := "SUM(checks) AS checks, SUM(dangerous_checks) AS dangerous_checks FROM check_rollups WHERE " + window
+	users := "check_rollup_users WHERE " + window
+	if len(scope) != 0 {
+		query += "scope AS (SELECT id FROM incidents WHERE tenant_id=$3 AND deleted_date IS NULL"
+		for _, condition := range scope {
+			query += " AND " + condition
+		}
+		query += "), "
+		inScope := " AND incident_id IN (SELECT id FROM scope)"
+		totals = "SUM(dangerous_checks) AS checks, SUM(dangerous_checks) AS dangerous_checks FROM incident_check_rollups WHERE " + window + inScope
+		users = "incident_check_rollup_users WHERE " + window + inScope
+	}
+	query += "totals AS (SELECT " + bucket + ", " + totals + " GROUP BY 1)," +
+		" users AS (SELECT " + bucket + ", COUNT(DISTINCT user_id) AS unique_users FROM " + users + " GROUP BY 1)" +
+		" SELECT t.bucket, t.checks, t.dangerous_checks, COALESCE(u.unique_users, 0) FROM totals t" +
+		" LEFT JOIN users u ON u.bucket = t.bucket ORDER BY t.bucket;"
+	return query, args
+}
+
+// GetChecksHeatmap returns cells ordered by geohash, cells without checks are not returned.
+func (pr *PostgresRepository) GetChecksHeatmap(ctx context.Context, filter *entities.HeatmapFilter, exec repository.Executor) ([]*entities.HeatmapCell, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForChecksHeatmap(filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.HeatmapCell{}
+	for rows.Next() {
+		cell := &entities.HeatmapCell{}
+		if err := rows.Scan(&cell.Geohash, &cell.Checks, &cell.DangerousChecks); err != nil {
+			return nil, err
+		}
+		result = append(result, cell)
+	}
+	return result, rows.Err()
+}
+
+// getQueryAndArgsForChecksHeatmap filters by the coordinate columns, so only the checks inside bbox
+// are counted even in the cells crossing its border.
+func (pr *PostgresRepository) getQueryAndArgsForChecksHeatmap(filter *entities.HeatmapFilter) (string, []any) {
+	args := []any{filter.TenantID, filter.From, filter.To, filter.Precision}
+	query := "SELECT ST_GeoHash(coordinates::geometry, $4) AS cell, COUNT(*), COUNT(*) FILTER (WHERE is_danger) FROM checks" +
+		" WHERE tenant_id=$1 AND created_date >= $2 AND created_date < $3"
+	if filter.BBox != nil {
+		args = append(args, filter.BBox.MinLat, filter.BBox.MaxLat, filter.BBox.MinLon, filter.BBox.MaxLon)
+		query += fmt.Sprintf(" AND latitude BETWEEN $%d AND $%d AND longitude BETWEEN $%d AND $%d",
+			len(args)-3, len(args)-2, len(args)-1, len(args))
+	}
+	query += " GROUP BY cell"
+	if filter.DangerousOnly {
+		query += " HAVING COUNT(*) FILTER (WHERE is_danger) > 0"
+	}
+	query += " ORDER BY cell"
+	if filter.Limit != 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	query += ";"
+	return query, args
+}
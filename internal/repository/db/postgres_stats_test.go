@@ -0,0 +1,191 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/lib/pq"
+)
+
+func TestGetQueryAndArgsForChecksTimeSeries(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	bucket := "date_bin($1 * INTERVAL '1 second', bucket_start, $2) AS bucket"
+	window := "tenant_id=$3 AND bucket_start >= $2 AND bucket_start < $4"
+	selectPart := " SELECT t.bucket, t.checks, t.dangerous_checks, COALESCE(u.unique_users, 0) FROM totals t" +
+		" LEFT JOIN users u ON u.bucket = t.bucket ORDER BY t.bucket;"
+	inScope := " AND incident_id IN (SELECT id FROM scope)"
+	scopedTotals := "totals AS (SELECT " + bucket + ", SUM(dangerous_checks) AS checks, SUM(dangerous_checks) AS dangerous_checks" +
+		" FROM incident_check_rollups WHERE " + window + inScope + " GROUP BY 1)," +
+		" users AS (SELECT " + bucket + ", COUNT(DISTINCT user_id) AS unique_users FROM incident_check_rollup_users WHERE " +
+		window + inScope + " GROUP BY 1)"
+	testCases := []struct {
+		name      string
+		input     *entities.ChecksTimeSeriesFilter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name: "all_checks",
+			input: &entities.ChecksTimeSeriesFilter{
+				TenantID: "default",
+				From:     from,
+				To:       to,
+				Bucket:   time.Hour,
+			},
+			wantQuery: "WITH totals AS (SELECT " + bucket + ", SUM(checks) AS checks, SUM(dangerous_checks) AS dangerous_checks" +
+				" FROM check_rollups WHERE " + window + " GROUP BY 1)," +
+				" users AS (SELECT " + bucket + ", COUNT(DISTINCT user_id) AS unique_users FROM check_rollup_users WHERE " +
+				window + " GROUP BY 1)" + selectPart,
+			wantArgs: []any{int64(3600), from, "default", to},
+		},
+		{
+			name: "incident",
+			input: &entities.ChecksTimeSeriesFilter{
+				TenantID:   "default",
+				From:       from,
+				To:         to,
+				Bucket:     5 * time.Minute,
+				IncidentID: "uuid",
+			},
+			wantQuery: "WITH scope AS (SELECT id FROM incidents WHERE tenant_id=$3 AND deleted_date IS NULL AND id=$5), " +
+				scopedTotals + selectPart,
+			wantArgs: []any{int64(300), from, "default", to, "uuid"},
+		},
+		{
+			name: "incident_and_type",
+			input: &entities.ChecksTimeSeriesFilter{
+				TenantID:   "moscow",
+				From:       from,
+				To:         to,
+				Bucket:     24 * time.Hour,
+				IncidentID: "uuid",
+				Type:       "fire",
+			},
+			wantQuery: "WITH scope AS (SELECT id FROM incidents WHERE tenant_id=$3 AND deleted_date IS NULL AND id=$5 AND type=$6), " +
+				scopedTotals + selectPart,
+			wantArgs: []any{int64(86400), from, "moscow", to, "uuid", "fire"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForChecksTimeSeries(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGetQueryAndArgsForIncidentsStatistics(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	selectPart := "WITH totals AS (SELECT incident_id, SUM(dangerous_checks) AS dangerous_checks, MIN(first_check_date) AS first_check_date," +
+		" MAX(last_check_date) AS last_check_date FROM incident_check_rollups" +
+		" WHERE tenant_id=$1 AND bucket_start >= $2 AND bucket_start < $3 GROUP BY incident_id)," +
+		" users AS (SELECT incident_id, COUNT(DISTINCT user_id) AS user_count FROM incident_check_rollup_users" +
+		" WHERE tenant_id=$1 AND bucket_start >= $2 AND bucket_start < $3 GROUP BY incident_id)" +
+		" SELECT i.id, i.name, i.type, i.status, u.user_count, t.dangerous_checks, t.first_check_date, t.last_check_date" +
+		" FROM totals t JOIN users u ON u.incident_id = t.incident_id JOIN incidents i ON i.id = t.incident_id" +
+		" WHERE i.tenant_id=$1 AND i.deleted_date IS NULL"
+	orderPart := " ORDER BY u.user_count DESC, i.id;"
+	testCases := []struct {
+		name      string
+		input     *entities.IncidentStatsFilter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "active_incidents",
+			input:     &entities.IncidentStatsFilter{TenantID: "default", From: from, To: to},
+			wantQuery: selectPart + " AND i.is_active = true" + orderPart,
+			wantArgs:  []any{"default", from, to},
+		},
+		{
+			name: "inactive_with_types",
+			input: &entities.IncidentStatsFilter{
+				TenantID:        "moscow",
+				From:            from,
+				To:              to,
+				IncludeInactive: true,
+				Types:           []string{"fire", "flood"},
+			},
+			wantQuery: selectPart + " AND i.type = ANY($4)" + orderPart,
+			wantArgs:  []any{"moscow", from, to, pq.Array([]string{"fire", "flood"})},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForIncidentsStatistics(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGetQueryAndArgsForChecksHeatmap(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	selectPart := "SELECT ST_GeoHash(coordinates::geometry, $4) AS cell, COUNT(*), COUNT(*) FILTER (WHERE is_danger) FROM checks" +
+		" WHERE tenant_id=$1 AND created_date >= $2 AND created_date < $3"
+	testCases := []struct {
+		name      string
+		input     *entities.HeatmapFilter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "whole_world",
+			input:     &entities.HeatmapFilter{TenantID: "default", From: from, To: to, Precision: 5},
+			wantQuery: selectPart + " GROUP BY cell ORDER BY cell;",
+			wantArgs:  []any{"default", from, to, 5},
+		},
+		{
+			name: "bbox_dangerous_with_limit",
+			input: &entities.HeatmapFilter{
+				TenantID:      "moscow",
+				From:          from,
+				To:            to,
+				Precision:     7,
+				BBox:          &entities.BBox{MinLon: 37.3, MinLat: 55.5, MaxLon: 37.9, MaxLat: 56},
+				DangerousOnly: true,
+				Limit:         101,
+			},
+			wantQuery: selectPart + " AND latitude BETWEEN $5 AND $6 AND longitude BETWEEN $7 AND $8" +
+				" GROUP BY cell HAVING COUNT(*) FILTER (WHERE is_danger) > 0 ORDER BY cell LIMIT $9;",
+			wantArgs: []any{"moscow", from, to, 7, 55.5, 56.0, 37.3, 37.9, 101},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForChecksHeatmap(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+	"github.com/lib/pq"
+)
+
+func (pr *PostgresRepository) GetUserChecks(ctx context.Context, filter *entities.UserChecksFilter, exec repository.Executor) ([]*entities.CheckRecord, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	query, args := pr.getQueryAndArgsForUserChecks(filter)
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entities.CheckRecord{}
+	for rows.Next() {
+		res := &entities.CheckRecord{}
+		err := rows.Scan(
+			&res.ID,
+			&res.UserID,
+			&res.Latitude,
+			&res.Longitude,
+			&res.IsDanger,
+			pq.Array(&res.DetectedIncidentIDs),
+			&res.CreatedDate,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res)
+	}
+	return result, rows.Err()
+}
+
+func (pr *PostgresRepository) getQueryAndArgsForUserChecks(filter *entities.UserChecksFilter) (string, []any) {
+	args := []any{filter.TenantID, filter.UserID}
+	query := "SELECT id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date FROM checks WHERE tenant_id=$1 AND user_id=$2"
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		query += fmt.Sprintf(" AND created_date >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		query += fmt.Sprintf(" AND created_date <= $%d", len(args))
+	}
+	if filter.DangerousOnly {
+		query += " AND is_danger = true"
+	}
+	query += " ORDER BY created_date DESC, id"
+	if filter.Limit != 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset != 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	query += ";"
+	return query, args
+}
+
+// DeleteUserChecks removes checks of the user, including checks archived by retention,
+// and the user from unique users of the rollups. Rollup counters are not personal data
+// and stay as they are. Returns the number of deleted checks.
+func (pr *PostgresRepository) DeleteUserChecks(ctx context.Context, tenantID, userID string, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	tables, err := pr.getChecksTables(ctx, exec)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, table := range tables {
+		res, err := exec.ExecContext(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1 AND user_id = $2;`, tenantID, userID)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += affected
+	}
+	for _, table := range []string{"check_users", "check_rollup_users", "incident_check_rollup_users"} {
+		_, err := exec.ExecContext(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1 AND user_id = $2;`, tenantID, userID)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// AnonymizeUserChecks gives every check of the user, including archived ones, its own random id
+// and rounds its coordinates. Rollups keep no coordinates and get one pseudonym, so that statistics
+// keep counting the user once. Returns the number of changed checks.
+func (pr *PostgresRepository) AnonymizeUserChecks(ctx context.Context, entit *entities.UserAnonymization, exec repository.Executor) (int64, error) {
+	if exec == nil {
+		exec = pr.db
+	}
+	tables, err := pr.getChecksTables(ctx, exec)
+	if err != nil {
+		return 0, err
+	}
+	count := int64(0)
+	for _, table := range tables {
+		res, err := exec.ExecContext(ctx, `
+		UPDATE `+table+` SET user_id = $3 || gen_random_uuid()::text, latitude = ROUND(latitude, $4), longitude = ROUND(longitude, $4)
+		WHERE tenant_id = $1 AND user_id = $2;`, entit.TenantID, entit.UserID, entit.CheckPrefix, entit.Decimals)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		count += affected
+	}
+	for _, table := range []string{"check_users", "check_rollup_users", "incident_check_rollup_users"} {
+		_, err := exec.ExecContext(ctx, `UPDATE `+table+` SET user_id = $3 WHERE tenant_id = $1 AND user_id = $2;`,
+			entit.TenantID, entit.UserID, entit.Pseudonym)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// getChecksTables returns checks and the tables of the checks_archive schema, archived partitions
+// hold checks of users as well and data subject requests must reach them.
+func (pr *PostgresRepository) getChecksTables(ctx context.Context, exec repository.Executor) ([]string, error) {
+	rows, err := exec.QueryContext(ctx, `SELECT tablename FROM pg_tables WHERE schemaname = $1 ORDER BY tablename;`, checksArchiveSchema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []string{"checks"}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		result = append(result, checksArchiveSchema+"."+pq.QuoteIdentifier(name))
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+func TestGetQueryAndArgsForUserChecks(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	selectPart := "SELECT id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date FROM checks WHERE tenant_id=$1 AND user_id=$2"
+	testCases := []struct {
+		name      string
+		input     *entities.UserChecksFilter
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "no_filters",
+			input:     &entities.UserChecksFilter{TenantID: "default", UserID: "user"},
+			wantQuery: selectPart + " ORDER BY created_date DESC, id;",
+			wantArgs:  []any{"default", "user"},
+		},
+		{
+			name: "all_filters",
+			input: &entities.UserChecksFilter{
+				TenantID:      "moscow",
+				UserID:        "user",
+				From:          &from,
+				To:            &to,
+				DangerousOnly: true,
+				Limit:         10,
+				Offset:        20,
+			},
+			wantQuery: selectPart + " AND created_date >= $3 AND created_date <= $4 AND is_danger = true" +
+				" ORDER BY created_date DESC, id LIMIT $5 OFFSET $6;",
+			wantArgs: []any{"moscow", "user", from, to, 10, 20},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+			gotQuery, gotArgs := pr.getQueryAndArgsForUserChecks(tc.input)
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("\nQuery mismatch:\nGOT:  %s\nWANT: %s", gotQuery, tc.wantQuery)
+			}
+
+			if !reflect.DeepEqual(gotArgs, tc.wantArgs) {
+				t.Errorf("\nArgs mismatch:\nGOT:  %v\nWANT: %v", gotArgs, tc.wantArgs)
+			}
+		})
+	}
+}
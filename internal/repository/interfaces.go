@@ -14,25 +14,73 @@ type DbReposytory interface {
 	PingWithCtx(ctx context.Context) error
 	PingWithTimeout(duration time.Duration) error
 	Close() error
+	TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error)
 	RegistrationIncident(ctx context.Context, entit *entities.RegistrationIncidentEntitie, exec Executor) (string, error)
 	GetInfoByIncidentID(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error)
 	GetExistByIncidentID(ctx context.Context, id string, exec Executor) (bool, error)
+	GetExistByIncidentName(ctx context.Context, tenantID, name string, exec Executor) (bool, error)
 	UpdateIncidentByID(ctx context.Context, id string, entit *entities.UpdateIncident, exec Executor) (*entities.ReadIncident, error)
+	GetInfoByIncidentIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error)
+	GetInfoByIncidentIDForShare(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error)
 	DeleteIncidentByID(ctx context.Context, id string, exec Executor) error
-	GetCountRows(ctx context.Context, exec Executor) (int, error)
+	GetDeletedInfoByIncidentID(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error)
+	GetDeletedInfoByIncidentIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error)
+	RestoreIncidentByID(ctx context.Context, id string, entit *entities.RestoreIncident, exec Executor) (*entities.ReadIncident, error)
+	PurgeDeletedIncidents(ctx context.Context, deletedBefore time.Time, exec Executor) (int64, error)
+	RegistrationStatusTransition(ctx context.Context, entit *entities.StatusTransition, exec Executor) error
+	RegistrationAuditRecord(ctx context.Context, entit *entities.AuditRecord, exec Executor) error
+	GetAuditRecords(ctx context.Context, filter *entities.AuditFilter, exec Executor) ([]*entities.AuditRecord, error)
+	GetCountRows(ctx context.Context, entit *entities.PaginationIncidents, exec Executor) (int, error)
 	GetPaginationIncidentsInfo(ctx context.Context, entit *entities.PaginationIncidents, exec Executor) ([]*entities.ReadIncident, error)
-	RegistrationCheck(ctx context.Context, userID, latitude, longitude string, exec Executor) (string, error)
-	GetDetectedIncidents(ctx context.Context, longitude, latitude string, exec Executor) ([]*entities.DistanceCheck, error)
-	UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, isDanger bool, exec Executor) error
-	GetCountUniqueUsers(ctx context.Context, exec Executor) (int, error)
-	GetStaticsForIncidentsWithTimeWindow(ctx context.Context, exec Executor, timeWindow int) ([]*entities.IncidentStat, error)
+	SearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec Executor) ([]*entities.SearchResult, error)
+	GetCountSearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec Executor) (int, error)
+	ExportIncidents(ctx context.Context, entit *entities.PaginationIncidents, fn func(*entities.ReadIncident) error, exec Executor) error
+	ExportChecks(ctx context.Context, tenantID string, from, to time.Time, fn func(*entities.CheckRecord) error, exec Executor) error
+	ExportIncidentExposure(ctx context.Context, tenantID, incidentID string, fn func(*entities.ExposedUser) error, exec Executor) error
+	GetUserChecks(ctx context.Context, filter *entities.UserChecksFilter, exec Executor) ([]*entities.CheckRecord, error)
+	DeleteUserChecks(ctx context.Context, tenantID, userID string, exec Executor) (int64, error)
+	AnonymizeUserChecks(ctx context.Context, entit *entities.UserAnonymization, exec Executor) (int64, error)
+	GetChecksPartitions(ctx context.Context, exec Executor) ([]*entities.ChecksPartition, error)
+	CreateChecksPartition(ctx context.Context, name string, from, to time.Time, exec Executor) error
+	DropChecksPartition(ctx context.Context, name string, exec Executor) error
+	ArchiveChecksPartition(ctx context.Context, name string, exec Executor) error
+	PurgeDefaultChecksPartition(ctx context.Context, before time.Time, archive bool, exec Executor) (int64, error)
+	DeleteRollupUsersBefore(ctx context.Context, before time.Time, exec Executor) (int64, error)
+	CoarsenChecks(ctx context.Context, from, before time.Time, decimals, limit int, exec Executor) (int64, error)
+	CoarsenArchivedChecks(ctx context.Context, from, before time.Time, decimals int, exec Executor) (int64, error)
+	GetChecksCoarsening(ctx context.Context, exec Executor) (*entities.ChecksCoarsening, error)
+	SaveChecksCoarsening(ctx context.Context, entit *entities.ChecksCoarsening, exec Executor) error
+	RegistrationCheck(ctx context.Context, tenantID, userID, latitude, longitude string, exec Executor) (string, time.Time, error)
+	GetDetectedIncidents(ctx context.Context, tenantID, longitude, latitude string, exec Executor) ([]*entities.DistanceCheck, error)
+	UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, createdDate time.Time, isDanger bool, exec Executor) error
+	GetCountUniqueUsers(ctx context.Context, tenantID string, exec Executor) (int, error)
+	RegistrationCheckRollup(ctx context.Context, tenantID, userID string, incidentIDs []string, exec Executor) error
+	GetStatisticsForIncidents(ctx context.Context, filter *entities.IncidentStatsFilter, exec Executor) ([]*entities.IncidentStat, error)
+	GetChecksTimeSeries(ctx context.Context, filter *entities.ChecksTimeSeriesFilter, exec Executor) ([]*entities.ChecksBucket, error)
+	GetChecksHeatmap(ctx context.Context, filter *entities.HeatmapFilter, exec Executor) ([]*entities.HeatmapCell, error)
+	FindCheckClusters(ctx context.Context, params *entities.HotspotParams, exec Executor) ([]*entities.Hotspot, error)
+	GetNearbyHotspotForUpdate(ctx context.Context, tenantID, latitude, longitude string, distance int, seenSince time.Time, exec Executor) (*entities.Hotspot, error)
+	GetHotspotByIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.Hotspot, error)
+	RegistrationHotspot(ctx context.Context, entit *entities.Hotspot, exec Executor) (string, error)
+	UpdateHotspotCluster(ctx context.Context, id string, entit *entities.Hotspot, exec Executor) error
+	UpdateHotspotStatus(ctx context.Context, id, status string, incidentID *string, exec Executor) error
+	GetHotspots(ctx context.Context, tenantID, status string, exec Executor) ([]*entities.Hotspot, error)
+	RegistrationAPIKey(ctx context.Context, entit *entities.APIKey, exec Executor) (*entities.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string, exec Executor) (*entities.APIKey, error)
+	GetAPIKeyByIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.APIKey, error)
+	GetAPIKeys(ctx context.Context, tenantID string, exec Executor) ([]*entities.APIKey, error)
+	UpdateAPIKeyExpiry(ctx context.Context, id string, expiresAt *time.Time, exec Executor) error
+	RevokeAPIKey(ctx context.Context, id string, exec Executor) error
+	UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time, exec Executor) error
 	Name() string
 }
 
 type CacheReposytory interface {
 	SetActiveIncident(ctx context.Context, data *entities.ReadIncident) error
-	GetActiveIncident(ctx context.Context, id string) (*entities.ReadIncident, error)
-	DeleteActiveIncident(ctx context.Context, id string) error
+	GetActiveIncident(ctx context.Context, tenantID, id string) (*entities.ReadIncident, error)
+	DeleteActiveIncident(ctx context.Context, tenantID, id string) error
+	// DeleteUserState removes everything kept in cache about the user, such as rate limit buckets
+	DeleteUserState(ctx context.Context, tenantID, userID string) error
 	PingWithCtx(ctx context.Context) error
 	Name() string
 }
@@ -43,9 +91,22 @@ type Executor interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
+// RateLimiter takes one token from the bucket of key, the bucket holds capacity tokens
+// and is refilled completely in period. When the bucket is empty it returns the time to wait.
+type RateLimiter interface {
+	Take(ctx context.Context, key string, capacity int, period time.Duration) (bool, time.Duration, error)
+}
+
+// NonceStore remembers nonces of signed requests, UseNonce returns false for a nonce seen within ttl.
+type NonceStore interface {
+	UseNonce(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
 type CacheQueue interface {
 	PopFromQueue(ctx context.Context) (*dto.WebhookTask, bool, error)
 	AddToQueue(read *dto.WebhookTask, ctx context.Context) error
+	// RemoveUserTasks drops queued tasks with checks of the user, returns the number of removed tasks
+	RemoveUserTasks(ctx context.Context, tenant, userID string) (int, error)
 	PingWithCtx(ctx context.Context) error
 	Name() string
 }
@@ -10,14 +10,16 @@ import (
 
 /*
 SetActiveIncident(ctx context.Context, data *entities.ReadIncident) error
-	GetActiveIncident(ctx context.Context, id string) (*entities.ReadIncident, error)
-	DeleteActiveIncident(ctx context.Context, id string) error
+	GetActiveIncident(ctx context.Context, tenantID, id string) (*entities.ReadIncident, error)
+	DeleteActiveIncident(ctx context.Context, tenantID, id string) error
 	PingWithCtx(ctx context.Context) error
 */
 
 type CacheMock struct {
 	Storage map[string]*entities.ReadIncident
-	mu      sync.RWMutex
+	// ForgottenUsers keeps tenant:user pairs passed to DeleteUserState
+	ForgottenUsers []string
+	mu             sync.RWMutex
 }
 
 func NewCacheMock() *CacheMock {
@@ -34,22 +36,31 @@ func (cm *CacheMock) SetActiveIncident(ctx context.Context, data *entities.ReadI
 	return nil
 }
 
-func (cm *CacheMock) GetActiveIncident(ctx context.Context, id string) (*entities.ReadIncident, error) {
+func (cm *CacheMock) GetActiveIncident(ctx context.Context, tenantID, id string) (*entities.ReadIncident, error) {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
 	res, ok := cm.Storage[id]
-	if !ok {
+	if !ok || tenantOf(res.TenantID) != tenantID {
 		return nil, fmt.Errorf("no contains with id")
 	}
 
 	return res, nil
 }
 
-func (cm *CacheMock) DeleteActiveIncident(ctx context.Context, id string) error {
+func (cm *CacheMock) DeleteActiveIncident(ctx context.Context, tenantID, id string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
-	delete(cm.Storage, id)
+	if res, ok := cm.Storage[id]; ok && tenantOf(res.TenantID) == tenantID {
+		delete(cm.Storage, id)
+	}
+	return nil
+}
+
+func (cm *CacheMock) DeleteUserState(ctx context.Context, tenantID, userID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.ForgottenUsers = append(cm.ForgottenUsers, tenantID+":"+userID)
 	return nil
 }
 
@@ -7,9 +7,12 @@ import (
 	"math"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/Piccadilly98/incidents_service/internal/geohash"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
 	"github.com/google/uuid"
 )
@@ -17,6 +20,7 @@ import (
 const R = 6371000
 
 type Check struct {
+	TenantID    string
 	UserID      string
 	Latitude    string
 	Longitude   string
@@ -26,23 +30,40 @@ type Check struct {
 }
 
 type MockDbRepository struct {
-	Storage map[string]*entities.ReadIncident
-	Checks  map[string]*Check
-	Mu      *sync.RWMutex
-	Tx      *FakeTx
-	InTx    bool
+	Storage     map[string]*entities.ReadIncident
+	Checks      map[string]*Check
+	Transitions []*entities.StatusTransition
+	Audit       []*entities.AuditRecord
+	APIKeys     map[string]*entities.APIKey
+	Hotspots    map[string]*entities.Hotspot
+	Partitions  map[string]*entities.ChecksPartition
+	// Archived keeps names of archived partitions, their checks are moved from Checks to ArchivedChecks
+	Archived       []string
+	ArchivedChecks map[string]*Check
+	Coarsening     *entities.ChecksCoarsening
+	// Locks keeps advisory locks held by other sessions
+	Locks     map[int64]bool
+	Mu        *sync.RWMutex
+	Tx        *FakeTx
+	CommitErr error
+	InTx      bool
 }
 
 func NewMockDb() *MockDbRepository {
 	return &MockDbRepository{
-		Storage: make(map[string]*entities.ReadIncident),
-		Mu:      &sync.RWMutex{},
-		Checks:  make(map[string]*Check),
+		Storage:        make(map[string]*entities.ReadIncident),
+		Mu:             &sync.RWMutex{},
+		Checks:         make(map[string]*Check),
+		APIKeys:        make(map[string]*entities.APIKey),
+		Hotspots:       make(map[string]*entities.Hotspot),
+		Partitions:     make(map[string]*entities.ChecksPartition),
+		ArchivedChecks: make(map[string]*Check),
+		Locks:          make(map[int64]bool),
 	}
 }
 
 func (m *MockDbRepository) Begin() (Tx, error) {
-	m.Tx = &FakeTx{}
+	m.Tx = &FakeTx{CommitErr: m.CommitErr}
 	return m.Tx, nil
 }
 
@@ -58,6 +79,21 @@ func (m *MockDbRepository) PingWithCtx(ctx context.Context) error {
 	return nil
 }
 
+func (m *MockDbRepository) TryAdvisoryLock(ctx context.Context, key int64) (func(), bool, error) {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	if m.Locks[key] {
+		return nil, false, nil
+	}
+	m.Locks[key] = true
+	unlock := func() {
+		m.Mu.Lock()
+		defer m.Mu.Unlock()
+		delete(m.Locks, key)
+	}
+	return unlock, true, nil
+}
+
 func (m *MockDbRepository) Name() string {
 	return "mock_db_repository"
 }
@@ -68,12 +104,52 @@ func (m *MockDbRepository) GetInfoByIncidentID(ctx context.Context, id string, e
 	}
 	m.Mu.RLock()
 	stEntit, ok := m.Storage[id]
-	if !ok {
+	m.Mu.RUnlock()
+	if !ok || stEntit.DeletedDate != nil {
 		return nil, sql.ErrNoRows
 	}
+	return copyReadIncident(id, stEntit), nil
+}
+
+func (m *MockDbRepository) GetInfoByIncidentIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error) {
+	return m.GetInfoByIncidentID(ctx, id, exec)
+}
+
+func (m *MockDbRepository) GetInfoByIncidentIDForShare(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error) {
+	return m.GetInfoByIncidentID(ctx, id, exec)
+}
+
+func (m *MockDbRepository) GetDeletedInfoByIncidentIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error) {
+	return m.GetDeletedInfoByIncidentID(ctx, id, exec)
+}
+
+func (m *MockDbRepository) GetDeletedInfoByIncidentID(ctx context.Context, id string, exec Executor) (*entities.ReadIncident, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	stEntit, ok := m.Storage[id]
 	m.Mu.RUnlock()
+	if !ok || stEntit.DeletedDate == nil {
+		return nil, sql.ErrNoRows
+	}
+	res := copyReadIncident(id, stEntit)
+	res.DeletedDate = stEntit.DeletedDate
+	return res, nil
+}
+
+// tenantOf mirrors the column default, incidents put into Storage by tests belong to the default tenant.
+func tenantOf(tenantID string) string {
+	if tenantID == "" {
+		return identity.DefaultTenant
+	}
+	return tenantID
+}
+
+func copyReadIncident(id string, stEntit *entities.ReadIncident) *entities.ReadIncident {
 	res := &entities.ReadIncident{}
 	res.Id = id
+	res.TenantID = tenantOf(stEntit.TenantID)
 	res.Name = stEntit.Name
 	res.Type = stEntit.Type
 	res.Description = stEntit.Description
@@ -86,7 +162,8 @@ func (m *MockDbRepository) GetInfoByIncidentID(ctx context.Context, id string, e
 	res.UpdatedDate = &time.Time{}
 	res.ResolvedDate = stEntit.ResolvedDate
 	res.CreatedDate = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
-	return res, nil
+	res.Version = stEntit.Version
+	return res
 }
 
 func (m *MockDbRepository) GetExistByIncidentID(ctx context.Context, id string, exec Executor) (bool, error) {
@@ -95,8 +172,22 @@ func (m *MockDbRepository) GetExistByIncidentID(ctx context.Context, id string,
 	}
 	m.Mu.RLock()
 	defer m.Mu.RUnlock()
-	_, ok := m.Storage[id]
-	return ok, nil
+	res, ok := m.Storage[id]
+	return ok && res.DeletedDate == nil, nil
+}
+
+func (m *MockDbRepository) GetExistByIncidentName(ctx context.Context, tenantID, name string, exec Executor) (bool, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+	for _, res := range m.Storage {
+		if tenantOf(res.TenantID) == tenantID && res.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func (m *MockDbRepository) UpdateIncidentByID(ctx context.Context, id string, entit *entities.UpdateIncident, exec Executor) (*entities.ReadIncident, error) {
@@ -108,7 +199,7 @@ func (m *MockDbRepository) UpdateIncidentByID(ctx context.Context, id string, en
 	defer m.Mu.Unlock()
 
 	res, ok := m.Storage[id]
-	if !ok {
+	if !ok || res.DeletedDate != nil {
 		return nil, sql.ErrNoRows
 	}
 
@@ -130,8 +221,12 @@ func (m *MockDbRepository) UpdateIncidentByID(ctx context.Context, id string, en
 	res.IsActive = entit.IsActive
 	if entit.Description != nil {
 		res.Description = entit.Description
+	} else if entit.ClearDescription {
+		res.Description = nil
 	}
+	res.TenantID = tenantOf(res.TenantID)
 	res.UpdatedDate = getTimePtr(time.Now().UTC())
+	res.Version++
 	return res, nil
 }
 
@@ -143,45 +238,507 @@ func (m *MockDbRepository) DeleteIncidentByID(ctx context.Context, id string, ex
 	m.Mu.Lock()
 	defer m.Mu.Unlock()
 
-	delete(m.Storage, id)
+	res, ok := m.Storage[id]
+	if !ok || res.DeletedDate != nil {
+		return nil
+	}
+	deleted := *res
+	deleted.IsActive = false
+	deleted.DeletedDate = getTimePtr(time.Now().UTC())
+	deleted.Version++
+	m.Storage[id] = &deleted
+	return nil
+}
+
+func (m *MockDbRepository) RestoreIncidentByID(ctx context.Context, id string, entit *entities.RestoreIncident, exec Executor) (*entities.ReadIncident, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	res, ok := m.Storage[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	restored := *res
+	restored.DeletedDate = nil
+	restored.Status = entit.Status
+	restored.IsActive = entit.IsActive
+	restored.ResolvedDate = entit.ResolvedTime
+	restored.UpdatedDate = getTimePtr(time.Now().UTC())
+	restored.Version++
+	m.Storage[id] = &restored
+	return copyReadIncident(id, &restored), nil
+}
+
+func (m *MockDbRepository) PurgeDeletedIncidents(ctx context.Context, deletedBefore time.Time, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	var count int64
+	for id, res := range m.Storage {
+		if res.DeletedDate != nil && res.DeletedDate.Before(deletedBefore) {
+			delete(m.Storage, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockDbRepository) RegistrationStatusTransition(ctx context.Context, entit *entities.StatusTransition, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	m.Transitions = append(m.Transitions, entit)
+	return nil
+}
+
+func (m *MockDbRepository) RegistrationAuditRecord(ctx context.Context, entit *entities.AuditRecord, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	entit.ID = uuid.NewString()
+	entit.CreatedDate = time.Now().UTC()
+	m.Audit = append(m.Audit, entit)
 	return nil
 }
 
+func (m *MockDbRepository) GetAuditRecords(ctx context.Context, filter *entities.AuditFilter, exec Executor) ([]*entities.AuditRecord, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	res := []*entities.AuditRecord{}
+	for _, record := range m.Audit {
+		if filter.TenantID != "" && record.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.IncidentID != "" && record.IncidentID != filter.IncidentID {
+			continue
+		}
+		if filter.Actor != "" && record.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && record.Action != filter.Action {
+			continue
+		}
+		if filter.RequestID != "" && record.RequestID != filter.RequestID {
+			continue
+		}
+		if filter.From != nil && record.CreatedDate.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && record.CreatedDate.After(*filter.To) {
+			continue
+		}
+		res = append(res, record)
+	}
+	if filter.Offset >= len(res) {
+		return []*entities.AuditRecord{}, nil
+	}
+	res = res[filter.Offset:]
+	if filter.Limit != 0 && filter.Limit < len(res) {
+		res = res[:filter.Limit]
+	}
+	return res, nil
+}
+
 func getTimePtr(t time.Time) *time.Time {
 	return &t
 }
 
-func (m *MockDbRepository) GetCountRows(ctx context.Context, exec Executor) (int, error) {
+func (m *MockDbRepository) GetCountRows(ctx context.Context, entit *entities.PaginationIncidents, exec Executor) (int, error) {
 	if exec != nil {
 		m.InTx = true
 	}
 	m.Mu.RLock()
 	defer m.Mu.RUnlock()
 
-	return len(m.Storage), nil
+	count := 0
+	for _, res := range m.Storage {
+		if res.DeletedDate == nil && matchPaginationFilter(res, entit) {
+			count++
+		}
+	}
+	return count, nil
 }
 
 func (m *MockDbRepository) GetPaginationIncidentsInfo(ctx context.Context, entit *entities.PaginationIncidents, exec Executor) ([]*entities.ReadIncident, error) {
-	return nil, nil
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	res := []*entities.ReadIncident{}
+	for _, incident := range m.Storage {
+		if incident.DeletedDate != nil || !matchPaginationFilter(incident, entit) {
+			continue
+		}
+		if entit.After != nil {
+			cmp := compareSortKeys(sortValue(incident, entit.Sort), incident.Id, entit.After.Value, entit.After.ID)
+			if (!entit.SortDesc && cmp <= 0) || (entit.SortDesc && cmp >= 0) {
+				continue
+			}
+		}
+		res = append(res, copyReadIncident(incident.Id, incident))
+	}
+	slices.SortFunc(res, func(a, b *entities.ReadIncident) int {
+		cmp := compareSortKeys(sortValue(a, entit.Sort), a.Id, sortValue(b, entit.Sort), b.Id)
+		if entit.SortDesc {
+			return -cmp
+		}
+		return cmp
+	})
+
+	if entit.Offset != 0 {
+		if entit.Offset >= len(res) {
+			return []*entities.ReadIncident{}, nil
+		}
+		res = res[entit.Offset:]
+	}
+	if entit.Limit != 0 && entit.Limit < len(res) {
+		res = res[:entit.Limit]
+	}
+	return res, nil
+}
+
+// SearchIncidents matches incidents whose name or description contains every word of the text.
+func (m *MockDbRepository) SearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec Executor) ([]*entities.SearchResult, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	res := []*entities.SearchResult{}
+	for _, incident := range m.Storage {
+		if incident.DeletedDate != nil || !matchPaginationFilter(incident, entit) {
+			continue
+		}
+		rank := searchRank(incident, text)
+		if rank == 0 {
+			continue
+		}
+		res = append(res, &entities.SearchResult{
+			Incident:             *copyReadIncident(incident.Id, incident),
+			Rank:                 rank,
+			NameHighlight:        incident.Name,
+			DescriptionHighlight: incident.Description,
+		})
+	}
+	slices.SortFunc(res, func(a, b *entities.SearchResult) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Incident.Id, b.Incident.Id)
+	})
+	if entit.Offset != 0 {
+		if entit.Offset >= len(res) {
+			return []*entities.SearchResult{}, nil
+		}
+		res = res[entit.Offset:]
+	}
+	if entit.Limit != 0 && entit.Limit < len(res) {
+		res = res[:entit.Limit]
+	}
+	return res, nil
+}
+
+func (m *MockDbRepository) GetCountSearchIncidents(ctx context.Context, text string, entit *entities.PaginationIncidents, exec Executor) (int, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	count := 0
+	for _, incident := range m.Storage {
+		if incident.DeletedDate == nil && matchPaginationFilter(incident, entit) && searchRank(incident, text) != 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// searchRank weights name matches above description matches like the postgres search vector does.
+func searchRank(incident *entities.ReadIncident, text string) float64 {
+	name := strings.ToLower(incident.Name)
+	description := ""
+	if incident.Description != nil {
+		description = strings.ToLower(*incident.Description)
+	}
+	rank := 0.0
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		switch {
+		case strings.Contains(name, word):
+			rank += 1
+		case strings.Contains(description, word):
+			rank += 0.4
+		default:
+			return 0
+		}
+	}
+	return rank
+}
+
+func (m *MockDbRepository) ExportIncidents(ctx context.Context, entit *entities.PaginationIncidents, fn func(*entities.ReadIncident) error, exec Executor) error {
+	filter := *entit
+	filter.Limit, filter.Offset, filter.After = 0, 0, nil
+	incidents, err := m.GetPaginationIncidentsInfo(ctx, &filter, exec)
+	if err != nil {
+		return err
+	}
+	for _, incident := range incidents {
+		if err := fn(incident); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockDbRepository) ExportChecks(ctx context.Context, tenantID string, from, to time.Time, fn func(*entities.CheckRecord) error, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	res := []*entities.CheckRecord{}
+	for id, check := range m.Checks {
+		if tenantOf(check.TenantID) != tenantID || check.CreatedDate.Before(from) || !check.CreatedDate.Before(to) {
+			continue
+		}
+		res = append(res, &entities.CheckRecord{
+			ID:                  id,
+			UserID:              check.UserID,
+			Latitude:            check.Latitude,
+			Longitude:           check.Longitude,
+			IsDanger:            check.IsDanger,
+			DetectedIncidentIDs: check.DangerIds,
+			CreatedDate:         check.CreatedDate,
+		})
+	}
+	m.Mu.RUnlock()
+
+	slices.SortFunc(res, func(a, b *entities.CheckRecord) int {
+		return compareSortKeys(a.CreatedDate, a.ID, b.CreatedDate, b.ID)
+	})
+	for _, check := range res {
+		if err := fn(check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockDbRepository) ExportIncidentExposure(ctx context.Context, tenantID, incidentID string, fn func(*entities.ExposedUser) error, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	incident, ok := m.Storage[incidentID]
+	if !ok {
+		m.Mu.RUnlock()
+		return nil
+	}
+	incLat, _ := strconv.ParseFloat(incident.Latitude, 64)
+	incLon, _ := strconv.ParseFloat(incident.Longitude, 64)
+	users := map[string]*entities.ExposedUser{}
+	for _, check := range m.Checks {
+		if tenantOf(check.TenantID) != tenantID || !check.IsDanger || !slices.Contains(check.DangerIds, incidentID) {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(check.Latitude, 64)
+		lon, _ := strconv.ParseFloat(check.Longitude, 64)
+		distance := math.Round(haversine(lat, lon, incLat, incLon)*100) / 100
+		user, ok := users[check.UserID]
+		if !ok {
+			user = &entities.ExposedUser{UserID: check.UserID, FirstSeen: check.CreatedDate, MinDistance: math.MaxFloat64}
+			users[check.UserID] = user
+		}
+		user.Checks++
+		if check.CreatedDate.Before(user.FirstSeen) {
+			user.FirstSeen = check.CreatedDate
+		}
+		if check.CreatedDate.After(user.LastSeen) {
+			user.LastSeen = check.CreatedDate
+		}
+		if distance < user.MinDistance {
+			user.MinDistance, user.Latitude, user.Longitude = distance, check.Latitude, check.Longitude
+		}
+	}
+	m.Mu.RUnlock()
+
+	res := make([]*entities.ExposedUser, 0, len(users))
+	for _, user := range users {
+		res = append(res, user)
+	}
+	slices.SortFunc(res, func(a, b *entities.ExposedUser) int {
+		return compareSortKeys(a.FirstSeen, a.UserID, b.FirstSeen, b.UserID)
+	})
+	for _, user := range res {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchPaginationFilter(res *entities.ReadIncident, entit *entities.PaginationIncidents) bool {
+	if entit == nil {
+		return true
+	}
+	if entit.TenantID != "" && tenantOf(res.TenantID) != entit.TenantID {
+		return false
+	}
+	if entit.ID != "" && res.Id != entit.ID {
+		return false
+	}
+	if len(entit.IDs) != 0 && !slices.Contains(entit.IDs, res.Id) {
+		return false
+	}
+	if len(entit.Statuses) != 0 && !slices.Contains(entit.Statuses, res.Status) {
+		return false
+	}
+	if len(entit.Types) != 0 && !slices.Contains(entit.Types, res.Type) {
+		return false
+	}
+	if entit.Name != "" && res.Name != entit.Name {
+		return false
+	}
+	if entit.NameContains != "" && !strings.Contains(strings.ToLower(res.Name), strings.ToLower(entit.NameContains)) {
+		return false
+	}
+	if entit.Radius != nil && res.Radius != *entit.Radius {
+		return false
+	}
+	if entit.RadiusMin != nil && res.Radius < *entit.RadiusMin {
+		return false
+	}
+	if entit.RadiusMax != nil && res.Radius > *entit.RadiusMax {
+		return false
+	}
+	if entit.IsActive != nil && res.IsActive != *entit.IsActive {
+		return false
+	}
+	if !inTimeRange(&res.CreatedDate, entit.CreatedFrom, entit.CreatedTo) {
+		return false
+	}
+	updated := res.CreatedDate
+	if res.UpdatedDate != nil {
+		updated = *res.UpdatedDate
+	}
+	if !inTimeRange(&updated, entit.UpdatedFrom, entit.UpdatedTo) {
+		return false
+	}
+	if !inTimeRange(res.ResolvedDate, entit.ResolvedFrom, entit.ResolvedTo) {
+		return false
+	}
+	if entit.Near != nil {
+		lat, _ := strconv.ParseFloat(res.Latitude, 64)
+		lon, _ := strconv.ParseFloat(res.Longitude, 64)
+		if haversine(entit.Near.Latitude, entit.Near.Longitude, lat, lon) > float64(entit.Near.Meters) {
+			return false
+		}
+	}
+	return true
+}
+
+func inTimeRange(t, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	if to != nil && t.After(*to) {
+		return false
+	}
+	return true
+}
+
+// sortValue returns the value the postgres repository orders by for the given sort field.
+func sortValue(res *entities.ReadIncident, sort string) any {
+	switch sort {
+	case entities.IncidentSortUpdated:
+		if res.UpdatedDate != nil {
+			return *res.UpdatedDate
+		}
+		return res.CreatedDate
+	case entities.IncidentSortName:
+		return res.Name
+	case entities.IncidentSortRadius:
+		return res.Radius
+	case entities.IncidentSortStatus:
+		return res.Status
+	default:
+		return res.CreatedDate
+	}
+}
+
+func compareSortKeys(aValue any, aID string, bValue any, bID string) int {
+	cmp := 0
+	switch a := aValue.(type) {
+	case time.Time:
+		b, _ := bValue.(time.Time)
+		cmp = a.Compare(b)
+	case int:
+		b, _ := bValue.(int)
+		cmp = a - b
+	case string:
+		b, _ := bValue.(string)
+		cmp = strings.Compare(a, b)
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(aID, bID)
 }
 
-func (m *MockDbRepository) RegistrationCheck(ctx context.Context, userID, latitude, longitude string, exec Executor) (string, error) {
+func (m *MockDbRepository) RegistrationCheck(ctx context.Context, tenantID, userID, latitude, longitude string, exec Executor) (string, time.Time, error) {
 	if exec != nil {
 		m.InTx = true
 	}
 	id := uuid.NewString()
+	now := time.Now()
 
 	m.Mu.Lock()
 	defer m.Mu.Unlock()
 
 	m.Checks[id] = &Check{
+		TenantID:    tenantID,
 		UserID:      userID,
 		Latitude:    latitude,
 		Longitude:   longitude,
-		CreatedDate: time.Now(),
+		CreatedDate: now,
 	}
 
-	return id, nil
+	return id, now, nil
+}
+
+// roundCoordinate works like ROUND(value, decimals) of a DECIMAL column.
+func roundCoordinate(value string, decimals int) string {
+	f, _ := strconv.ParseFloat(value, 64)
+	return strconv.FormatFloat(f, 'f', decimals, 64)
 }
 
 func haversine(lat1, lon1, lat2, lon2 float64) float64 {
@@ -198,7 +755,7 @@ func haversine(lat1, lon1, lat2, lon2 float64) float64 {
 	return R * c
 }
 
-func (m *MockDbRepository) GetDetectedIncidents(ctx context.Context, longitude, latitude string, exec Executor) ([]*entities.DistanceCheck, error) {
+func (m *MockDbRepository) GetDetectedIncidents(ctx context.Context, tenantID, longitude, latitude string, exec Executor) ([]*entities.DistanceCheck, error) {
 	if exec != nil {
 		m.InTx = true
 	}
@@ -207,6 +764,9 @@ func (m *MockDbRepository) GetDetectedIncidents(ctx context.Context, longitude,
 	res := []*entities.DistanceCheck{}
 
 	for _, incident := range m.Storage {
+		if tenantOf(incident.TenantID) != tenantID {
+			continue
+		}
 		lat, _ := strconv.ParseFloat(latitude, 64)
 		lon, _ := strconv.ParseFloat(longitude, 64)
 		incLat, _ := strconv.ParseFloat(incident.Latitude, 64)
@@ -225,7 +785,7 @@ func (m *MockDbRepository) GetDetectedIncidents(ctx context.Context, longitude,
 	return res, nil
 }
 
-func (m *MockDbRepository) UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, isDanger bool, exec Executor) error {
+func (m *MockDbRepository) UpdateCheckByID(ctx context.Context, dangersIds []string, checkId string, createdDate time.Time, isDanger bool, exec Executor) error {
 	if exec != nil {
 		m.InTx = true
 	}
@@ -233,7 +793,7 @@ func (m *MockDbRepository) UpdateCheckByID(ctx context.Context, dangersIds []str
 	defer m.Mu.Unlock()
 
 	res, ok := m.Checks[checkId]
-	if !ok {
+	if !ok || !res.CreatedDate.Equal(createdDate) {
 		return sql.ErrNoRows
 	}
 
@@ -243,7 +803,7 @@ func (m *MockDbRepository) UpdateCheckByID(ctx context.Context, dangersIds []str
 	return nil
 }
 
-func (m *MockDbRepository) GetCountUniqueUsers(ctx context.Context, exec Executor) (int, error) {
+func (m *MockDbRepository) GetCountUniqueUsers(ctx context.Context, tenantID string, exec Executor) (int, error) {
 	if exec != nil {
 		m.InTx = true
 	}
@@ -252,33 +812,24 @@ func (m *MockDbRepository) GetCountUniqueUsers(ctx context.Context, exec Executo
 
 	userSet := make(map[string]struct{})
 	for _, check := range m.Checks {
+		if tenantOf(check.TenantID) != tenantID {
+			continue
+		}
 		userSet[check.UserID] = struct{}{}
 	}
 
 	return len(userSet), nil
 }
 
-func (m *MockDbRepository) getStatisticForIncidentById(id string, timeWindow int) *entities.IncidentStat {
-	inc := m.Storage[id]
-	res := &entities.IncidentStat{
-		ID:   id,
-		Name: inc.Name,
-		Type: inc.Type,
-	}
-	userCount := map[string]struct{}{}
-	for _, check := range m.Checks {
-		if slices.Contains(check.DangerIds, id) &&
-			check.CreatedDate.Add(-time.Duration(timeWindow)*time.Second).Compare(time.Now()) >= 0 {
-			userCount[check.UserID] = struct{}{}
-		}
+// RegistrationCheckRollup does nothing, the mock computes statistics from Checks.
+func (m *MockDbRepository) RegistrationCheckRollup(ctx context.Context, tenantID, userID string, incidentIDs []string, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
 	}
-
-	res.UserCount = len(userCount)
-
-	return res
+	return nil
 }
 
-func (m *MockDbRepository) GetStaticsForIncidentsWithTimeWindow(ctx context.Context, exec Executor, timeWindow int) ([]*entities.IncidentStat, error) {
+func (m *MockDbRepository) GetStatisticsForIncidents(ctx context.Context, filter *entities.IncidentStatsFilter, exec Executor) ([]*entities.IncidentStat, error) {
 	if exec != nil {
 		m.InTx = true
 	}
@@ -286,22 +837,161 @@ func (m *MockDbRepository) GetStaticsForIncidentsWithTimeWindow(ctx context.Cont
 	defer m.Mu.RUnlock()
 
 	result := []*entities.IncidentStat{}
-	for id := range m.Storage {
-		result = append(result, m.getStatisticForIncidentById(id, timeWindow))
+	for id, incident := range m.Storage {
+		if tenantOf(incident.TenantID) != filter.TenantID || incident.DeletedDate != nil ||
+			(!filter.IncludeInactive && !incident.IsActive) ||
+			(len(filter.Types) != 0 && !slices.Contains(filter.Types, incident.Type)) {
+			continue
+		}
+		stat := &entities.IncidentStat{
+			ID:     id,
+			Name:   incident.Name,
+			Type:   incident.Type,
+			Status: incident.Status,
+		}
+		users := map[string]struct{}{}
+		for _, check := range m.Checks {
+			if tenantOf(check.TenantID) != filter.TenantID || !check.IsDanger || !slices.Contains(check.DangerIds, id) ||
+				check.CreatedDate.Before(filter.From) || !check.CreatedDate.Before(filter.To) {
+				continue
+			}
+			users[check.UserID] = struct{}{}
+			stat.DangerousChecks++
+			if stat.FirstCheckDate.IsZero() || check.CreatedDate.Before(stat.FirstCheckDate) {
+				stat.FirstCheckDate = check.CreatedDate
+			}
+			if check.CreatedDate.After(stat.LastCheckDate) {
+				stat.LastCheckDate = check.CreatedDate
+			}
+		}
+		if stat.DangerousChecks == 0 {
+			continue
+		}
+		stat.UserCount = len(users)
+		result = append(result, stat)
 	}
+	slices.SortFunc(result, func(a, b *entities.IncidentStat) int {
+		if a.UserCount != b.UserCount {
+			return b.UserCount - a.UserCount
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
 	return result, nil
 }
 
-func (m *MockDbRepository) RegistrationIncident(ctx context.Context, entit *entities.RegistrationIncidentEntitie, exec Executor) (string, error) {
+func (m *MockDbRepository) GetChecksTimeSeries(ctx context.Context, filter *entities.ChecksTimeSeriesFilter, exec Executor) ([]*entities.ChecksBucket, error) {
 	if exec != nil {
 		m.InTx = true
 	}
-	uuid := uuid.NewString()
-	m.Mu.Lock()
-	defer m.Mu.Unlock()
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
 
-	m.Storage[uuid] = &entities.ReadIncident{
+	scoped := filter.IncidentID != "" || filter.Type != ""
+	scope := []string{}
+	for id, incident := range m.Storage {
+		if tenantOf(incident.TenantID) != filter.TenantID || incident.DeletedDate != nil ||
+			(filter.IncidentID != "" && id != filter.IncidentID) || (filter.Type != "" && incident.Type != filter.Type) {
+			continue
+		}
+		scope = append(scope, id)
+	}
+
+	buckets := map[time.Time]*entities.ChecksBucket{}
+	users := map[time.Time]map[string]struct{}{}
+	for _, check := range m.Checks {
+		if tenantOf(check.TenantID) != filter.TenantID || check.CreatedDate.Before(filter.From) || !check.CreatedDate.Before(filter.To) {
+			continue
+		}
+		dangerous := check.IsDanger
+		if scoped {
+			dangerous = dangerous && slices.ContainsFunc(scope, func(id string) bool {
+				return slices.Contains(check.DangerIds, id)
+			})
+			if !dangerous {
+				continue
+			}
+		}
+		start := filter.From.Add(check.CreatedDate.Sub(filter.From).Truncate(filter.Bucket)).UTC()
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &entities.ChecksBucket{Start: start}
+			buckets[start] = bucket
+			users[start] = map[string]struct{}{}
+		}
+		bucket.Checks++
+		if dangerous {
+			bucket.DangerousChecks++
+		}
+		users[start][check.UserID] = struct{}{}
+	}
+
+	result := []*entities.ChecksBucket{}
+	for start, bucket := range buckets {
+		bucket.UniqueUsers = len(users[start])
+		result = append(result, bucket)
+	}
+	slices.SortFunc(result, func(a, b *entities.ChecksBucket) int {
+		return a.Start.Compare(b.Start)
+	})
+	return result, nil
+}
+
+func (m *MockDbRepository) GetChecksHeatmap(ctx context.Context, filter *entities.HeatmapFilter, exec Executor) ([]*entities.HeatmapCell, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	cells := map[string]*entities.HeatmapCell{}
+	for _, check := range m.Checks {
+		if tenantOf(check.TenantID) != filter.TenantID || check.CreatedDate.Before(filter.From) || !check.CreatedDate.Before(filter.To) {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(check.Latitude, 64)
+		lon, _ := strconv.ParseFloat(check.Longitude, 64)
+		if box := filter.BBox; box != nil && (lat < box.MinLat || lat > box.MaxLat || lon < box.MinLon || lon > box.MaxLon) {
+			continue
+		}
+		hash := geohash.Encode(lat, lon, filter.Precision)
+		cell, ok := cells[hash]
+		if !ok {
+			cell = &entities.HeatmapCell{Geohash: hash}
+			cells[hash] = cell
+		}
+		cell.Checks++
+		if check.IsDanger {
+			cell.DangerousChecks++
+		}
+	}
+
+	result := []*entities.HeatmapCell{}
+	for _, cell := range cells {
+		if filter.DangerousOnly && cell.DangerousChecks == 0 {
+			continue
+		}
+		result = append(result, cell)
+	}
+	slices.SortFunc(result, func(a, b *entities.HeatmapCell) int {
+		return strings.Compare(a.Geohash, b.Geohash)
+	})
+	if filter.Limit != 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *MockDbRepository) RegistrationIncident(ctx context.Context, entit *entities.RegistrationIncidentEntitie, exec Executor) (string, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	uuid := uuid.NewString()
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	m.Storage[uuid] = &entities.ReadIncident{
 		Id:           uuid,
+		TenantID:     entit.TenantID,
 		Name:         entit.Name,
 		Type:         entit.Type,
 		Description:  entit.Description,
@@ -312,7 +1002,551 @@ func (m *MockDbRepository) RegistrationIncident(ctx context.Context, entit *enti
 		Status:       entit.Status,
 		ResolvedDate: entit.ResolvedTime,
 		CreatedDate:  time.Now().UTC(),
+		Version:      1,
 	}
 
 	return uuid, nil
 }
+
+func (m *MockDbRepository) RegistrationAPIKey(ctx context.Context, entit *entities.APIKey, exec Executor) (*entities.APIKey, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	for _, key := range m.APIKeys {
+		if key.KeyHash == entit.KeyHash {
+			return nil, fmt.Errorf("duplicate key value violates unique constraint")
+		}
+	}
+	key := *entit
+	key.ID = uuid.NewString()
+	key.CreatedDate = time.Now().UTC()
+	m.APIKeys[key.ID] = &key
+	res := key
+	return &res, nil
+}
+
+func (m *MockDbRepository) GetAPIKeyByHash(ctx context.Context, keyHash string, exec Executor) (*entities.APIKey, error) {
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+	for _, key := range m.APIKeys {
+		if key.KeyHash == keyHash {
+			res := *key
+			return &res, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockDbRepository) GetAPIKeyByIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.APIKey, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+	key, ok := m.APIKeys[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	res := *key
+	return &res, nil
+}
+
+func (m *MockDbRepository) GetAPIKeys(ctx context.Context, tenantID string, exec Executor) ([]*entities.APIKey, error) {
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+	res := []*entities.APIKey{}
+	for _, key := range m.APIKeys {
+		if tenantOf(key.TenantID) != tenantID {
+			continue
+		}
+		copyKey := *key
+		res = append(res, &copyKey)
+	}
+	slices.SortFunc(res, func(a, b *entities.APIKey) int {
+		if cmp := b.CreatedDate.Compare(a.CreatedDate); cmp != 0 {
+			return cmp
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return res, nil
+}
+
+func (m *MockDbRepository) UpdateAPIKeyExpiry(ctx context.Context, id string, expiresAt *time.Time, exec Executor) error {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	if key, ok := m.APIKeys[id]; ok {
+		key.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (m *MockDbRepository) RevokeAPIKey(ctx context.Context, id string, exec Executor) error {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	if key, ok := m.APIKeys[id]; ok && key.RevokedDate == nil {
+		key.RevokedDate = getTimePtr(time.Now().UTC())
+	}
+	return nil
+}
+
+func (m *MockDbRepository) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time, exec Executor) error {
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	if key, ok := m.APIKeys[id]; ok {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+// FindCheckClusters joins checks closer than the radius into one cluster, it is simpler than DBSCAN
+// but gives the same clusters for dense groups of checks.
+func (m *MockDbRepository) FindCheckClusters(ctx context.Context, params *entities.HotspotParams, exec Executor) ([]*entities.Hotspot, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	type point struct {
+		check    *Check
+		lat, lon float64
+	}
+	points := []*point{}
+	for _, check := range m.Checks {
+		if check.IsDanger || check.CreatedDate.Before(params.Since) {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(check.Latitude, 64)
+		lon, _ := strconv.ParseFloat(check.Longitude, 64)
+		points = append(points, &point{check: check, lat: lat, lon: lon})
+	}
+
+	result := []*entities.Hotspot{}
+	visited := make([]bool, len(points))
+	for i := range points {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		cluster := []*point{points[i]}
+		for next := 0; next < len(cluster); next++ {
+			for j, p := range points {
+				if !visited[j] && tenantOf(p.check.TenantID) == tenantOf(cluster[next].check.TenantID) &&
+					haversine(cluster[next].lat, cluster[next].lon, p.lat, p.lon) <= float64(params.RadiusMeters) {
+					visited[j] = true
+					cluster = append(cluster, p)
+				}
+			}
+		}
+
+		users := map[string]struct{}{}
+		res := &entities.Hotspot{TenantID: tenantOf(points[i].check.TenantID), FirstCheckDate: cluster[0].check.CreatedDate}
+		var lat, lon float64
+		for _, p := range cluster {
+			users[p.check.UserID] = struct{}{}
+			lat += p.lat / float64(len(cluster))
+			lon += p.lon / float64(len(cluster))
+			if p.check.CreatedDate.Before(res.FirstCheckDate) {
+				res.FirstCheckDate = p.check.CreatedDate
+			}
+			if p.check.CreatedDate.After(res.LastCheckDate) {
+				res.LastCheckDate = p.check.CreatedDate
+			}
+		}
+		if len(cluster) < params.MinChecks || len(users) < params.MinUsers {
+			continue
+		}
+		res.Latitude = strconv.FormatFloat(lat, 'f', 8, 64)
+		res.Longitude = strconv.FormatFloat(lon, 'f', 8, 64)
+		res.Checks = len(cluster)
+		res.UniqueUsers = len(users)
+		res.Radius = 1
+		for _, p := range cluster {
+			res.Radius = max(res.Radius, int(math.Ceil(haversine(lat, lon, p.lat, p.lon))))
+		}
+		result = append(result, res)
+	}
+	return result, nil
+}
+
+func (m *MockDbRepository) GetNearbyHotspotForUpdate(ctx context.Context, tenantID, latitude, longitude string, distance int, seenSince time.Time, exec Executor) (*entities.Hotspot, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	lat, _ := strconv.ParseFloat(latitude, 64)
+	lon, _ := strconv.ParseFloat(longitude, 64)
+	var res *entities.Hotspot
+	best := math.MaxFloat64
+	for _, hotspot := range m.Hotspots {
+		hLat, _ := strconv.ParseFloat(hotspot.Latitude, 64)
+		hLon, _ := strconv.ParseFloat(hotspot.Longitude, 64)
+		dist := haversine(lat, lon, hLat, hLon)
+		if hotspot.TenantID != tenantID || hotspot.LastCheckDate.Before(seenSince) ||
+			dist > float64(hotspot.Radius+distance) || dist >= best {
+			continue
+		}
+		res, best = hotspot, dist
+	}
+	if res == nil {
+		return nil, sql.ErrNoRows
+	}
+	copied := *res
+	return &copied, nil
+}
+
+func (m *MockDbRepository) GetHotspotByIDForUpdate(ctx context.Context, id string, exec Executor) (*entities.Hotspot, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	res, ok := m.Hotspots[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *res
+	return &copied, nil
+}
+
+func (m *MockDbRepository) RegistrationHotspot(ctx context.Context, entit *entities.Hotspot, exec Executor) (string, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	res := *entit
+	res.ID = uuid.NewString()
+	res.Status = "candidate"
+	res.DetectedDate = time.Now().UTC()
+	res.UpdatedDate = res.DetectedDate
+	m.Hotspots[res.ID] = &res
+	return res.ID, nil
+}
+
+func (m *MockDbRepository) UpdateHotspotCluster(ctx context.Context, id string, entit *entities.Hotspot, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	res, ok := m.Hotspots[id]
+	if !ok {
+		return nil
+	}
+	res.Latitude, res.Longitude, res.Radius = entit.Latitude, entit.Longitude, entit.Radius
+	res.Checks, res.UniqueUsers = entit.Checks, entit.UniqueUsers
+	if entit.FirstCheckDate.Before(res.FirstCheckDate) {
+		res.FirstCheckDate = entit.FirstCheckDate
+	}
+	if entit.LastCheckDate.After(res.LastCheckDate) {
+		res.LastCheckDate = entit.LastCheckDate
+	}
+	res.UpdatedDate = time.Now().UTC()
+	return nil
+}
+
+func (m *MockDbRepository) UpdateHotspotStatus(ctx context.Context, id, status string, incidentID *string, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	if res, ok := m.Hotspots[id]; ok {
+		res.Status, res.IncidentID = status, incidentID
+		res.UpdatedDate = time.Now().UTC()
+	}
+	return nil
+}
+
+func (m *MockDbRepository) GetHotspots(ctx context.Context, tenantID, status string, exec Executor) ([]*entities.Hotspot, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	result := []*entities.Hotspot{}
+	for _, hotspot := range m.Hotspots {
+		if hotspot.TenantID == tenantID && hotspot.Status == status {
+			copied := *hotspot
+			result = append(result, &copied)
+		}
+	}
+	slices.SortFunc(result, func(a, b *entities.Hotspot) int {
+		if a.UniqueUsers != b.UniqueUsers {
+			return b.UniqueUsers - a.UniqueUsers
+		}
+		return b.LastCheckDate.Compare(a.LastCheckDate)
+	})
+	return result, nil
+}
+
+func (m *MockDbRepository) GetUserChecks(ctx context.Context, filter *entities.UserChecksFilter, exec Executor) ([]*entities.CheckRecord, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	res := []*entities.CheckRecord{}
+	for id, check := range m.Checks {
+		if tenantOf(check.TenantID) != filter.TenantID || check.UserID != filter.UserID ||
+			(filter.DangerousOnly && !check.IsDanger) ||
+			(filter.From != nil && check.CreatedDate.Before(*filter.From)) ||
+			(filter.To != nil && check.CreatedDate.After(*filter.To)) {
+			continue
+		}
+		res = append(res, &entities.CheckRecord{
+			ID:                  id,
+			UserID:              check.UserID,
+			Latitude:            check.Latitude,
+			Longitude:           check.Longitude,
+			IsDanger:            check.IsDanger,
+			DetectedIncidentIDs: check.DangerIds,
+			CreatedDate:         check.CreatedDate,
+		})
+	}
+	m.Mu.RUnlock()
+
+	slices.SortFunc(res, func(a, b *entities.CheckRecord) int {
+		return compareSortKeys(b.CreatedDate, a.ID, a.CreatedDate, b.ID)
+	})
+	if filter.Offset >= len(res) {
+		return []*entities.CheckRecord{}, nil
+	}
+	res = res[filter.Offset:]
+	if filter.Limit != 0 && filter.Limit < len(res) {
+		res = res[:filter.Limit]
+	}
+	return res, nil
+}
+
+func (m *MockDbRepository) DeleteUserChecks(ctx context.Context, tenantID, userID string, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	count := int64(0)
+	for _, checks := range []map[string]*Check{m.Checks, m.ArchivedChecks} {
+		for id, check := range checks {
+			if tenantOf(check.TenantID) == tenantID && check.UserID == userID {
+				delete(checks, id)
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (m *MockDbRepository) AnonymizeUserChecks(ctx context.Context, entit *entities.UserAnonymization, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	count := int64(0)
+	for _, checks := range []map[string]*Check{m.Checks, m.ArchivedChecks} {
+		for _, check := range checks {
+			if tenantOf(check.TenantID) == entit.TenantID && check.UserID == entit.UserID {
+				check.UserID = entit.CheckPrefix + uuid.NewString()
+				check.Latitude = roundCoordinate(check.Latitude, entit.Decimals)
+				check.Longitude = roundCoordinate(check.Longitude, entit.Decimals)
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (m *MockDbRepository) GetChecksPartitions(ctx context.Context, exec Executor) ([]*entities.ChecksPartition, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	result := []*entities.ChecksPartition{}
+	for _, partition := range m.Partitions {
+		res := *partition
+		result = append(result, &res)
+	}
+	slices.SortFunc(result, func(a, b *entities.ChecksPartition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result, nil
+}
+
+func (m *MockDbRepository) CreateChecksPartition(ctx context.Context, name string, from, to time.Time, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	if _, ok := m.Partitions[name]; ok {
+		return fmt.Errorf("relation %q already exists", name)
+	}
+	m.Partitions[name] = &entities.ChecksPartition{Name: name, From: from, To: to}
+	return nil
+}
+
+func (m *MockDbRepository) DropChecksPartition(ctx context.Context, name string, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	return m.removePartition(name, false)
+}
+
+func (m *MockDbRepository) ArchiveChecksPartition(ctx context.Context, name string, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	if err := m.removePartition(name, true); err != nil {
+		return err
+	}
+	m.Archived = append(m.Archived, name)
+	return nil
+}
+
+func (m *MockDbRepository) removePartition(name string, archive bool) error {
+	partition, ok := m.Partitions[name]
+	if !ok {
+		return fmt.Errorf("table %q does not exist", name)
+	}
+	for id, check := range m.Checks {
+		if !check.CreatedDate.Before(partition.From) && check.CreatedDate.Before(partition.To) {
+			if archive {
+				m.ArchivedChecks[id] = check
+			}
+			delete(m.Checks, id)
+		}
+	}
+	delete(m.Partitions, name)
+	return nil
+}
+
+func (m *MockDbRepository) PurgeDefaultChecksPartition(ctx context.Context, before time.Time, archive bool, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	count := int64(0)
+	for id, check := range m.Checks {
+		if !check.CreatedDate.Before(before) || m.inPartition(check.CreatedDate) {
+			continue
+		}
+		if archive {
+			m.ArchivedChecks[id] = check
+		}
+		delete(m.Checks, id)
+		count++
+	}
+	return count, nil
+}
+
+func (m *MockDbRepository) inPartition(date time.Time) bool {
+	for _, partition := range m.Partitions {
+		if !date.Before(partition.From) && date.Before(partition.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteRollupUsersBefore does nothing, the mock computes statistics from checks.
+func (m *MockDbRepository) DeleteRollupUsersBefore(ctx context.Context, before time.Time, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	return 0, nil
+}
+
+func (m *MockDbRepository) CoarsenChecks(ctx context.Context, from, before time.Time, decimals, limit int, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	return coarsenChecks(m.Checks, from, before, decimals, limit), nil
+}
+
+func (m *MockDbRepository) CoarsenArchivedChecks(ctx context.Context, from, before time.Time, decimals int, exec Executor) (int64, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+	return coarsenChecks(m.ArchivedChecks, from, before, decimals, -1), nil
+}
+
+// coarsenChecks rounds at most limit checks, a negative limit rounds all of them.
+func coarsenChecks(checks map[string]*Check, from, before time.Time, decimals, limit int) int64 {
+	// round also reports whether value changes after rounding, like latitude <> ROUND(latitude, n)
+	round := func(value string) (string, bool) {
+		res := roundCoordinate(value, decimals)
+		f, _ := strconv.ParseFloat(value, 64)
+		r, _ := strconv.ParseFloat(res, 64)
+		return res, r != f
+	}
+	count := int64(0)
+	for _, check := range checks {
+		if count == int64(limit) {
+			break
+		}
+		if check.CreatedDate.Before(from) || !check.CreatedDate.Before(before) {
+			continue
+		}
+		lat, latChanged := round(check.Latitude)
+		lon, lonChanged := round(check.Longitude)
+		if !latChanged && !lonChanged {
+			continue
+		}
+		check.Latitude, check.Longitude = lat, lon
+		count++
+	}
+	return count
+}
+
+func (m *MockDbRepository) GetChecksCoarsening(ctx context.Context, exec Executor) (*entities.ChecksCoarsening, error) {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.RLock()
+	defer m.Mu.RUnlock()
+
+	if m.Coarsening == nil {
+		return nil, sql.ErrNoRows
+	}
+	res := *m.Coarsening
+	return &res, nil
+}
+
+func (m *MockDbRepository) SaveChecksCoarsening(ctx context.Context, entit *entities.ChecksCoarsening, exec Executor) error {
+	if exec != nil {
+		m.InTx = true
+	}
+	m.Mu.Lock()
+	defer m.Mu.Unlock()
+
+	res := *entit
+	m.Coarsening = &res
+	return nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStoreMock keeps used nonces forever, Err makes every call fail.
+type NonceStoreMock struct {
+	Used map[string]struct{}
+	Err  error
+	mu   sync.Mutex
+}
+
+func NewNonceStoreMock() *NonceStoreMock {
+	return &NonceStoreMock{
+		Used: make(map[string]struct{}),
+	}
+}
+
+func (ns *NonceStoreMock) UseNonce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	if ns.Err != nil {
+		return false, ns.Err
+	}
+	if _, ok := ns.Used[key]; ok {
+		return false, nil
+	}
+	ns.Used[key] = struct{}{}
+	return true, nil
+}
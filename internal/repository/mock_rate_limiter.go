@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiterMock counts taken tokens without refill, Err makes every call fail.
+type RateLimiterMock struct {
+	Taken map[string]int
+	Err   error
+	mu    sync.Mutex
+}
+
+func NewRateLimiterMock() *RateLimiterMock {
+	return &RateLimiterMock{
+		Taken: make(map[string]int),
+	}
+}
+
+func (rl *RateLimiterMock) Take(ctx context.Context, key string, capacity int, period time.Duration) (bool, time.Duration, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.Err != nil {
+		return false, 0, rl.Err
+	}
+	if rl.Taken[key] >= capacity {
+		return false, period / time.Duration(capacity), nil
+	}
+	rl.Taken[key]++
+	return true, 0, nil
+}
@@ -9,9 +9,13 @@ type FakeTx struct {
 	Committed  bool
 	RolledBack bool
 	Closed     bool
+	CommitErr  error
 }
 
 func (f *FakeTx) Commit() error {
+	if f.CommitErr != nil {
+		return f.CommitErr
+	}
 	f.Committed = true
 	return nil
 }
@@ -0,0 +1,27 @@
+package nonce
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const KeyPrefix = "nonce:"
+
+type RedisNonceStore struct {
+	client *redis.Client
+}
+
+func NewRedisNonceStore(client *redis.Client) *RedisNonceStore {
+	return &RedisNonceStore{client: client}
+}
+
+func (ns *RedisNonceStore) UseNonce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := ns.client.SetNX(ctx, KeyPrefix+key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis SETNX failed: %w", err)
+	}
+	return ok, nil
+}
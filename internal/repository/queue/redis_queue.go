@@ -63,6 +63,31 @@ func (rq *RedisQueue) PopFromQueue(ctx context.Context) (*dto.WebhookTask, bool,
 	return task, true, nil
 }
 
+// RemoveUserTasks reads the queue and removes tasks of the user one by one with LREM,
+// a task popped by the webhook manager in between is simply not found.
+func (rq *RedisQueue) RemoveUserTasks(ctx context.Context, tenant, userID string) (int, error) {
+	items, err := rq.client.LRange(ctx, KeyQueue, 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("lrange failed: %s", err.Error())
+	}
+	removed := 0
+	for _, item := range items {
+		var task *dto.WebhookTask
+		if err := json.Unmarshal([]byte(item), &task); err != nil {
+			continue
+		}
+		if task.Tenant != tenant || task.Dto.UserID != userID {
+			continue
+		}
+		count, err := rq.client.LRem(ctx, KeyQueue, 1, item).Result()
+		if err != nil {
+			return removed, fmt.Errorf("lrem failed: %s", err.Error())
+		}
+		removed += int(count)
+	}
+	return removed, nil
+}
+
 func (rq *RedisQueue) PingWithCtx(ctx context.Context) error {
 	return rq.client.Ping(ctx).Err()
 }
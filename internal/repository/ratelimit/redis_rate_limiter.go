@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const KeyPrefix = "ratelimit:"
+
+// tokenBucket refills the bucket by the time passed since the last call and takes one token.
+// Redis time is used so that replicas with skewed clocks share the same buckets.
+// Returns {1, 0} when the token is taken and {0, ms to wait} otherwise.
+var tokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+local rate = capacity / period
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, wait}
+`)
+
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+func (rl *RedisRateLimiter) Take(ctx context.Context, key string, capacity int, period time.Duration) (bool, time.Duration, error) {
+	res, err := tokenBucket.Run(ctx, rl.client, []string{KeyPrefix + key}, capacity, period.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("redis rate limit failed: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("redis rate limit failed: unexpected reply %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,75 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+type Task struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Worker periodically runs retention tasks (trash purge and similar cleanups).
+type Worker struct {
+	interval time.Duration
+	tasks    []Task
+	logger   *log.Logger
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func NewWorker(interval time.Duration, ctx context.Context, tasks ...Task) (*Worker, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval cannot be <= 0")
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("tasks cannot be empty")
+	}
+	for _, task := range tasks {
+		if task.Run == nil {
+			return nil, fmt.Errorf("task %s: run func cannot be nil", task.Name)
+		}
+	}
+	ctxRes, cancel := context.WithCancel(ctx)
+	w := &Worker{
+		interval: interval,
+		tasks:    tasks,
+		logger:   log.New(os.Stderr, "[RETENTION WORKER]  ", log.Ldate|log.Ltime),
+		ctx:      ctxRes,
+		cancel:   cancel,
+	}
+	go w.StartProcessing()
+	return w, nil
+}
+
+func (w *Worker) Stop() {
+	w.cancel()
+}
+
+func (w *Worker) StartProcessing() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.runTasks()
+	for {
+		select {
+		case <-w.ctx.Done():
+			w.logger.Println("CONTEX CANCEL, FINISH WORK")
+			return
+		case <-ticker.C:
+			w.runTasks()
+		}
+	}
+}
+
+func (w *Worker) runTasks() {
+	for _, task := range w.tasks {
+		if err := task.Run(w.ctx); err != nil {
+			w.logger.Printf("error in task %s: %s\n", task.Name, err.Error())
+		}
+	}
+}
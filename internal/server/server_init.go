@@ -6,18 +6,22 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/config"
 	"github.com/Piccadilly98/incidents_service/internal/error_worker"
 	"github.com/Piccadilly98/incidents_service/internal/handlers"
 	"github.com/Piccadilly98/incidents_service/internal/health"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/jwtauth"
 	"github.com/Piccadilly98/incidents_service/internal/middleware"
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
 	"github.com/Piccadilly98/incidents_service/internal/repository/cache"
 	"github.com/Piccadilly98/incidents_service/internal/repository/db"
+	"github.com/Piccadilly98/incidents_service/internal/repository/nonce"
 	"github.com/Piccadilly98/incidents_service/internal/repository/queue"
+	"github.com/Piccadilly98/incidents_service/internal/repository/ratelimit"
+	"github.com/Piccadilly98/incidents_service/internal/retention"
 	"github.com/Piccadilly98/incidents_service/internal/service"
 	"github.com/Piccadilly98/incidents_service/internal/webhook_manager"
 	"github.com/go-chi/chi/v5"
@@ -77,6 +81,42 @@ func ServerStart() (chan error, error) {
 	if err != nil {
 		return nil, err
 	}
+	search, err := handlers.NewSearchHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	importHandler, err := handlers.NewImportHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	bulk, err := handlers.NewBulkHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	incidentsExport, err := handlers.NewIncidentsExportHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	checksExport, err := handlers.NewChecksExportHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	heatmap, err := handlers.NewChecksHeatmapHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	exposure, err := handlers.NewIncidentExposureHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	userChecks, err := handlers.NewUserChecksHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	forgetUser, err := handlers.NewForgetUserHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
 	lockCheck, err := handlers.NewLocationCheckHandler(service, ew)
 	if err != nil {
 		return nil, err
@@ -85,15 +125,118 @@ func ServerStart() (chan error, error) {
 	if err != nil {
 		return nil, err
 	}
-	apiKey := os.Getenv("API_KEY")
-	if apiKey == "" {
-		log.Fatal("API_KEY not set in .env")
+	timeSeries, err := handlers.NewStatsTimeSeriesHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	history, err := handlers.NewHistoryHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := handlers.NewAuditHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := handlers.NewPatchHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	restore, err := handlers.NewRestoreHandler(service, ew)
+	if err != nil {
+		return nil, err
 	}
-	fmt.Printf("\n\n\nAPI Key (для админских эндпоинтов): %-36s \n", apiKey)
-	fmt.Printf("Используй в заголовке: X-API-Key: %s \n\n\n", apiKey)
-	mid := middleware.CheckMiddleware(apiKey)
+	keyIssue, err := handlers.NewAPIKeyIssueHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	keyList, err := handlers.NewAPIKeyListHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	keyRotate, err := handlers.NewAPIKeyRotateHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	keyRevoke, err := handlers.NewAPIKeyRevokeHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	hotspotList, err := handlers.NewHotspotListHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	hotspotPromote, err := handlers.NewHotspotPromoteHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	hotspotDismiss, err := handlers.NewHotspotDismissHandler(service, ew)
+	if err != nil {
+		return nil, err
+	}
+	_, err = retention.NewWorker(time.Duration(cfg.RetentionIntervalMinutes)*time.Minute, context.Background(),
+		retention.Task{
+			Name: "purge incidents trash",
+			Run: func(ctx context.Context) error {
+				_, err := service.PurgeDeletedIncidents(ctx)
+				return err
+			},
+		},
+		exclusiveTask(service, "create checks partitions", func(ctx context.Context) error {
+			_, err := service.EnsureChecksPartitions(ctx)
+			return err
+		}),
+		exclusiveTask(service, "checks retention", func(ctx context.Context) error {
+			_, err := service.ApplyChecksRetention(ctx)
+			return err
+		}),
+		exclusiveTask(service, "coarsen checks", func(ctx context.Context) error {
+			_, err := service.CoarsenChecks(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = retention.NewWorker(time.Duration(cfg.HotspotIntervalMinutes)*time.Minute, context.Background(),
+		// a hotspot that does not exist yet cannot be locked, detection on two replicas would save it twice
+		exclusiveTask(service, "detect hotspots", func(ctx context.Context) error {
+			_, err := service.DetectHotspots(ctx)
+			return err
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BootstrapAPIKey == "" {
+		log.Printf("%s not set, admin endpoints accept only issued keys", config.EnvNameBootstrapAPIKey)
+	}
+	err = service.BootstrapAPIKey(context.Background(), cfg.BootstrapAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	var bearer middleware.BearerAuthenticator
+	if cfg.JWTJWKS != "" {
+		verifier, err := jwtauth.NewVerifier(verifierConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+		bearer = verifier
+	}
+	mid := middleware.CheckMiddleware(service, bearer)
+	limiter := ratelimit.NewRedisRateLimiter(redisClient)
+	if cfg.RequireSignedChecks {
+		log.Printf("location checks require an app signature, %d signing keys", len(cfg.AppSigningKeys))
+	}
+	signed := middleware.SignatureMiddleware(cfg.AppSigningKeys, nonce.NewRedisNonceStore(redisClient), cfg.RequireSignedChecks,
+		time.Duration(cfg.SignatureMaxSkewSeconds)*time.Second)
+	r.Use(middleware.RequestIDMiddleware)
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/location/check", lockCheck.Handler)
+		r.With(
+			middleware.TenantMiddleware,
+			middleware.RateLimitMiddleware(limiter, config.RateLimitRouteLocationCheck, cfg.RateLimits[config.RateLimitRouteLocationCheck], cfg.RateLimitTrustForwarded),
+			signed,
+			middleware.UserRateLimitMiddleware(limiter, config.RateLimitRouteLocationCheck, cfg.RateLimits[config.RateLimitRouteLocationCheck]),
+		).Post("/location/check", lockCheck.Handler)
 		r.Get("/system/health", healthHandler.Handler)
 		r.Post("/test", func(w http.ResponseWriter, r *http.Request) {
 			v := dto.ResultWebhookRequestDTO{}
@@ -108,12 +251,53 @@ func ServerStart() (chan error, error) {
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mid)
-			r.Get("/incidents/stats", staticHandler.Handler)
-			r.Delete("/incidents/{id}", del.Handler)
-			r.Post("/incidents", regHandler.Handler)
-			r.Put("/incidents/{id}", updateHandler.Handler)
-			r.Get("/incidents/{id}", get.Handler)
-			r.Get("/incidents", pagination.Handler)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(identity.PermStatsRead))
+				r.Get("/incidents/stats", staticHandler.Handler)
+				r.Get("/incidents/stats/timeseries", timeSeries.Handler)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(identity.PermChecksExport))
+				r.Get("/checks/export", checksExport.Handler)
+				r.Get("/checks/heatmap", heatmap.Handler)
+				r.Get("/incidents/{id}/exposure", exposure.Handler)
+				r.Get("/users/{user_id}/checks", userChecks.Handler)
+			})
+			r.With(middleware.RequirePermission(identity.PermChecksForget)).Delete("/users/{user_id}/checks", forgetUser.Handler)
+			r.With(middleware.RequirePermission(identity.PermAuditRead)).Get("/audit", audit.Handler)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(identity.PermIncidentsRead))
+				r.Get("/incidents/{id}", get.Handler)
+				r.Get("/incidents/{id}/history", history.Handler)
+				r.Get("/incidents", pagination.Handler)
+				r.Get("/incidents/search", search.Handler)
+				r.Get("/incidents/export", incidentsExport.Handler)
+				r.Get("/hotspots", hotspotList.Handler)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(identity.PermIncidentsCreate))
+				r.Post("/incidents", regHandler.Handler)
+				r.Post("/incidents/import", importHandler.Handler)
+				r.Post("/hotspots/{id}/promote", hotspotPromote.Handler)
+			})
+			r.Group(func(r chi.Router) {
+				// archived incidents and bulk delete are checked again by the service
+				r.Use(middleware.RequirePermission(identity.PermIncidentsUpdate))
+				r.Post("/incidents/bulk", bulk.Handler)
+				r.Put("/incidents/{id}", updateHandler.Handler)
+				r.Patch("/incidents/{id}", patch.Handler)
+				r.Post("/hotspots/{id}/dismiss", hotspotDismiss.Handler)
+			})
+			// force delete needs incidents.force_delete, checked by the service
+			r.With(middleware.RequirePermission(identity.PermIncidentsArchive)).Delete("/incidents/{id}", del.Handler)
+			r.With(middleware.RequirePermission(identity.PermIncidentsRestore)).Post("/incidents/{id}/restore", restore.Handler)
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequirePermission(identity.PermKeysManage))
+				r.Post("/keys", keyIssue.Handler)
+				r.Get("/keys", keyList.Handler)
+				r.Post("/keys/{id}/rotate", keyRotate.Handler)
+				r.Delete("/keys/{id}", keyRevoke.Handler)
+			})
 		})
 	})
 	errCh := make(chan error)
@@ -127,3 +311,28 @@ func ServerStart() (chan error, error) {
 	}()
 	return errCh, nil
 }
+
+// verifierConfig passes the JWT settings of cfg to the bearer token verifier.
+func verifierConfig(cfg *config.Config) jwtauth.Config {
+	return jwtauth.Config{
+		JWKS:        cfg.JWTJWKS,
+		Issuer:      cfg.JWTIssuer,
+		Audience:    cfg.JWTAudience,
+		RolesClaim:  cfg.JWTRolesClaim,
+		RoleScopes:  cfg.JWTRoleScopes,
+		Roles:       cfg.JWTRoles,
+		TenantClaim: cfg.JWTTenantClaim,
+		Refresh:     time.Duration(cfg.JWTJWKSRefreshMinutes) * time.Minute,
+		Leeway:      jwtauth.DefaultLeeway,
+	}
+}
+
+// exclusiveTask runs the task on one replica at a time, see Service.RunExclusive.
+func exclusiveTask(serv *service.Service, name string, run func(ctx context.Context) error) retention.Task {
+	return retention.Task{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			return serv.RunExclusive(ctx, name, run)
+		},
+	}
+}
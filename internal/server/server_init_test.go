@@ -0,0 +1,41 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+)
+
+func TestVerifierConfig(t *testing.T) {
+	t.Setenv(config.EnvNameDbName, "testdb")
+	t.Setenv(config.EnvNameDbUser, "user")
+	t.Setenv(config.EnvNameDbPassword, "pass")
+	t.Setenv(config.EnvNameJWTJWKS, "jwks.json")
+	t.Setenv(config.EnvNameJWTIssuer, "https://idp.example.com")
+	t.Setenv(config.EnvNameJWTAudience, "incidents")
+	t.Setenv(config.EnvNameJWTRoleScopes, "dispatcher=incidents:read")
+	t.Setenv(config.EnvNameJWTRoles, "dispatcher=operator;ops-admin=admin")
+
+	cfg, err := config.NewConfig(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	got := verifierConfig(cfg)
+
+	expectedRoles := map[string]string{"dispatcher": "operator", "ops-admin": "admin"}
+	if !reflect.DeepEqual(got.Roles, expectedRoles) {
+		t.Errorf("roles: got: %v, expect: %v\n", got.Roles, expectedRoles)
+	}
+	expectedScopes := map[string][]string{"dispatcher": {"incidents:read"}}
+	if !reflect.DeepEqual(got.RoleScopes, expectedScopes) {
+		t.Errorf("role scopes: got: %v, expect: %v\n", got.RoleScopes, expectedScopes)
+	}
+	if got.JWKS != "jwks.json" || got.Issuer != "https://idp.example.com" || got.Audience != "incidents" {
+		t.Errorf("provider settings are not passed: %+v\n", got)
+	}
+	if got.Refresh != time.Duration(config.DefaultJWTJWKSRefreshMinutes)*time.Minute {
+		t.Errorf("refresh: got: %v\n", got.Refresh)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+const (
+	apiKeyPrefix = "isk_"
+	// apiKeyLastUsedInterval limits last_used_at writes to one per key per interval
+	apiKeyLastUsedInterval = time.Minute
+
+	BootstrapAPIKeyName   = "bootstrap"
+	MaxAPIKeyGraceMinutes = 7 * 24 * 60
+)
+
+// ErrInvalidAPIKey is returned for unknown, revoked and expired keys alike,
+// so the caller cannot tell which keys exist.
+var ErrInvalidAPIKey = errors.New("invalid api-key")
+
+func (s *Service) IssueAPIKey(ctx context.Context, req *dto.APIKeyIssueRequest) (*dto.APIKeyIssuedResponse, error) {
+	if err := s.authorize(ctx, identity.PermKeysManage); err != nil {
+		return nil, err
+	}
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !identity.IsKnownRole(req.Role) {
+		return nil, fmt.Errorf("invalid role %q: must be one of %v", req.Role, identity.Roles)
+	}
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	expiresAt := toUTC(req.ExpiresAt)
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("expires_at cannot be in the past")
+	}
+	tenant, err := s.apiKeyTenant(ctx, req.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.issueAPIKey(ctx, req.Name, req.Role, tenant, scopes, expiresAt, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.authLogger.Printf("INFO: api key %s (%s) for tenant %s issued by %s", res.Prefix, res.Name, tenant, identity.Actor(ctx))
+	return res, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]*dto.APIKeyResponse, error) {
+	if err := s.authorize(ctx, identity.PermKeysManage); err != nil {
+		return nil, err
+	}
+	keys, err := s.db.GetAPIKeys(ctx, identity.Tenant(ctx), nil)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	res := make([]*dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, dto.ToAPIKeyResponse(key, now))
+	}
+	return res, nil
+}
+
+// RotateAPIKey issues a new key with the same name, role, tenant, scopes and expiry.
+// The old key is revoked, or expires after the grace period when one is requested.
+func (s *Service) RotateAPIKey(ctx context.Context, id string, req *dto.APIKeyRotateRequest) (*dto.APIKeyIssuedResponse, error) {
+	if err := s.authorize(ctx, identity.PermKeysManage); err != nil {
+		return nil, err
+	}
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if req.GraceMinutes != nil && *req.GraceMinutes > MaxAPIKeyGraceMinutes {
+		return nil, fmt.Errorf("grace_minutes cannot be > %d", MaxAPIKeyGraceMinutes)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	old, err := s.db.GetAPIKeyByIDForUpdate(ctx, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if old.TenantID != identity.Tenant(ctx) {
+		return nil, sql.ErrNoRows
+	}
+	now := time.Now().UTC()
+	if old.RevokedDate != nil {
+		return nil, fmt.Errorf("api key already revoked")
+	}
+	if old.ExpiresAt != nil && !old.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("api key already expired")
+	}
+
+	res, err := s.issueAPIKey(ctx, old.Name, old.Role, old.TenantID, old.Scopes, old.ExpiresAt, tx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GraceMinutes == nil || *req.GraceMinutes == 0 {
+		err = s.db.RevokeAPIKey(ctx, id, tx)
+	} else {
+		graceEnd := now.Add(time.Duration(*req.GraceMinutes) * time.Minute)
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(graceEnd) {
+			graceEnd = *old.ExpiresAt
+		}
+		err = s.db.UpdateAPIKeyExpiry(ctx, id, &graceEnd, tx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.authLogger.Printf("INFO: api key %s rotated to %s by %s", old.Prefix, res.Prefix, identity.Actor(ctx))
+	return res, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) error {
+	if err := s.authorize(ctx, identity.PermKeysManage); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	key, err := s.db.GetAPIKeyByIDForUpdate(ctx, id, tx)
+	if err != nil {
+		return err
+	}
+	if key.TenantID != identity.Tenant(ctx) {
+		return sql.ErrNoRows
+	}
+	if key.RevokedDate != nil {
+		return fmt.Errorf("api key already revoked")
+	}
+	if err = s.db.RevokeAPIKey(ctx, id, tx); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	s.authLogger.Printf("INFO: api key %s revoked by %s", key.Prefix, identity.Actor(ctx))
+	return nil
+}
+
+// AuthenticateAPIKey resolves the raw key from the request header to the caller identity.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*identity.Identity, error) {
+	if key == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	stored, err := s.db.GetAPIKeyByHash(ctx, hashAPIKey(key), nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if stored.RevokedDate != nil || (stored.ExpiresAt != nil && !stored.ExpiresAt.After(now)) {
+		return nil, ErrInvalidAPIKey
+	}
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= apiKeyLastUsedInterval {
+		// a failed write must not reject a valid key
+		if err := s.db.UpdateAPIKeyLastUsed(ctx, stored.ID, now, nil); err != nil {
+			s.authLogger.Printf("ERROR: update last used of api key %s: %s", stored.Prefix, err.Error())
+		}
+	}
+	return &identity.Identity{
+		Subject: identity.ActorAPIKey + ":" + stored.Name,
+		Role:    stored.Role,
+		Tenant:  stored.TenantID,
+		Scopes:  stored.Scopes,
+	}, nil
+}
+
+// BootstrapAPIKey stores the key from API_KEY as an admin key of the default tenant with every scope, so that
+// the first personal key can be issued on a fresh database. A key that is already stored, even revoked, is left as is.
+func (s *Service) BootstrapAPIKey(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	keyHash := hashAPIKey(key)
+	_, err := s.db.GetAPIKeyByHash(ctx, keyHash, nil)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	stored, err := s.db.RegistrationAPIKey(ctx, &entities.APIKey{
+		Name:     BootstrapAPIKeyName,
+		Prefix:   "env",
+		KeyHash:  keyHash,
+		Role:     identity.RoleAdmin,
+		TenantID: identity.DefaultTenant,
+		Scopes:   identity.Scopes,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	s.authLogger.Printf("INFO: api key from env registered with id %s, revoke it after issuing personal keys", stored.ID)
+	return nil
+}
+
+func (s *Service) issueAPIKey(ctx context.Context, name, role, tenant string, scopes []string, expiresAt *time.Time, exec repository.Executor) (*dto.APIKeyIssuedResponse, error) {
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.db.RegistrationAPIKey(ctx, &entities.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(key),
+		Role:      role,
+		TenantID:  tenant,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, exec)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.APIKeyIssuedResponse{
+		APIKeyResponse: *dto.ToAPIKeyResponse(stored, time.Now().UTC()),
+		Key:            key,
+	}, nil
+}
+
+// apiKeyTenant resolves the tenant of a new key: the caller tenant by default,
+// another tenant only for callers of the default tenant, which manages the deployment.
+func (s *Service) apiKeyTenant(ctx context.Context, requested string) (string, error) {
+	tenant := identity.Tenant(ctx)
+	if requested == "" || requested == tenant {
+		return tenant, nil
+	}
+	if !identity.IsValidTenant(requested) {
+		return "", fmt.Errorf("invalid tenant %q: must be a lowercase slug of latin letters, digits, - and _", requested)
+	}
+	if tenant != identity.DefaultTenant {
+		return "", fmt.Errorf("permission denied: tenant %s cannot issue keys for tenant %s", tenant, requested)
+	}
+	return requested, nil
+}
+
+// generateAPIKey returns the key and its public prefix, the key looks like isk_<prefix>_<secret>.
+func generateAPIKey() (string, string, error) {
+	b := make([]byte, 38)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix := apiKeyPrefix + hex.EncodeToString(b[:6])
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(b[6:]), prefix, nil
+}
+
+// hashAPIKey uses plain sha256: keys are random 256 bit secrets, so a slow hash adds nothing.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	res := []string{}
+	for _, scope := range scopes {
+		if !slices.Contains(identity.Scopes, scope) {
+			return nil, fmt.Errorf("invalid scope %q: must be one of %v", scope, identity.Scopes)
+		}
+		if !slices.Contains(res, scope) {
+			res = append(res, scope)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+var auditActions = []string{
+	AuditActionCreate,
+	AuditActionUpdate,
+	AuditActionDeactivate,
+	AuditActionForceDelete,
+	AuditActionRestore,
+}
+
+var auditFields = []string{
+	"name",
+	"type",
+	"description",
+	"latitude",
+	"longitude",
+	"radius",
+	"status",
+	"is_active",
+	"resolved_date",
+}
+
+func auditIncidentFields(inc *entities.ReadIncident) map[string]any {
+	res := map[string]any{}
+	if inc == nil {
+		return res
+	}
+	res["name"] = inc.Name
+	res["type"] = inc.Type
+	res["latitude"] = inc.Latitude
+	res["longitude"] = inc.Longitude
+	res["radius"] = inc.Radius
+	res["status"] = inc.Status
+	res["is_active"] = inc.IsActive
+	if inc.Description != nil {
+		res["description"] = *inc.Description
+	}
+	if inc.ResolvedDate != nil {
+		res["resolved_date"] = inc.ResolvedDate.UTC().Format(time.RFC3339Nano)
+	}
+	return res
+}
+
+// diffIncidents returns field-level changes between two states of incident,
+// nil before means creation, nil after means deletion.
+func diffIncidents(before, after *entities.ReadIncident) map[string]entities.FieldChange {
+	beforeFields := auditIncidentFields(before)
+	afterFields := auditIncidentFields(after)
+	changes := map[string]entities.FieldChange{}
+	for _, field := range auditFields {
+		if !reflect.DeepEqual(beforeFields[field], afterFields[field]) {
+			changes[field] = entities.FieldChange{
+				Before: beforeFields[field],
+				After:  afterFields[field],
+			}
+		}
+	}
+	return changes
+}
+
+func (s *Service) writeAudit(ctx context.Context, action, id string, before, after *entities.ReadIncident, exec repository.Executor) error {
+	tenant := identity.Tenant(ctx)
+	if after != nil {
+		tenant = after.TenantID
+	} else if before != nil {
+		tenant = before.TenantID
+	}
+	return s.db.RegistrationAuditRecord(ctx, &entities.AuditRecord{
+		TenantID:   tenant,
+		IncidentID: id,
+		Action:     action,
+		Actor:      identity.Actor(ctx),
+		RequestID:  identity.RequestID(ctx),
+		Changes:    diffIncidents(before, after),
+	}, exec)
+}
+
+// GetIncidentHistory answers like GET /incidents/{id}: unknown incident and incident of another tenant are not found.
+// Incidents in trash keep their history.
+func (s *Service) GetIncidentHistory(ctx context.Context, id string, query *dto.AuditQueryParams) (*dto.AuditListResponse, error) {
+	read, err := s.db.GetInfoByIncidentID(ctx, id, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		read, err = s.db.GetDeletedInfoByIncidentID(ctx, id, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenant(ctx, read); err != nil {
+		return nil, err
+	}
+	query.IncidentID = id
+	return s.SearchAudit(ctx, query)
+}
+
+func (s *Service) SearchAudit(ctx context.Context, query *dto.AuditQueryParams) (*dto.AuditListResponse, error) {
+	if query.Action != "" && !slices.Contains(auditActions, query.Action) {
+		return nil, fmt.Errorf("invalid action: must be one of %v", auditActions)
+	}
+	filter := &entities.AuditFilter{
+		TenantID:   identity.Tenant(ctx),
+		IncidentID: query.IncidentID,
+		Actor:      query.Actor,
+		Action:     query.Action,
+		RequestID:  query.RequestID,
+		From:       toUTC(query.From),
+		To:         toUTC(query.To),
+	}
+	if query.PageNum != nil {
+		filter.Limit = s.config.MaxRowsInPage
+		filter.Offset = s.config.MaxRowsInPage * (*query.PageNum - 1)
+	}
+	records, err := s.db.GetAuditRecords(ctx, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToAuditListResponse(records, query.PageNum), nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+)
+
+// authorize repeats the router permission check for actions that depend on the incident itself,
+// e.g. editing an archived incident. Calls without identity come from the service itself
+// (background jobs, tests) and are allowed.
+func (s *Service) authorize(ctx context.Context, perm string) error {
+	id := identity.FromContext(ctx)
+	if id == nil {
+		return nil
+	}
+	if !id.Can(perm) {
+		role := id.Role
+		if role == "" {
+			role = "none"
+		}
+		return fmt.Errorf("permission denied: role %s cannot %s", role, perm)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+const (
+	BulkOperationUpdateStatus = "update_status"
+	BulkOperationArchive      = "archive"
+	BulkOperationDelete       = "delete"
+	BulkOperationChangeRadius = "change_radius"
+
+	MaxBulkIncidents = 1000
+)
+
+var bulkOperations = []string{BulkOperationUpdateStatus, BulkOperationArchive, BulkOperationDelete, BulkOperationChangeRadius}
+
+// BulkIncidents applies one operation to every selected incident in a single transaction.
+// Incidents the operation cannot be applied to are reported as skipped and do not stop the others,
+// db errors roll back the whole operation. Dry run performs the same checks and writes nothing.
+func (s *Service) BulkIncidents(ctx context.Context, req *dto.BulkRequest) (*dto.BulkResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateBulkOperation(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, bulkPermission(req)); err != nil {
+		return nil, err
+	}
+	entit, err := s.toBulkEntity(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	incidents, err := s.db.GetPaginationIncidentsInfo(ctx, entit, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(incidents) > MaxBulkIncidents {
+		return nil, fmt.Errorf("bulk operation cannot be applied to more than %d incidents", MaxBulkIncidents)
+	}
+
+	res := &dto.BulkResponse{
+		Operation:   req.Operation,
+		DryRun:      req.DryRun,
+		Matched:     len(incidents),
+		AffectedIDs: []string{},
+		Skipped:     []*dto.BulkSkipped{},
+	}
+	for _, id := range entit.IDs {
+		found := slices.ContainsFunc(incidents, func(read *entities.ReadIncident) bool {
+			return read.Id == id
+		})
+		if !found {
+			res.Skipped = append(res.Skipped, &dto.BulkSkipped{ID: id, Reason: "not found"})
+		}
+	}
+	for _, read := range incidents {
+		skipReason, err := s.applyBulkOperation(ctx, req, read, tx)
+		if err != nil {
+			return nil, err
+		}
+		if skipReason != "" {
+			res.Skipped = append(res.Skipped, &dto.BulkSkipped{ID: read.Id, Reason: skipReason})
+			continue
+		}
+		res.AffectedIDs = append(res.AffectedIDs, read.Id)
+	}
+	if req.DryRun {
+		return res, nil
+	}
+
+	for _, id := range res.AffectedIDs {
+		s.cacheIncident(ctx, entit.TenantID, id, nil)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: bulk %s applied to %d incidents, skipped %d", req.Operation, len(res.AffectedIDs), len(res.Skipped))
+	return res, nil
+}
+
+func (s *Service) validateBulkOperation(ctx context.Context, req *dto.BulkRequest) error {
+	if !slices.Contains(bulkOperations, req.Operation) {
+		return fmt.Errorf("invalid operation: must be one of %v", bulkOperations)
+	}
+	if req.Operation == BulkOperationUpdateStatus {
+		if req.Status == nil {
+			return fmt.Errorf("status cannot be empty for %s", req.Operation)
+		}
+		if !isKnownStatus(*req.Status) {
+			return fmt.Errorf("invalid status: %s", *req.Status)
+		}
+	} else if req.Status != nil {
+		return fmt.Errorf("status cannot be used with %s", req.Operation)
+	}
+	if req.Operation == BulkOperationChangeRadius {
+		if req.Radius == nil {
+			return fmt.Errorf("radius cannot be empty for %s", req.Operation)
+		}
+		if _, err := s.processingRadius(ctx, req.Radius); err != nil {
+			return err
+		}
+	} else if req.Radius != nil {
+		return fmt.Errorf("radius cannot be used with %s", req.Operation)
+	}
+	if req.Reason != nil && req.Operation != BulkOperationUpdateStatus && req.Operation != BulkOperationArchive {
+		return fmt.Errorf("reason cannot be used with %s", req.Operation)
+	}
+	return nil
+}
+
+// bulkPermission is checked for the whole operation, archived incidents are checked one by one
+// in applyBulkOperation and skipped for callers that cannot edit them.
+func bulkPermission(req *dto.BulkRequest) string {
+	switch req.Operation {
+	case BulkOperationDelete:
+		return identity.PermIncidentsForceDelete
+	case BulkOperationArchive:
+		return identity.PermIncidentsArchive
+	}
+	return identity.PermIncidentsUpdate
+}
+
+// toBulkEntity selects incidents with row locks, one row over the limit is requested
+// to reject operations that are too large instead of silently cutting them.
+func (s *Service) toBulkEntity(ctx context.Context, req *dto.BulkRequest) (*entities.PaginationIncidents, error) {
+	entit := &entities.PaginationIncidents{}
+	if req.Filter != nil {
+		query := req.Filter.ToQueryParams()
+		for _, status := range query.Statuses {
+			if !isKnownStatus(status) {
+				return nil, fmt.Errorf("invalid status: %s", status)
+			}
+		}
+		entit = s.toPaginationEntity(ctx, query, entities.IncidentSortCreated, false)
+	} else {
+		ids := []string{}
+		for _, id := range req.IDs {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > MaxBulkIncidents {
+			return nil, fmt.Errorf("bulk operation cannot be applied to more than %d incidents", MaxBulkIncidents)
+		}
+		entit.TenantID = identity.Tenant(ctx)
+		entit.IDs = ids
+		entit.Sort = entities.IncidentSortCreated
+	}
+	entit.Limit = MaxBulkIncidents + 1
+	entit.ForUpdate = true
+	return entit, nil
+}
+
+// applyBulkOperation returns skip reason when the operation is not allowed for the incident.
+func (s *Service) applyBulkOperation(ctx context.Context, req *dto.BulkRequest, read *entities.ReadIncident, exec repository.Executor) (string, error) {
+	if req.Operation == BulkOperationDelete {
+		if req.DryRun {
+			return "", nil
+		}
+		if err := s.db.DeleteIncidentByID(ctx, read.Id, exec); err != nil {
+			return "", err
+		}
+		return "", s.writeAudit(ctx, AuditActionForceDelete, read.Id, read, nil, exec)
+	}
+
+	action := AuditActionUpdate
+	update := &dto.UpdateRequest{}
+	switch req.Operation {
+	case BulkOperationUpdateStatus:
+		update.Status = req.Status
+		update.StatusReason = req.Reason
+	case BulkOperationArchive:
+		if read.Status == StatusArchived {
+			return "incident already archived", nil
+		}
+		archived := StatusArchived
+		update.Status = &archived
+		update.StatusReason = req.Reason
+		action = AuditActionDeactivate
+	case BulkOperationChangeRadius:
+		update.Radius = req.Radius
+	}
+	if read.Status == StatusArchived {
+		if err := s.authorize(ctx, identity.PermIncidentsEditArchived); err != nil {
+			return err.Error(), nil
+		}
+	}
+	if err := s.processingIncidentIDForUpdate(read, update, read.Id); err != nil {
+		return err.Error(), nil
+	}
+	if req.DryRun {
+		return "", nil
+	}
+	_, err := s.writeIncidentUpdate(ctx, read.Id, read, update, action, exec)
+	return "", err
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+// EnsureChecksPartitions creates partitions of checks for the current period and the configured
+// number of periods ahead. Periods covered by an existing partition are skipped, so switching
+// the interval keeps the partitions created before. Returns the number of created partitions.
+func (s *Service) EnsureChecksPartitions(ctx context.Context) (int, error) {
+	existing, err := s.db.GetChecksPartitions(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, period := range checksPartitionPeriods(time.Now().UTC(), s.config.ChecksPartitionInterval, s.config.ChecksPartitionsAhead) {
+		if overlapsPartitions(period, existing) {
+			continue
+		}
+		if err := s.createChecksPartition(ctx, period); err != nil {
+			return created, err
+		}
+		existing = append(existing, period)
+		created++
+		s.changeLogger.Printf("INFO: checks partition %s created for %s - %s", period.Name,
+			period.From.Format(time.DateOnly), period.To.Format(time.DateOnly))
+	}
+	return created, nil
+}
+
+func (s *Service) createChecksPartition(ctx context.Context, period *entities.ChecksPartition) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.db.CreateChecksPartition(ctx, period.Name, period.From, period.To, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// checksPartitionPeriods returns the period containing now and ahead periods after it.
+func checksPartitionPeriods(now time.Time, interval string, ahead int) []*entities.ChecksPartition {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	layout := "200601"
+	next := func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	if interval == config.PartitionIntervalDay {
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		layout = "20060102"
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	}
+
+	result := make([]*entities.ChecksPartition, 0, ahead+1)
+	for range ahead + 1 {
+		end := next(start)
+		result = append(result, &entities.ChecksPartition{
+			Name: checksPartitionPrefix + start.Format(layout),
+			From: start,
+			To:   end,
+		})
+		start = end
+	}
+	return result
+}
+
+func overlapsPartitions(period *entities.ChecksPartition, partitions []*entities.ChecksPartition) bool {
+	for _, partition := range partitions {
+		if !partition.IsDefault && period.From.Before(partition.To) && partition.From.Before(period.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyChecksRetention removes checks older than the retention period. Whole partitions are dropped
+// or archived depending on the retention mode, old checks of the default partition are removed
+// row by row. User ids of old rollup buckets are deleted as well, the counters are kept.
+// Returns the number of removed partitions.
+func (s *Service) ApplyChecksRetention(ctx context.Context) (int, error) {
+	if s.config.ChecksRetentionDays == 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -s.config.ChecksRetentionDays)
+	archive := s.config.ChecksRetentionMode == config.RetentionModeArchive
+
+	partitions, err := s.db.GetChecksPartitions(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, partition := range partitions {
+		if partition.IsDefault || partition.To.After(cutoff) {
+			continue
+		}
+		if archive {
+			err = s.archiveChecksPartition(ctx, partition.Name)
+		} else {
+			err = s.db.DropChecksPartition(ctx, partition.Name, nil)
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed++
+		s.changeLogger.Printf("CRITICAL: checks partition %s removed by retention with mode %s", partition.Name, s.config.ChecksRetentionMode)
+	}
+
+	count, err := s.db.PurgeDefaultChecksPartition(ctx, cutoff, archive, nil)
+	if err != nil {
+		return removed, err
+	}
+	if count > 0 {
+		s.changeLogger.Printf("CRITICAL: %d checks of the default partition removed by retention with mode %s", count, s.config.ChecksRetentionMode)
+	}
+	if _, err := s.db.DeleteRollupUsersBefore(ctx, cutoff, nil); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
+func (s *Service) archiveChecksPartition(ctx context.Context, name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.db.ArchiveChecksPartition(ctx, name, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CoarsenChecks rounds coordinates of checks older than the configured number of days,
+// the precise location of a user is not kept longer than needed for detection. Checks archived
+// by retention are coarsened as well. Works in batches, so a large backlog does not lock the table
+// for long. A run starts where the previous one stopped, only lowering the number of decimals
+// rounds the whole history again.
+// Returns the number of changed checks.
+func (s *Service) CoarsenChecks(ctx context.Context) (int64, error) {
+	if s.config.ChecksCoarsenAfterDays == 0 {
+		return 0, nil
+	}
+	decimals := s.config.ChecksCoarsenDecimals
+	before := time.Now().UTC().AddDate(0, 0, -s.config.ChecksCoarsenAfterDays)
+	var from time.Time
+	progress, err := s.db.GetChecksCoarsening(ctx, nil)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return 0, err
+	case progress.Decimals <= decimals:
+		from = progress.CoarsenedBefore
+	}
+
+	total := int64(0)
+	for {
+		count, err := s.db.CoarsenChecks(ctx, from, before, decimals, coarsenChecksBatch, nil)
+		if err != nil {
+			return total, err
+		}
+		total += count
+		if count < coarsenChecksBatch {
+			break
+		}
+	}
+	archived, err := s.db.CoarsenArchivedChecks(ctx, from, before, decimals, nil)
+	if err != nil {
+		return total, err
+	}
+	total += archived
+	// a longer CHECKS_COARSEN_AFTER_DAYS does not move the progress back
+	err = s.db.SaveChecksCoarsening(ctx, &entities.ChecksCoarsening{CoarsenedBefore: latest(from, before), Decimals: decimals}, nil)
+	if err != nil {
+		return total, err
+	}
+	if total > 0 {
+		s.changeLogger.Printf("INFO: coordinates of %d checks coarsened to %d decimals", total, decimals)
+	}
+	return total, nil
+}
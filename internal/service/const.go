@@ -1,7 +1,40 @@
 package service
 
 const (
-	StatusActive   = "active"
-	StatusResolved = "resolved"
-	StatusArchived = "archived"
+	StatusUnverified = "unverified"
+	StatusScheduled  = "scheduled"
+	StatusActive     = "active"
+	StatusMonitoring = "monitoring"
+	StatusResolved   = "resolved"
+	StatusArchived   = "archived"
+)
+
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDeactivate  = "deactivate"
+	AuditActionForceDelete = "force_delete"
+	AuditActionRestore     = "restore"
+)
+
+const (
+	HotspotStatusCandidate = "candidate"
+	HotspotStatusPromoted  = "promoted"
+	HotspotStatusDismissed = "dismissed"
+
+	HotspotIncidentType = "hotspot"
+)
+
+const (
+	ForgetModeErase     = "erase"
+	ForgetModeAnonymize = "anonymize"
+
+	anonymizedUserPrefix = "anonymized-"
+	// about a kilometer, a home or work address cannot be told from the rounded point
+	anonymizedCoordinateDecimals = 2
+)
+
+const (
+	checksPartitionPrefix = "checks_p"
+	coarsenChecksBatch    = 5000
 )
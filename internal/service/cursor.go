@@ -0,0 +1,129 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/google/uuid"
+)
+
+const DefaultIncidentSort = "-" + entities.IncidentSortCreated
+
+var incidentSortFields = []string{
+	entities.IncidentSortCreated,
+	entities.IncidentSortUpdated,
+	entities.IncidentSortName,
+	entities.IncidentSortRadius,
+	entities.IncidentSortStatus,
+}
+
+// incidentCursor is the payload of the opaque cursor returned to clients.
+// Sort is stored so that a cursor cannot be replayed with another ordering.
+type incidentCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    string          `json:"id"`
+}
+
+func parseIncidentSort(sort string) (string, bool, error) {
+	if sort == "" {
+		sort = DefaultIncidentSort
+	}
+	field, desc := strings.CutPrefix(sort, "-")
+	for _, known := range incidentSortFields {
+		if field == known {
+			return field, desc, nil
+		}
+	}
+	return "", false, fmt.Errorf("invalid sort: must be one of %v with optional '-' prefix", incidentSortFields)
+}
+
+func incidentSortValue(res *entities.ReadIncident, field string) any {
+	switch field {
+	case entities.IncidentSortUpdated:
+		if res.UpdatedDate != nil {
+			return *res.UpdatedDate
+		}
+		return res.CreatedDate
+	case entities.IncidentSortName:
+		return res.Name
+	case entities.IncidentSortRadius:
+		return res.Radius
+	case entities.IncidentSortStatus:
+		return res.Status
+	default:
+		return res.CreatedDate
+	}
+}
+
+func encodeIncidentCursor(sort string, res *entities.ReadIncident) (string, error) {
+	field, _, err := parseIncidentSort(sort)
+	if err != nil {
+		return "", err
+	}
+	value := incidentSortValue(res, field)
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(incidentCursor{Sort: sort, Value: raw, ID: res.Id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeIncidentCursor(cursor, sort string) (*entities.IncidentCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	payload := incidentCursor{}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if payload.Sort != sort {
+		return nil, fmt.Errorf("invalid cursor: issued for sort %q", payload.Sort)
+	}
+	if _, err := uuid.Parse(payload.ID); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	field, _, err := parseIncidentSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entities.IncidentCursor{ID: payload.ID}
+	switch field {
+	case entities.IncidentSortRadius:
+		var radius int
+		if err := json.Unmarshal(payload.Value, &radius); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		res.Value = radius
+	case entities.IncidentSortName, entities.IncidentSortStatus:
+		var str string
+		if err := json.Unmarshal(payload.Value, &str); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		res.Value = str
+	default:
+		var str string
+		if err := json.Unmarshal(payload.Value, &str); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		t, err := time.Parse(time.RFC3339Nano, str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		res.Value = t
+	}
+	return res, nil
+}
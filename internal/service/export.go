@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	DefaultChecksExportWindow = 24 * time.Hour
+	MaxChecksExportWindow     = 31 * 24 * time.Hour
+)
+
+var incidentExportColumns = []string{
+	"id", "name", "type", "description", "radius", "status", "is_active",
+	"created_date", "updated_date", "resolved_date", "version",
+}
+
+// detected_incident_ids is written as a comma separated string,
+// GeoPackage and shapefile attributes cannot hold arrays
+var checkExportColumns = []string{"id", "user_id", "is_danger", "detected_incident_ids", "created_date"}
+
+// ExportIncidents writes incidents matching the list filters to w in the requested format.
+// Rows are written while they are read from the database, paging parameters are ignored.
+func (s *Service) ExportIncidents(ctx context.Context, format string, query *dto.PaginationQueryParams, w io.Writer) error {
+	if query.Radius != nil {
+		_, err := s.processingRadius(ctx, query.Radius)
+		if err != nil {
+			return err
+		}
+	}
+	for _, status := range query.Statuses {
+		if !isKnownStatus(status) {
+			return fmt.Errorf("invalid status: %s", status)
+		}
+	}
+	sortField, sortDesc, err := parseIncidentSort(query.Sort)
+	if err != nil {
+		return err
+	}
+	fw, err := geoformat.NewFeatureWriter(format, w, incidentExportColumns)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	entit := s.toPaginationEntity(ctx, query, sortField, sortDesc)
+	err = s.db.ExportIncidents(ctx, entit, func(incident *entities.ReadIncident) error {
+		count++
+		return fw.Write(&geoformat.Feature{
+			Latitude:  incident.Latitude,
+			Longitude: incident.Longitude,
+			Properties: []any{
+				incident.Id, incident.Name, incident.Type, incident.Description, incident.Radius, incident.Status,
+				incident.IsActive, incident.CreatedDate, incident.UpdatedDate, incident.ResolvedDate, incident.Version,
+			},
+		})
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if err = fw.Close(); err != nil {
+		return err
+	}
+	s.changeLogger.Printf("INFO: exported %d incidents as %s", count, format)
+	return nil
+}
+
+// ExportChecks writes location checks created in [from, to) to w in the requested format.
+// Without bounds the last DefaultChecksExportWindow is exported.
+func (s *Service) ExportChecks(ctx context.Context, format string, from, to *time.Time, w io.Writer) error {
+	end := time.Now().UTC()
+	if to != nil {
+		end = to.UTC()
+	}
+	start := end.Add(-DefaultChecksExportWindow)
+	if from != nil {
+		start = from.UTC()
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("from cannot be after to")
+	}
+	if end.Sub(start) > MaxChecksExportWindow {
+		return fmt.Errorf("export window cannot be longer than %d days", int(MaxChecksExportWindow.Hours()/24))
+	}
+	fw, err := geoformat.NewFeatureWriter(format, w, checkExportColumns)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.db.ExportChecks(ctx, identity.Tenant(ctx), start, end, func(check *entities.CheckRecord) error {
+		count++
+		return fw.Write(&geoformat.Feature{
+			Latitude:  check.Latitude,
+			Longitude: check.Longitude,
+			Properties: []any{
+				check.ID, check.UserID, check.IsDanger, strings.Join(check.DetectedIncidentIDs, ","), check.CreatedDate,
+			},
+		})
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if err = fw.Close(); err != nil {
+		return err
+	}
+	s.changeLogger.Printf("INFO: exported %d checks as %s", count, format)
+	return nil
+}
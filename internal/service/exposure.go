@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+// exported point of a user is the check closest to the incident center
+var exposureExportColumns = []string{"user_id", "first_seen", "last_seen", "checks", "min_distance_meters"}
+
+// GetIncidentExposure lists users whose checks fell inside the incident. A check detects only
+// active incidents, so the list covers the time the incident was active.
+func (s *Service) GetIncidentExposure(ctx context.Context, id string) (*dto.ExposureResponse, error) {
+	if err := s.checkExposureIncident(ctx, id); err != nil {
+		return nil, err
+	}
+	res := &dto.ExposureResponse{IncidentID: id, Users: []*dto.ExposedUserResponse{}}
+	err := s.db.ExportIncidentExposure(ctx, identity.Tenant(ctx), id, func(user *entities.ExposedUser) error {
+		res.Users = append(res.Users, dto.ToExposedUserResponse(user))
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.UsersCount = len(res.Users)
+	return res, nil
+}
+
+// ExportIncidentExposure writes the exposure report to w in the requested format.
+func (s *Service) ExportIncidentExposure(ctx context.Context, id, format string, w io.Writer) error {
+	if err := s.checkExposureIncident(ctx, id); err != nil {
+		return err
+	}
+	fw, err := geoformat.NewFeatureWriter(format, w, exposureExportColumns)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.db.ExportIncidentExposure(ctx, identity.Tenant(ctx), id, func(user *entities.ExposedUser) error {
+		count++
+		return fw.Write(&geoformat.Feature{
+			Latitude:   user.Latitude,
+			Longitude:  user.Longitude,
+			Properties: []any{user.UserID, user.FirstSeen, user.LastSeen, user.Checks, user.MinDistance},
+		})
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if err = fw.Close(); err != nil {
+		return err
+	}
+	s.changeLogger.Printf("INFO: exported exposure of incident %s: %d users as %s", id, count, format)
+	return nil
+}
+
+// checkExposureIncident allows reports for deleted incidents too, the review is often done after cleanup.
+func (s *Service) checkExposureIncident(ctx context.Context, id string) error {
+	read, err := s.db.GetInfoByIncidentID(ctx, id, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		read, err = s.db.GetDeletedInfoByIncidentID(ctx, id, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return checkTenant(ctx, read)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+// DetectHotspots clusters recent checks which did not hit any incident and saves dense clusters
+// as candidates. A cluster close to a hotspot seen in the previous window updates that hotspot,
+// so one place is not reported again on every run.
+func (s *Service) DetectHotspots(ctx context.Context) (int, error) {
+	window := time.Duration(s.config.HotspotWindowMinutes) * time.Minute
+	since := time.Now().UTC().Add(-window)
+	clusters, err := s.db.FindCheckClusters(ctx, &entities.HotspotParams{
+		Since:        since,
+		RadiusMeters: s.config.HotspotRadiusMeters,
+		MinChecks:    s.config.HotspotMinChecks,
+		MinUsers:     s.config.HotspotMinUsers,
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	detected := 0
+	for _, cluster := range clusters {
+		created, err := s.saveHotspot(ctx, cluster, since.Add(-window))
+		if err != nil {
+			return detected, err
+		}
+		if created {
+			detected++
+			s.changeLogger.Printf("INFO: hotspot detected for tenant %s at %s, %s: %d checks of %d users",
+				cluster.TenantID, cluster.Latitude, cluster.Longitude, cluster.Checks, cluster.UniqueUsers)
+		}
+	}
+	return detected, nil
+}
+
+func (s *Service) saveHotspot(ctx context.Context, cluster *entities.Hotspot, seenSince time.Time) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	created := false
+	nearby, err := s.db.GetNearbyHotspotForUpdate(ctx, cluster.TenantID, cluster.Latitude, cluster.Longitude,
+		cluster.Radius+s.config.HotspotRadiusMeters, seenSince, tx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = s.db.RegistrationHotspot(ctx, cluster, tx)
+		created = true
+	case err != nil:
+		return false, err
+	case nearby.Status == HotspotStatusCandidate:
+		err = s.db.UpdateHotspotCluster(ctx, nearby.ID, cluster, tx)
+	default:
+		// promoted and dismissed hotspots are not reopened, the cluster is already handled
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return created, tx.Commit()
+}
+
+func (s *Service) GetHotspots(ctx context.Context, status string) ([]*dto.HotspotResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsRead); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = HotspotStatusCandidate
+	}
+	if !isKnownHotspotStatus(status) {
+		return nil, fmt.Errorf("invalid status: must be one of %s, %s, %s",
+			HotspotStatusCandidate, HotspotStatusPromoted, HotspotStatusDismissed)
+	}
+	hotspots, err := s.db.GetHotspots(ctx, identity.Tenant(ctx), status, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*dto.HotspotResponse, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		res = append(res, dto.ToHotspotResponse(hotspot))
+	}
+	return res, nil
+}
+
+// PromoteHotspot creates an incident at the hotspot and marks the hotspot as promoted.
+func (s *Service) PromoteHotspot(ctx context.Context, id string, req *dto.PromoteHotspotRequest) (*dto.PromoteHotspotResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsCreate); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hotspot, err := s.getHotspotForWrite(ctx, id, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	incidentReq := &dto.RegistrationIncidentRequest{
+		Name:           fmt.Sprintf("Hotspot %s", hotspot.ID[:8]),
+		Type:           HotspotIncidentType,
+		Latitude:       hotspot.Latitude,
+		Longitude:      hotspot.Longitude,
+		Description:    req.Description,
+		RadiusInMeters: req.RadiusInMeters,
+		Status:         req.Status,
+	}
+	if req.Name != nil {
+		incidentReq.Name = *req.Name
+	}
+	if req.Type != nil {
+		incidentReq.Type = *req.Type
+	}
+	if incidentReq.RadiusInMeters == nil {
+		radius := min(hotspot.Radius, s.tenantConfig(ctx).MaxRadius)
+		incidentReq.RadiusInMeters = &radius
+	}
+	if incidentReq.Status == nil {
+		status := StatusUnverified
+		incidentReq.Status = &status
+	}
+	if err := incidentReq.Validate(); err != nil {
+		return nil, err
+	}
+	entit, err := s.FromDtoToEntitie(ctx, incidentReq)
+	if err != nil {
+		return nil, err
+	}
+
+	incident, err := s.createIncident(ctx, entit, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.UpdateHotspotStatus(ctx, id, HotspotStatusPromoted, &incident.Id, tx)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheIncident(ctx, incident.TenantID, incident.Id, incident)
+	if err = s.commitCached(ctx, tx, incident.TenantID, incident.Id); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: Create new incident with id: %s", incident.Id)
+	s.changeLogger.Printf("INFO: hotspot %s promoted to incident %s by %s", id, incident.Id, identity.Actor(ctx))
+
+	hotspot.Status = HotspotStatusPromoted
+	hotspot.IncidentID = &incident.Id
+	return &dto.PromoteHotspotResponse{
+		Hotspot:  dto.ToHotspotResponse(hotspot),
+		Incident: dto.CreateAdminResponse(incident, nil),
+	}, nil
+}
+
+// DismissHotspot hides a false positive, the place is not reported again while checks keep coming.
+func (s *Service) DismissHotspot(ctx context.Context, id string) (*dto.HotspotResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsUpdate); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hotspot, err := s.getHotspotForWrite(ctx, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.UpdateHotspotStatus(ctx, id, HotspotStatusDismissed, nil, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: hotspot %s dismissed by %s", id, identity.Actor(ctx))
+	hotspot.Status = HotspotStatusDismissed
+	return dto.ToHotspotResponse(hotspot), nil
+}
+
+func (s *Service) getHotspotForWrite(ctx context.Context, id string, exec repository.Executor) (*entities.Hotspot, error) {
+	hotspot, err := s.db.GetHotspotByIDForUpdate(ctx, id, exec)
+	if err != nil {
+		return nil, err
+	}
+	if hotspot.TenantID != identity.Tenant(ctx) {
+		return nil, sql.ErrNoRows
+	}
+	switch hotspot.Status {
+	case HotspotStatusPromoted:
+		return nil, fmt.Errorf("hotspot already promoted")
+	case HotspotStatusDismissed:
+		return nil, fmt.Errorf("hotspot already dismissed")
+	}
+	return hotspot, nil
+}
+
+func isKnownHotspotStatus(status string) bool {
+	switch status {
+	case HotspotStatusCandidate, HotspotStatusPromoted, HotspotStatusDismissed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Piccadilly98/incidents_service/internal/geoformat"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	ImportModeAtomic     = "atomic"
+	ImportModeBestEffort = "best_effort"
+
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+	ImportRowFailed  = "failed"
+
+	MaxImportRows = 1000
+)
+
+var importModes = []string{ImportModeAtomic, ImportModeBestEffort}
+
+type importItem struct {
+	result *dto.ImportRowResult
+	entit  *entities.RegistrationIncidentEntitie
+}
+
+// ImportIncidents creates incidents from a GeoJSON, CSV or KML document.
+// In atomic mode nothing is written when at least one row is invalid,
+// in best effort mode every row is saved in its own transaction.
+// Rows whose name is already taken are skipped in both modes.
+func (s *Service) ImportIncidents(ctx context.Context, format, mode string, r io.Reader) (*dto.ImportReport, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsCreate); err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		mode = ImportModeAtomic
+	}
+	if mode != ImportModeAtomic && mode != ImportModeBestEffort {
+		return nil, fmt.Errorf("invalid mode: must be one of %v", importModes)
+	}
+	records, err := geoformat.Parse(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > MaxImportRows {
+		return nil, fmt.Errorf("import cannot be larger than %d rows", MaxImportRows)
+	}
+
+	report := &dto.ImportReport{Format: format, Mode: mode, Total: len(records)}
+	items := s.prepareImportItems(ctx, records)
+	for _, item := range items {
+		report.Rows = append(report.Rows, item.result)
+	}
+
+	if mode == ImportModeAtomic {
+		err = s.importAtomic(ctx, items)
+	} else {
+		s.importBestEffort(ctx, items)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case ImportRowCreated:
+			report.Created++
+		case ImportRowSkipped:
+			report.Skipped++
+		case ImportRowFailed:
+			report.Failed++
+		}
+	}
+	s.changeLogger.Printf("INFO: import %s/%s finished: created %d, skipped %d, failed %d",
+		format, mode, report.Created, report.Skipped, report.Failed)
+	return report, nil
+}
+
+// prepareImportItems validates every record, rows that cannot be created are marked in their result.
+func (s *Service) prepareImportItems(ctx context.Context, records []*geoformat.Record) []*importItem {
+	items := make([]*importItem, 0, len(records))
+	names := map[string]int{}
+	for _, record := range records {
+		item := &importItem{result: &dto.ImportRowResult{Row: record.Row}}
+		items = append(items, item)
+		if record.Err != nil {
+			item.result.Status = ImportRowFailed
+			item.result.Error = record.Err.Error()
+			continue
+		}
+		item.result.Name = record.Request.Name
+		if err := record.Request.Validate(); err != nil {
+			item.result.Status = ImportRowFailed
+			item.result.Error = err.Error()
+			continue
+		}
+		entit, err := s.FromDtoToEntitie(ctx, record.Request)
+		if err != nil {
+			item.result.Status = ImportRowFailed
+			item.result.Error = err.Error()
+			continue
+		}
+		if row, ok := names[entit.Name]; ok {
+			item.result.Status = ImportRowSkipped
+			item.result.Error = fmt.Sprintf("name already used in row %d", row)
+			continue
+		}
+		names[entit.Name] = record.Row
+		item.entit = entit
+	}
+	return items
+}
+
+func (s *Service) importAtomic(ctx context.Context, items []*importItem) error {
+	for _, item := range items {
+		if item.result.Status == ImportRowFailed {
+			for _, other := range items {
+				if other.entit != nil {
+					other.result.Status = ImportRowSkipped
+					other.result.Error = "not imported: document has failed rows"
+				}
+			}
+			return nil
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	created := []string{}
+	for _, item := range items {
+		if item.entit == nil {
+			continue
+		}
+		exists, err := s.db.GetExistByIncidentName(ctx, item.entit.TenantID, item.entit.Name, tx)
+		if err != nil {
+			return err
+		}
+		if exists {
+			item.result.Status = ImportRowSkipped
+			item.result.Error = "incident with this name already exists"
+			continue
+		}
+		res, err := s.createIncident(ctx, item.entit, tx)
+		if err != nil {
+			return err
+		}
+		item.result.Status = ImportRowCreated
+		item.result.ID = res.Id
+		created = append(created, res.Id)
+		s.cacheIncident(ctx, res.TenantID, res.Id, res)
+	}
+	if err = s.commitCached(ctx, tx, identity.Tenant(ctx), created...); err != nil {
+		return err
+	}
+	for _, id := range created {
+		s.changeLogger.Printf("INFO: Create new incident with id: %s", id)
+	}
+	return nil
+}
+
+func (s *Service) importBestEffort(ctx context.Context, items []*importItem) {
+	for _, item := range items {
+		if item.entit == nil {
+			continue
+		}
+		res, skipped, err := s.importOne(ctx, item.entit)
+		switch {
+		case err != nil:
+			s.changeLogger.Printf("ERROR: import row %d: %s", item.result.Row, err.Error())
+			item.result.Status = ImportRowFailed
+			item.result.Error = "failed to save incident"
+		case skipped:
+			item.result.Status = ImportRowSkipped
+			item.result.Error = "incident with this name already exists"
+		default:
+			item.result.Status = ImportRowCreated
+			item.result.ID = res.Id
+			s.changeLogger.Printf("INFO: Create new incident with id: %s", res.Id)
+		}
+	}
+}
+
+func (s *Service) importOne(ctx context.Context, entit *entities.RegistrationIncidentEntitie) (*entities.ReadIncident, bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	exists, err := s.db.GetExistByIncidentName(ctx, entit.TenantID, entit.Name, tx)
+	if err != nil {
+		return nil, false, err
+	}
+	if exists {
+		return nil, true, nil
+	}
+	res, err := s.createIncident(ctx, entit, tx)
+	if err != nil {
+		return nil, false, err
+	}
+	s.cacheIncident(ctx, res.TenantID, res.Id, res)
+	if err = s.commitCached(ctx, tx, res.TenantID, res.Id); err != nil {
+		return nil, false, err
+	}
+	return res, false, nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -10,7 +11,8 @@ import (
 )
 
 type WebhookSender interface {
-	AddToQueue(result dto.LocationCheckResponse, url, method string)
+	AddToQueue(result dto.LocationCheckResponse, tenant, url, method string)
+	RemoveUserTasks(ctx context.Context, tenant, userID string) (int, error)
 	Stop()
 }
 
@@ -21,6 +23,7 @@ type Service struct {
 	changeLogger     *log.Logger
 	dbCriticalLogger *log.Logger
 	cacheLogger      *log.Logger
+	authLogger       *log.Logger
 	wm               WebhookSender
 }
 
@@ -32,6 +35,7 @@ func NewService(db repository.DbReposytory, cache repository.CacheReposytory, co
 		changeLogger:     log.New(os.Stdout, "[UPDATE INCIDENT INFO] ", log.Ldate|log.Ltime),
 		dbCriticalLogger: log.New(os.Stderr, "[DB PING ERROR] ", log.Ldate|log.Ltime),
 		cacheLogger:      log.New(os.Stderr, "[CACHE ERROR] ", log.Ldate|log.Ltime),
+		authLogger:       log.New(os.Stdout, "[AUTH] ", log.Ldate|log.Ltime),
 		wm:               wm,
 	}
 }
@@ -2,12 +2,15 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
 	"github.com/Piccadilly98/incidents_service/internal/repository"
@@ -276,7 +279,7 @@ func TestService_RegistrationIncident_WithCache(t *testing.T) {
 			}
 
 			if tt.wantCacheEntry {
-				cached, cacheErr := mockCache.GetActiveIncident(context.Background(), res.ID)
+				cached, cacheErr := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, res.ID)
 				assert.NoError(t, cacheErr)
 				assert.NotNil(t, cached)
 				assert.Equal(t, tt.req.Name, cached.Name)
@@ -435,12 +438,12 @@ func TestService_GetIncidentInfo(t *testing.T) {
 			}
 
 			if tc.loadToCacheAfterDb {
-				_, err := cacheMock.GetActiveIncident(context.Background(), tc.checkId)
+				_, err := cacheMock.GetActiveIncident(context.Background(), identity.DefaultTenant, tc.checkId)
 				if err != nil {
 					t.Errorf("CHECK EXISTS IN CACHE AFTER DB: got: true, expect: false\n")
 				}
 			} else {
-				_, err := cacheMock.GetActiveIncident(context.Background(), tc.checkId)
+				_, err := cacheMock.GetActiveIncident(context.Background(), identity.DefaultTenant, tc.checkId)
 				if err == nil {
 					t.Errorf("CHECK EXISTS IN CACHE AFTER DB: got: false, expect: true\n")
 				}
@@ -449,6 +452,49 @@ func TestService_GetIncidentInfo(t *testing.T) {
 	}
 }
 
+func TestService_UpdateIncidentCache(t *testing.T) {
+	name := "new_name"
+	testCases := []struct {
+		name          string
+		commitErr     error
+		expectVersion int
+		expectInCache bool
+	}{
+		{
+			name:          "new_version_cached",
+			expectVersion: 2,
+			expectInCache: true,
+		},
+		{
+			name:      "failed_commit_drops_cached_copy",
+			commitErr: fmt.Errorf("connection reset"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			mockDb.CommitErr = tc.commitErr
+			cacheMock := repository.NewCacheMock()
+			mockDb.Storage["id"] = &entities.ReadIncident{Id: "id", Name: "old_name", Type: "fire", Status: service.StatusActive, IsActive: true, Version: 1}
+			cacheMock.Storage["id"] = &entities.ReadIncident{Id: "id", Name: "old_name", Type: "fire", Status: service.StatusActive, IsActive: true, Version: 1}
+			svc := service.NewService(mockDb, cacheMock, &config.Config{MaxRadius: 5000}, nil)
+
+			_, err := svc.UpdateIncidentByID(context.Background(), "id", &dto.UpdateRequest{Name: &name}, nil)
+			if tc.commitErr != nil {
+				assert.ErrorIs(t, err, tc.commitErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			cached, ok := cacheMock.Storage["id"]
+			assert.Equal(t, tc.expectInCache, ok)
+			if ok {
+				assert.Equal(t, tc.expectVersion, cached.Version)
+				assert.Equal(t, name, cached.Name)
+			}
+		})
+	}
+}
+
 func TestService_UpdateIncidentByID(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -457,6 +503,8 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 		containInDb    bool
 		containInCache bool
 		body           *dto.UpdateRequest
+		ifMatch        *int
+		requireIfMatch bool
 		expectInCache  bool
 		expectErr      bool
 		containErr     string
@@ -598,6 +646,73 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 			containErr:    "radius cannot be >",
 			expectInCache: false,
 		},
+		{
+			name:        "if_match_version_equal",
+			id:          "upd-id-6",
+			containInDb: true,
+			bodyInStorage: &entities.ReadIncident{
+				Id:       "upd-id-6",
+				Name:     "old",
+				IsActive: true,
+				Version:  3,
+			},
+			body: &dto.UpdateRequest{
+				Name: getStrPtr("new"),
+			},
+			ifMatch:       getIntPtr(3),
+			expectInCache: true,
+		},
+		{
+			name:        "if_match_version_mismatch",
+			id:          "upd-id-7",
+			containInDb: true,
+			bodyInStorage: &entities.ReadIncident{
+				Id:       "upd-id-7",
+				Name:     "old",
+				IsActive: true,
+				Version:  4,
+			},
+			body: &dto.UpdateRequest{
+				Name: getStrPtr("new"),
+			},
+			ifMatch:    getIntPtr(3),
+			expectErr:  true,
+			containErr: "precondition failed: incident version is 4",
+		},
+		{
+			name:        "if_match_required_but_absent",
+			id:          "upd-id-8",
+			containInDb: true,
+			bodyInStorage: &entities.ReadIncident{
+				Id:       "upd-id-8",
+				Name:     "old",
+				IsActive: true,
+				Version:  1,
+			},
+			body: &dto.UpdateRequest{
+				Name: getStrPtr("new"),
+			},
+			requireIfMatch: true,
+			expectErr:      true,
+			containErr:     "precondition required",
+		},
+		{
+			name:           "stale_cache_is_not_used_for_update",
+			id:             "upd-id-9",
+			containInDb:    true,
+			containInCache: true,
+			bodyInStorage: &entities.ReadIncident{
+				Id:       "upd-id-9",
+				Name:     "old",
+				IsActive: true,
+				Version:  2,
+			},
+			body: &dto.UpdateRequest{
+				Name: getStrPtr("new"),
+			},
+			ifMatch:       getIntPtr(2),
+			expectInCache: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -605,13 +720,16 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 			mockDb := repository.NewMockDb()
 			mockCache := repository.NewCacheMock()
 			cfg := &config.Config{
-				DefaultRadius: 500,
-				MaxRadius:     5000,
+				DefaultRadius:  500,
+				MaxRadius:      5000,
+				RequireIfMatch: tc.requireIfMatch,
 			}
 			svc := service.NewService(mockDb, mockCache, cfg, nil)
 			if tc.containInCache {
 				if tc.bodyInStorage != nil {
-					mockCache.Storage[tc.bodyInStorage.Id] = tc.bodyInStorage
+					stale := *tc.bodyInStorage
+					stale.Version--
+					mockCache.Storage[tc.bodyInStorage.Id] = &stale
 				} else {
 					t.Fatalf("invalid test: body in storage cannot be nil and contain in cache: true")
 				}
@@ -624,7 +742,7 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 				}
 			}
 
-			res, err := svc.UpdateIncidentByID(context.Background(), tc.id, tc.body)
+			res, err := svc.UpdateIncidentByID(context.Background(), tc.id, tc.body, tc.ifMatch)
 			if err != nil {
 				if tc.expectErr {
 					if !strings.Contains(err.Error(), tc.containErr) {
@@ -651,7 +769,7 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 			}
 
 			if tc.expectInCache {
-				_, err := mockCache.GetActiveIncident(context.Background(), tc.id)
+				_, err := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, tc.id)
 				if err != nil {
 					t.Errorf("error exists in cache: got: false, expect: true\n")
 				}
@@ -676,6 +794,25 @@ func TestService_UpdateIncidentByID(t *testing.T) {
 						t.Errorf("fail update type: got: %s, expect: %s\n", res.Type, *tc.body.Type)
 					}
 				}
+				if tc.body.Status != nil {
+					if len(mockDb.Transitions) != 1 {
+						t.Fatalf("status transitions: got: %d, expect: 1\n", len(mockDb.Transitions))
+					}
+					if mockDb.Transitions[0].ToStatus != *tc.body.Status {
+						t.Errorf("transition to: got: %s, expect: %s\n", mockDb.Transitions[0].ToStatus, *tc.body.Status)
+					}
+				} else if len(mockDb.Transitions) != 0 {
+					t.Errorf("unexpected status transitions: %d\n", len(mockDb.Transitions))
+				}
+				if len(mockDb.Audit) != 1 {
+					t.Fatalf("audit records: got: %d, expect: 1\n", len(mockDb.Audit))
+				}
+				if mockDb.Audit[0].Action != service.AuditActionUpdate {
+					t.Errorf("audit action: got: %s, expect: %s\n", mockDb.Audit[0].Action, service.AuditActionUpdate)
+				}
+				if len(mockDb.Audit[0].Changes) == 0 {
+					t.Errorf("audit record must contain changed fields\n")
+				}
 			}
 		})
 	}
@@ -688,6 +825,7 @@ func TestService_DeactivateIncidentByID(t *testing.T) {
 		bodyInStorage  *entities.ReadIncident
 		containInDb    bool
 		containInCache bool
+		ifMatch        *int
 		expectInCache  bool
 		expectErr      bool
 		containErr     string
@@ -762,6 +900,30 @@ func TestService_DeactivateIncidentByID(t *testing.T) {
 			expectInCache: false,
 			expectErr:     false,
 		},
+		{
+			name: "if_match_version_equal",
+			id:   "new_id",
+			bodyInStorage: &entities.ReadIncident{
+				Id:      "new_id",
+				Status:  service.StatusActive,
+				Version: 5,
+			},
+			containInDb: true,
+			ifMatch:     getIntPtr(5),
+		},
+		{
+			name: "if_match_version_mismatch",
+			id:   "new_id",
+			bodyInStorage: &entities.ReadIncident{
+				Id:      "new_id",
+				Status:  service.StatusActive,
+				Version: 5,
+			},
+			containInDb: true,
+			ifMatch:     getIntPtr(2),
+			expectErr:   true,
+			containErr:  "precondition failed",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -788,7 +950,7 @@ func TestService_DeactivateIncidentByID(t *testing.T) {
 					t.Fatalf("invalid test: body in storage cannot be nil and contain in db: true")
 				}
 			}
-			res, err := svc.DeactivateIncidentByID(context.Background(), tc.id)
+			res, err := svc.DeactivateIncidentByID(context.Background(), tc.id, tc.ifMatch)
 			if err != nil {
 				if tc.expectErr {
 					if !strings.Contains(err.Error(), tc.containErr) {
@@ -824,12 +986,12 @@ func TestService_DeactivateIncidentByID(t *testing.T) {
 			}
 
 			if tc.expectInCache {
-				_, err := mockCache.GetActiveIncident(context.Background(), tc.id)
+				_, err := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, tc.id)
 				if err != nil {
 					t.Errorf("incident not contains in cache")
 				}
 			} else {
-				_, err := mockCache.GetActiveIncident(context.Background(), tc.id)
+				_, err := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, tc.id)
 				if err == nil {
 					t.Errorf("incident not delete from cache")
 				}
@@ -910,13 +1072,30 @@ func TestService_DeleteIncidentByID(t *testing.T) {
 				mockDb.Storage[loadId] = body
 			}
 
-			err := svc.DeleteIncidentByID(context.Background(), tc.deleteID)
+			err := svc.DeleteIncidentByID(context.Background(), tc.deleteID, nil)
 			if err != nil {
 				t.Errorf("unexpected err: %s\n", err.Error())
 			}
 
-			if mockDb.Tx != nil {
-				t.Errorf("unexpected tx in db\n")
+			if mockDb.Tx == nil || !mockDb.Tx.Committed {
+				t.Errorf("transaction must be committed\n")
+			}
+			expectAudit := tc.containInDb && tc.deleteID == loadId
+			if expectAudit {
+				if len(mockDb.Audit) != 1 {
+					t.Fatalf("expected 1 audit record, got: %d\n", len(mockDb.Audit))
+				}
+				if mockDb.Audit[0].Action != service.AuditActionForceDelete {
+					t.Errorf("unexpected audit action: %s\n", mockDb.Audit[0].Action)
+				}
+				if _, ok := mockDb.Audit[0].Changes["radius"]; !ok {
+					t.Errorf("audit record must contain radius before value\n")
+				}
+				if _, err := mockDb.GetDeletedInfoByIncidentID(context.Background(), loadId, nil); err != nil {
+					t.Errorf("deleted incident must stay in trash: %s\n", err.Error())
+				}
+			} else if len(mockDb.Audit) != 0 {
+				t.Errorf("unexpected audit records: %d\n", len(mockDb.Audit))
 			}
 			if !tc.expectBodyInCache {
 				if _, err := mockDb.GetInfoByIncidentID(context.Background(), loadId, nil); err == nil {
@@ -928,11 +1107,11 @@ func TestService_DeleteIncidentByID(t *testing.T) {
 				}
 			}
 			if !tc.expectBodyInDb {
-				if _, err := mockCache.GetActiveIncident(context.Background(), loadId); err == nil {
+				if _, err := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, loadId); err == nil {
 					t.Errorf("unexpected row in cache with id: %s\n", loadId)
 				}
 			} else {
-				if _, err := mockCache.GetActiveIncident(context.Background(), loadId); err != nil {
+				if _, err := mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, loadId); err != nil {
 					t.Errorf("invalid delete, cannot contains in db\n")
 				}
 			}
@@ -940,116 +1119,106 @@ func TestService_DeleteIncidentByID(t *testing.T) {
 	}
 }
 
-func TestService_LocationCheck(t *testing.T) {
-	inc1 := &entities.ReadIncident{
-		Id:        "inc_1",
-		Latitude:  "55.755826",
-		Longitude: "37.617300",
-		Status:    service.StatusActive,
-		IsActive:  true,
-		Radius:    2000,
-	}
-	inc2 := &entities.ReadIncident{
-		Id:        "inc_2",
-		Latitude:  "55.7735",
-		Longitude: "37.6200",
-		Status:    service.StatusActive,
-		IsActive:  true,
-		Radius:    1000,
-	}
-	inc3 := &entities.ReadIncident{
-		Id:          "inc_3",
-		Latitude:    "37.1406",
-		Longitude:   "115.4840",
-		Status:      service.StatusActive,
-		IsActive:    true,
-		Description: getStrPtr("zone-51!"),
-		Radius:      1000,
-	}
-	inc4 := &entities.ReadIncident{
-		Id:          "inc_4",
-		Latitude:    "37",
-		Longitude:   "115",
-		Status:      service.StatusArchived,
-		IsActive:    false,
-		Description: getStrPtr("inactive"),
-		Radius:      1000,
-	}
+func TestService_PatchIncidentByID(t *testing.T) {
+	id := "patch-id"
 	testCases := []struct {
-		name                  string
-		checkBody             *dto.LocationCheckRequest
-		expectCheck           bool
-		expectedIds           []string
-		expectedIsDanger      bool
-		expectedErrContain    string
-		ContainsIdsInWeebhook bool
+		name          string
+		patchType     string
+		patch         string
+		check         func(t *testing.T, res *dto.IncidentAdminResponse)
+		expectErr     string
+		expectTransit bool
 	}{
 		{
-			name: "check_coord_inc_1",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "new_user",
-				Latitude:  "55.755826",
-				Longitude: "37.617300",
+			name:      "merge_clear_description",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"description": null}`,
+			check: func(t *testing.T, res *dto.IncidentAdminResponse) {
+				if res.Description != nil {
+					t.Errorf("description must be cleared, got: %s\n", *res.Description)
+				}
 			},
-			expectCheck:      true,
-			expectedIds:      []string{"inc_1"},
-			expectedIsDanger: true,
 		},
 		{
-			name: "check_coord_inc_1_and_inc_2",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "test_user",
-				Latitude:  "55.7650",
-				Longitude: "37.6184",
+			name:      "merge_set_name_and_radius",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"name": "new", "radius": 300, "type": "fire"}`,
+			check: func(t *testing.T, res *dto.IncidentAdminResponse) {
+				if res.Name != "new" || res.Radius != 300 {
+					t.Errorf("got name: %s, radius: %d\n", res.Name, res.Radius)
+				}
 			},
-			expectCheck:      true,
-			expectedIds:      []string{"inc_1", "inc_2"},
-			expectedIsDanger: true,
 		},
 		{
-			name: "check_inactive_incident",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "test_user",
-				Latitude:  "37",
-				Longitude: "115",
-			},
-			expectCheck:      true,
-			expectedIsDanger: false,
+			name:      "merge_null_name",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"name": null}`,
+			expectErr: "name cannot be null",
 		},
-
 		{
-			name: "invalid_user_id_empty",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "",
-				Latitude:  "37",
-				Longitude: "115",
-			},
-			expectCheck:        false,
-			expectedIsDanger:   false,
-			expectedErrContain: "user_id cannot be empty",
+			name:      "merge_read_only_field",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"latitude": "10.0"}`,
+			expectErr: "field latitude cannot be patched",
 		},
 		{
-			name: "invalid_latitude_empty",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "user_1",
-				Latitude:  "",
-				Longitude: "115",
+			name:      "merge_unknown_field",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"severity": 3}`,
+			expectErr: "field severity cannot be patched",
+		},
+		{
+			name:      "merge_no_changes",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"name": "old"}`,
+			expectErr: "no data for update",
+		},
+		{
+			name:      "merge_radius_not_integer",
+			patchType: service.PatchTypeMerge,
+			patch:     `{"radius": 10.5}`,
+			expectErr: "radius must be integer",
+		},
+		{
+			name:      "json_patch_status_with_reason",
+			patchType: service.PatchTypeJSON,
+			patch: `[{"op": "test", "path": "/status", "value": "active"},
+				{"op": "replace", "path": "/status", "value": "resolved"},
+				{"op": "add", "path": "/status_reason", "value": "fire is out"}]`,
+			check: func(t *testing.T, res *dto.IncidentAdminResponse) {
+				if res.Status != service.StatusResolved || res.IsActive {
+					t.Errorf("got status: %s, is_active: %v\n", res.Status, res.IsActive)
+				}
 			},
-			expectCheck:        false,
-			expectedIsDanger:   false,
-			expectedErrContain: "latitude incorrect parse",
+			expectTransit: true,
 		},
-
 		{
-			name: "вне всех зон",
-			checkBody: &dto.LocationCheckRequest{
-				UserID:    "user-out",
-				Latitude:  "55.9000",
-				Longitude: "37.8000",
+			name:      "json_patch_remove_description",
+			patchType: service.PatchTypeJSON,
+			patch:     `[{"op": "remove", "path": "/description"}]`,
+			check: func(t *testing.T, res *dto.IncidentAdminResponse) {
+				if res.Description != nil {
+					t.Errorf("description must be removed\n")
+				}
 			},
-			expectedIsDanger: false,
-			expectCheck:      true,
-			expectedIds:      []string{},
+		},
+		{
+			name:      "json_patch_test_failed",
+			patchType: service.PatchTypeJSON,
+			patch:     `[{"op": "test", "path": "/radius", "value": 1}]`,
+			expectErr: "patch test failed",
+		},
+		{
+			name:      "json_patch_remove_name",
+			patchType: service.PatchTypeJSON,
+			patch:     `[{"op": "remove", "path": "/name"}]`,
+			expectErr: "name cannot be null",
+		},
+		{
+			name:      "unsupported_type",
+			patchType: "application/json",
+			patch:     `{}`,
+			expectErr: "invalid patch",
 		},
 	}
 
@@ -1057,42 +1226,1755 @@ func TestService_LocationCheck(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockDb := repository.NewMockDb()
 			mockCache := repository.NewCacheMock()
-			mockWebhook := webhook_manager.NewMockWebhookManager()
-			svc := service.NewService(mockDb, mockCache, nil, mockWebhook)
+			svc := service.NewService(mockDb, mockCache, &config.Config{MaxRadius: 5000}, nil)
+			mockDb.Storage[id] = &entities.ReadIncident{
+				Id:          id,
+				Name:        "old",
+				Type:        "type",
+				Description: getStrPtr("description"),
+				Latitude:    "55.7",
+				Longitude:   "37.6",
+				Radius:      100,
+				Status:      service.StatusActive,
+				IsActive:    true,
+				Version:     1,
+			}
 
-			mockDb.Storage[inc1.Id] = inc1
-			mockDb.Storage[inc2.Id] = inc2
-			mockDb.Storage[inc3.Id] = inc3
-			mockDb.Storage[inc4.Id] = inc4
+			res, err := svc.PatchIncidentByID(context.Background(), id, tc.patchType, []byte(tc.patch), nil)
+			if tc.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("ERROR: got: %v, expect contains: %s\n", err, tc.expectErr)
+				}
+				if len(mockDb.Audit) != 0 {
+					t.Errorf("unexpected audit records: %d\n", len(mockDb.Audit))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			tc.check(t, res)
+			if tc.expectTransit != (len(mockDb.Transitions) == 1) {
+				t.Errorf("status transitions: got: %d, expect transition: %v\n", len(mockDb.Transitions), tc.expectTransit)
+			}
+			if tc.expectTransit && (mockDb.Transitions[0].Reason == nil || *mockDb.Transitions[0].Reason != "fire is out") {
+				t.Errorf("status reason must be stored in transition\n")
+			}
+			if len(mockDb.Audit) != 1 || mockDb.Audit[0].Action != service.AuditActionUpdate {
+				t.Errorf("expected one update audit record\n")
+			}
+		})
+	}
+}
 
-			res, err := svc.LocationCheck(context.Background(), tc.checkBody)
+func TestService_DeleteIncidentByID_IfMatch(t *testing.T) {
+	testCases := []struct {
+		name       string
+		stored     bool
+		ifMatch    *int
+		expectErr  string
+		expectGone bool
+	}{
+		{
+			name:       "version_equal",
+			stored:     true,
+			ifMatch:    getIntPtr(2),
+			expectGone: true,
+		},
+		{
+			name:      "version_mismatch",
+			stored:    true,
+			ifMatch:   getIntPtr(1),
+			expectErr: "precondition failed",
+		},
+		{
+			name:      "missing_incident_with_if_match",
+			ifMatch:   getIntPtr(1),
+			expectErr: "no rows",
+		},
+	}
 
-			if err != nil {
-				if tc.expectedErrContain != "" {
-					if !strings.Contains(err.Error(), tc.expectedErrContain) {
-						t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedErrContain)
-					}
-				} else {
-					t.Errorf("unexpected error: %s\n", err.Error())
-				}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+			if tc.stored {
+				mockDb.Storage["id"] = &entities.ReadIncident{Id: "id", Status: service.StatusActive, Version: 2}
 			}
-			if tc.expectCheck {
-				if _, ok := mockDb.Checks[res.ID]; !ok {
-					t.Errorf("check_id: %s, not contains in checks\n", res.ID)
-				}
-				if mockDb.Tx != nil {
-					if !mockDb.Tx.Committed {
-						t.Errorf("tx cannot commit\n")
-					}
-				} else {
-					t.Errorf("tx cannot start")
-				}
-			} else {
-				if mockDb.Tx != nil {
-					t.Errorf("tx cannot be started\n")
+
+			err := svc.DeleteIncidentByID(context.Background(), "id", tc.ifMatch)
+			if tc.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("ERROR: got: %v, expect contains: %s\n", err, tc.expectErr)
 				}
-				if res != nil {
-					t.Errorf("unexpected result\n")
+				if len(mockDb.Audit) != 0 {
+					t.Errorf("unexpected audit records: %d\n", len(mockDb.Audit))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			_, err = mockDb.GetInfoByIncidentID(context.Background(), "id", nil)
+			if tc.expectGone != (err != nil) {
+				t.Errorf("incident deleted: got: %v, expect: %v\n", err != nil, tc.expectGone)
+			}
+		})
+	}
+}
+
+func TestService_RestoreIncidentByID(t *testing.T) {
+	id := "restore-id"
+	deletedDate := time.Now().UTC()
+	testCases := []struct {
+		name          string
+		stored        *entities.ReadIncident
+		req           *dto.RestoreRequest
+		version       *int
+		expectStatus  string
+		expectActive  bool
+		expectInCache bool
+		expectTransit bool
+		expectErr     bool
+		containErr    string
+	}{
+		{
+			name:          "archived_default_active",
+			stored:        &entities.ReadIncident{Id: id, Status: service.StatusArchived},
+			req:           &dto.RestoreRequest{},
+			expectStatus:  service.StatusActive,
+			expectActive:  true,
+			expectInCache: true,
+			expectTransit: true,
+		},
+		{
+			name:          "archived_to_monitoring_with_reason",
+			stored:        &entities.ReadIncident{Id: id, Status: service.StatusArchived},
+			req:           &dto.RestoreRequest{Status: getStrPtr(service.StatusMonitoring), Reason: getStrPtr("false archive")},
+			expectStatus:  service.StatusMonitoring,
+			expectActive:  true,
+			expectInCache: true,
+			expectTransit: true,
+		},
+		{
+			name:          "deleted_keeps_last_status",
+			stored:        &entities.ReadIncident{Id: id, Status: service.StatusActive, DeletedDate: &deletedDate},
+			req:           &dto.RestoreRequest{},
+			expectStatus:  service.StatusActive,
+			expectActive:  true,
+			expectInCache: true,
+		},
+		{
+			name:          "deleted_to_resolved",
+			stored:        &entities.ReadIncident{Id: id, Status: service.StatusActive, DeletedDate: &deletedDate},
+			req:           &dto.RestoreRequest{Status: getStrPtr(service.StatusResolved)},
+			expectStatus:  service.StatusResolved,
+			expectTransit: true,
+		},
+		{
+			name:       "active_not_restorable",
+			stored:     &entities.ReadIncident{Id: id, Status: service.StatusActive},
+			req:        &dto.RestoreRequest{},
+			expectErr:  true,
+			containErr: "restore not allowed",
+		},
+		{
+			name:       "archived_to_archived",
+			stored:     &entities.ReadIncident{Id: id, Status: service.StatusArchived},
+			req:        &dto.RestoreRequest{Status: getStrPtr(service.StatusArchived)},
+			expectErr:  true,
+			containErr: "restore not allowed",
+		},
+		{
+			name:       "unknown_status",
+			stored:     &entities.ReadIncident{Id: id, Status: service.StatusArchived},
+			req:        &dto.RestoreRequest{Status: getStrPtr("random")},
+			expectErr:  true,
+			containErr: "invalid status",
+		},
+		{
+			name:          "archived_version_match",
+			stored:        &entities.ReadIncident{Id: id, Status: service.StatusArchived, Version: 3},
+			req:           &dto.RestoreRequest{},
+			version:       getIntPtr(3),
+			expectStatus:  service.StatusActive,
+			expectActive:  true,
+			expectInCache: true,
+			expectTransit: true,
+		},
+		{
+			name:       "archived_version_mismatch",
+			stored:     &entities.ReadIncident{Id: id, Status: service.StatusArchived, Version: 3},
+			req:        &dto.RestoreRequest{},
+			version:    getIntPtr(2),
+			expectErr:  true,
+			containErr: "precondition failed",
+		},
+		{
+			name:       "deleted_version_mismatch",
+			stored:     &entities.ReadIncident{Id: id, Status: service.StatusActive, DeletedDate: &deletedDate, Version: 4},
+			req:        &dto.RestoreRequest{},
+			version:    getIntPtr(3),
+			expectErr:  true,
+			containErr: "precondition failed",
+		},
+		{
+			name:       "not_found",
+			req:        &dto.RestoreRequest{},
+			expectErr:  true,
+			containErr: "no rows",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			mockCache := repository.NewCacheMock()
+			svc := service.NewService(mockDb, mockCache, &config.Config{}, nil)
+			if tc.stored != nil {
+				mockDb.Storage[id] = tc.stored
+			}
+
+			res, err := svc.RestoreIncidentByID(context.Background(), id, tc.req, tc.version)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil\n")
+				}
+				if !strings.Contains(err.Error(), tc.containErr) {
+					t.Errorf("ERROR: got: %s, expect contains: %s\n", err.Error(), tc.containErr)
+				}
+				if len(mockDb.Audit) != 0 {
+					t.Errorf("unexpected audit records: %d\n", len(mockDb.Audit))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			if res.Status != tc.expectStatus {
+				t.Errorf("status: got: %s, expect: %s\n", res.Status, tc.expectStatus)
+			}
+			if res.IsActive != tc.expectActive {
+				t.Errorf("is_active: got: %v, expect: %v\n", res.IsActive, tc.expectActive)
+			}
+			if _, err := mockDb.GetInfoByIncidentID(context.Background(), id, nil); err != nil {
+				t.Errorf("restored incident must be readable: %s\n", err.Error())
+			}
+			_, err = mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, id)
+			if tc.expectInCache != (err == nil) {
+				t.Errorf("in cache: got: %v, expect: %v\n", err == nil, tc.expectInCache)
+			}
+			if tc.expectTransit != (len(mockDb.Transitions) == 1) {
+				t.Errorf("status transitions: got: %d, expect transition: %v\n", len(mockDb.Transitions), tc.expectTransit)
+			}
+			if len(mockDb.Audit) != 1 || mockDb.Audit[0].Action != service.AuditActionRestore {
+				t.Errorf("expected one restore audit record\n")
+			}
+			if !mockDb.Tx.Committed {
+				t.Errorf("transaction must be committed\n")
+			}
+		})
+	}
+}
+
+func TestService_PurgeDeletedIncidents(t *testing.T) {
+	old := time.Now().UTC().Add(-31 * 24 * time.Hour)
+	recent := time.Now().UTC().Add(-time.Hour)
+	mockDb := repository.NewMockDb()
+	mockDb.Storage["old"] = &entities.ReadIncident{Id: "old", DeletedDate: &old}
+	mockDb.Storage["recent"] = &entities.ReadIncident{Id: "recent", DeletedDate: &recent}
+	mockDb.Storage["alive"] = &entities.ReadIncident{Id: "alive"}
+	svc := service.NewService(mockDb, nil, &config.Config{TrashRetentionDays: 30}, nil)
+
+	count, err := svc.PurgeDeletedIncidents(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if count != 1 {
+		t.Errorf("purged: got: %d, expect: 1\n", count)
+	}
+	if _, ok := mockDb.Storage["old"]; ok {
+		t.Errorf("old tombstone must be purged\n")
+	}
+	if _, ok := mockDb.Storage["recent"]; !ok {
+		t.Errorf("recent tombstone must stay in trash\n")
+	}
+	if _, ok := mockDb.Storage["alive"]; !ok {
+		t.Errorf("alive incident must stay\n")
+	}
+}
+
+func TestService_GetPagination_Cursor(t *testing.T) {
+	mockDb := repository.NewMockDb()
+	names := []string{"e", "b", "d", "a", "c"}
+	for i, name := range names {
+		id := fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)
+		mockDb.Storage[id] = &entities.ReadIncident{Id: id, Name: name, Type: "fire", Radius: 100 * (i + 1), Status: service.StatusActive}
+	}
+	mockDb.Storage["deleted"] = &entities.ReadIncident{Id: "deleted", Name: "0", Type: "fire", DeletedDate: getTimePtr(time.Now())}
+	mockDb.Storage["other"] = &entities.ReadIncident{Id: "other", Name: "z", Type: "flood"}
+	svc := service.NewService(mockDb, nil, &config.Config{MaxRowsInPage: 10}, nil)
+
+	got := []string{}
+	cursor := ""
+	for range 5 {
+		res, err := svc.GetPagination(context.Background(), &dto.PaginationQueryParams{
+			Types:  []string{"fire"},
+			Sort:   "name",
+			Limit:  getIntPtr(2),
+			Cursor: cursor,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s\n", err.Error())
+		}
+		assert.Equal(t, 5, res.TotalIncidents)
+		assert.Equal(t, 3, res.TotalPages)
+		for _, incident := range res.Incidents {
+			got = append(got, incident.Name)
+		}
+		cursor = res.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, got)
+
+	res, err := svc.GetPagination(context.Background(), &dto.PaginationQueryParams{
+		Types: []string{"fire"},
+		Sort:  "-radius",
+		Limit: getIntPtr(10),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Empty(t, res.NextCursor)
+	assert.Len(t, res.Incidents, 5)
+	assert.Equal(t, 500, res.Incidents[0].Radius)
+
+	testCases := []struct {
+		name          string
+		query         *dto.PaginationQueryParams
+		expectedError string
+	}{
+		{
+			name:          "limit above max rows in page",
+			query:         &dto.PaginationQueryParams{Limit: getIntPtr(11)},
+			expectedError: "limit cannot be > 10",
+		},
+		{
+			name:          "unknown sort",
+			query:         &dto.PaginationQueryParams{Sort: "id"},
+			expectedError: "invalid sort",
+		},
+		{
+			name:          "page with cursor",
+			query:         &dto.PaginationQueryParams{PageNum: getIntPtr(1), Cursor: "abc"},
+			expectedError: "page cannot be combined with cursor",
+		},
+		{
+			name:          "broken cursor",
+			query:         &dto.PaginationQueryParams{Cursor: "%%%"},
+			expectedError: "invalid cursor",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.GetPagination(context.Background(), tc.query)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("error: got: %v, expect: %s\n", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestService_SearchIncidents(t *testing.T) {
+	mockDb := repository.NewMockDb()
+	mockDb.Storage["00000000-0000-0000-0000-000000000001"] = &entities.ReadIncident{
+		Id: "00000000-0000-0000-0000-000000000001", Name: "Прорыв трубы", Type: "utility", Status: service.StatusActive,
+	}
+	mockDb.Storage["00000000-0000-0000-0000-000000000002"] = &entities.ReadIncident{
+		Id: "00000000-0000-0000-0000-000000000002", Name: "Авария", Type: "utility", Status: service.StatusResolved,
+		Description: getStrPtr("прорыв трубы на улице"),
+	}
+	mockDb.Storage["00000000-0000-0000-0000-000000000003"] = &entities.ReadIncident{
+		Id: "00000000-0000-0000-0000-000000000003", Name: "Пожар", Type: "fire", Status: service.StatusActive,
+	}
+	svc := service.NewService(mockDb, nil, &config.Config{MaxRowsInPage: 10}, nil)
+
+	res, err := svc.SearchIncidents(context.Background(), &dto.SearchQueryParams{Query: "прорыв"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, 2, res.TotalResults)
+	assert.Equal(t, "Прорыв трубы", res.Results[0].Name, "name match must rank above description match")
+
+	res, err = svc.SearchIncidents(context.Background(), &dto.SearchQueryParams{
+		Query:                 "прорыв",
+		PaginationQueryParams: dto.PaginationQueryParams{Statuses: []string{service.StatusResolved}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, 1, res.TotalResults)
+	assert.Equal(t, "Авария", res.Results[0].Name)
+
+	res, err = svc.SearchIncidents(context.Background(), &dto.SearchQueryParams{
+		Query:                 "наводнение",
+		PaginationQueryParams: dto.PaginationQueryParams{PageNum: getIntPtr(1)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Empty(t, res.Results)
+
+	_, err = svc.SearchIncidents(context.Background(), &dto.SearchQueryParams{
+		Query:                 "прорыв",
+		PaginationQueryParams: dto.PaginationQueryParams{Statuses: []string{"unknown"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid status") {
+		t.Errorf("error: got: %v, expect: invalid status\n", err)
+	}
+}
+
+func TestService_ImportIncidents(t *testing.T) {
+	cfg := &config.Config{MaxRadius: 5000, DefaultRadius: 100}
+	doc := "lat,lon,name,type,radius,status\n" +
+		"55.75,37.61,Пожар,fire,300,\n" +
+		"55.76,37.62,Существующий,flood,,\n" +
+		"55.77,37.63,Пожар,fire,,\n" +
+		"95.00,37.64,Ошибка,fire,,\n" +
+		"55.78,37.65,Статус,fire,,unknown\n"
+
+	testCases := []struct {
+		name            string
+		mode            string
+		doc             string
+		expectedCreated int
+		expectedSkipped int
+		expectedFailed  int
+		expectedStatus  []string
+	}{
+		{
+			name:            "atomic with failed rows writes nothing",
+			mode:            service.ImportModeAtomic,
+			doc:             doc,
+			expectedSkipped: 3,
+			expectedFailed:  2,
+			expectedStatus:  []string{service.ImportRowSkipped, service.ImportRowSkipped, service.ImportRowSkipped, service.ImportRowFailed, service.ImportRowFailed},
+		},
+		{
+			name:            "best effort saves valid rows",
+			mode:            service.ImportModeBestEffort,
+			doc:             doc,
+			expectedCreated: 1,
+			expectedSkipped: 2,
+			expectedFailed:  2,
+			expectedStatus:  []string{service.ImportRowCreated, service.ImportRowSkipped, service.ImportRowSkipped, service.ImportRowFailed, service.ImportRowFailed},
+		},
+		{
+			name:            "atomic without failures",
+			mode:            "",
+			doc:             "lat,lon,name,type\n55.75,37.61,Пожар,fire\n55.76,37.62,Существующий,flood\n",
+			expectedCreated: 1,
+			expectedSkipped: 1,
+			expectedStatus:  []string{service.ImportRowCreated, service.ImportRowSkipped},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			mockDb.Storage["existing"] = &entities.ReadIncident{Id: "existing", Name: "Существующий"}
+			svc := service.NewService(mockDb, nil, cfg, nil)
+
+			report, err := svc.ImportIncidents(context.Background(), "csv", tc.mode, strings.NewReader(tc.doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, tc.expectedCreated, report.Created)
+			assert.Equal(t, tc.expectedSkipped, report.Skipped)
+			assert.Equal(t, tc.expectedFailed, report.Failed)
+			statuses := []string{}
+			for _, row := range report.Rows {
+				statuses = append(statuses, row.Status)
+			}
+			assert.Equal(t, tc.expectedStatus, statuses)
+			assert.Len(t, mockDb.Storage, 1+tc.expectedCreated)
+
+			created := 0
+			for _, record := range mockDb.Audit {
+				if record.Action == service.AuditActionCreate {
+					created++
+				}
+			}
+			assert.Equal(t, tc.expectedCreated, created)
+		})
+	}
+
+	svc := service.NewService(repository.NewMockDb(), nil, cfg, nil)
+	_, err := svc.ImportIncidents(context.Background(), "csv", "fast", strings.NewReader(doc))
+	if err == nil || !strings.Contains(err.Error(), "invalid mode") {
+		t.Errorf("error: got: %v, expect: invalid mode\n", err)
+	}
+}
+
+func TestService_ExportIncidents(t *testing.T) {
+	mockDb := repository.NewMockDb()
+	mockDb.Storage["00000000-0000-0000-0000-000000000001"] = &entities.ReadIncident{
+		Id: "00000000-0000-0000-0000-000000000001", Name: "Пожар", Type: "fire", Latitude: "55.75", Longitude: "37.61", Status: service.StatusActive,
+	}
+	mockDb.Storage["00000000-0000-0000-0000-000000000002"] = &entities.ReadIncident{
+		Id: "00000000-0000-0000-0000-000000000002", Name: "Потоп", Type: "flood", Latitude: "55.76", Longitude: "37.62", Status: service.StatusActive,
+	}
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	buf := &strings.Builder{}
+	err := svc.ExportIncidents(context.Background(), "csv", &dto.PaginationQueryParams{Types: []string{"fire"}}, buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], "55.75,37.61,00000000-0000-0000-0000-000000000001,Пожар,fire,"))
+
+	err = svc.ExportIncidents(context.Background(), "xlsx", &dto.PaginationQueryParams{}, &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "unsupported export format") {
+		t.Errorf("error: got: %v, expect: unsupported export format\n", err)
+	}
+}
+
+func TestService_ExportChecks(t *testing.T) {
+	now := time.Now().UTC()
+	mockDb := repository.NewMockDb()
+	mockDb.Checks["recent"] = &repository.Check{UserID: "u1", Latitude: "55.75", Longitude: "37.61", IsDanger: true, DangerIds: []string{"a", "b"}, CreatedDate: now.Add(-time.Hour)}
+	mockDb.Checks["old"] = &repository.Check{UserID: "u2", Latitude: "55.75", Longitude: "37.61", CreatedDate: now.Add(-48 * time.Hour)}
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	buf := &strings.Builder{}
+	if err := svc.ExportChecks(context.Background(), "ndjson", nil, nil, buf); err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1, "default window is the last day")
+	assert.Contains(t, lines[0], `"detected_incident_ids":"a,b"`)
+
+	from := now.Add(-40 * 24 * time.Hour)
+	testCases := []struct {
+		name          string
+		from, to      *time.Time
+		expectedError string
+	}{
+		{name: "window too long", from: &from, expectedError: "export window cannot be longer than 31 days"},
+		{name: "reversed window", from: getTimePtr(now), to: getTimePtr(now.Add(-time.Hour)), expectedError: "from cannot be after to"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := svc.ExportChecks(context.Background(), "csv", tc.from, tc.to, &strings.Builder{})
+			if err == nil || err.Error() != tc.expectedError {
+				t.Errorf("error: got: %v, expect: %s\n", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestService_IncidentExposure(t *testing.T) {
+	const id = "00000000-0000-0000-0000-000000000001"
+	now := time.Now().UTC()
+	mockDb := repository.NewMockDb()
+	mockDb.Storage[id] = &entities.ReadIncident{Id: id, Name: "fire", Type: "fire", Status: service.StatusActive, IsActive: true, Latitude: "55.75", Longitude: "37.61", Radius: 100}
+	mockDb.Checks["u1_far"] = &repository.Check{UserID: "u1", Latitude: "55.7505", Longitude: "37.61", IsDanger: true, DangerIds: []string{id}, CreatedDate: now.Add(-3 * time.Hour)}
+	mockDb.Checks["u1_near"] = &repository.Check{UserID: "u1", Latitude: "55.7501", Longitude: "37.61", IsDanger: true, DangerIds: []string{id}, CreatedDate: now.Add(-time.Hour)}
+	mockDb.Checks["u2"] = &repository.Check{UserID: "u2", Latitude: "55.75", Longitude: "37.61", IsDanger: true, DangerIds: []string{"other", id}, CreatedDate: now.Add(-2 * time.Hour)}
+	mockDb.Checks["other_incident"] = &repository.Check{UserID: "u3", Latitude: "55.75", Longitude: "37.61", IsDanger: true, DangerIds: []string{"other"}, CreatedDate: now}
+	mockDb.Checks["safe"] = &repository.Check{UserID: "u4", Latitude: "55.76", Longitude: "37.61", CreatedDate: now}
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	res, err := svc.GetIncidentExposure(context.Background(), id)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, 2, res.UsersCount)
+	if !assert.Len(t, res.Users, 2) {
+		return
+	}
+	assert.Equal(t, "u1", res.Users[0].UserID, "users are ordered by first check")
+	assert.Equal(t, 2, res.Users[0].Checks)
+	assert.Equal(t, now.Add(-3*time.Hour), res.Users[0].FirstSeen)
+	assert.Equal(t, now.Add(-time.Hour), res.Users[0].LastSeen)
+	assert.InDelta(t, 11.12, res.Users[0].MinDistance, 0.01)
+	assert.Equal(t, "u2", res.Users[1].UserID)
+	assert.Zero(t, res.Users[1].MinDistance)
+
+	buf := &strings.Builder{}
+	if err := svc.ExportIncidentExposure(context.Background(), id, "csv", buf); err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "latitude,longitude,user_id,first_seen,last_seen,checks,min_distance_meters", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "55.7501,37.61,u1,"), "user point is the closest check: %s", lines[1])
+
+	_, err = svc.GetIncidentExposure(identity.WithTenant(context.Background(), "moscow"), id)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = svc.GetIncidentExposure(context.Background(), "00000000-0000-0000-0000-000000000002")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestService_UserChecks(t *testing.T) {
+	now := time.Now().UTC()
+	newMockDb := func() *repository.MockDbRepository {
+		mockDb := repository.NewMockDb()
+		mockDb.Checks["safe"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now.Add(-3 * time.Hour)}
+		mockDb.Checks["danger"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", IsDanger: true, DangerIds: []string{"a"}, CreatedDate: now.Add(-2 * time.Hour)}
+		mockDb.Checks["recent"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now.Add(-time.Hour)}
+		mockDb.Checks["other_user"] = &repository.Check{UserID: "u2", Latitude: "55.75", Longitude: "37.61", CreatedDate: now}
+		mockDb.Checks["other_tenant"] = &repository.Check{TenantID: "moscow", UserID: "u1", Latitude: "55.75", Longitude: "37.61", CreatedDate: now}
+		return mockDb
+	}
+	ctx := context.Background()
+	svc := service.NewService(newMockDb(), nil, &config.Config{MaxRowsInPage: 2}, nil)
+
+	res, err := svc.GetUserChecks(ctx, &dto.UserChecksQueryParams{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, 3, res.CountChecks)
+	assert.Equal(t, "recent", res.Checks[0].ID, "newest checks go first")
+	res, err = svc.GetUserChecks(ctx, &dto.UserChecksQueryParams{UserID: "u1", PageNum: getIntPtr(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.CountChecks)
+	res, err = svc.GetUserChecks(ctx, &dto.UserChecksQueryParams{UserID: "u1", DangerousOnly: true, From: getTimePtr(now.Add(-150 * time.Minute))})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Checks, 1) {
+		assert.Equal(t, []string{"a"}, res.Checks[0].DetectedIncidentIDs)
+	}
+
+	testCases := []struct {
+		name          string
+		mode          string
+		expectedUsers []string
+	}{
+		{name: "erase_by_default", expectedUsers: []string{"u2"}},
+		{name: "anonymize", mode: service.ForgetModeAnonymize, expectedUsers: []string{"anonymized", "anonymized", "anonymized", "u2"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := newMockDb()
+			mockCache := repository.NewCacheMock()
+			wm := webhook_manager.NewMockWebhookManager()
+			wm.AddToQueue(dto.LocationCheckResponse{UserID: "u1", IsDanger: true}, identity.DefaultTenant, "", "")
+			wm.AddToQueue(dto.LocationCheckResponse{UserID: "u1", IsDanger: true}, "moscow", "", "")
+			wm.AddToQueue(dto.LocationCheckResponse{UserID: "u2", IsDanger: true}, identity.DefaultTenant, "", "")
+			svc := service.NewService(mockDb, mockCache, &config.Config{}, wm)
+
+			res, err := svc.ForgetUser(ctx, "u1", tc.mode)
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, int64(3), res.ChecksAffected)
+			assert.Equal(t, []string{identity.DefaultTenant + ":u1"}, mockCache.ForgottenUsers)
+			assert.Len(t, wm.Storage, 2, "queued webhooks of the user are removed")
+			users := []string{}
+			for _, check := range mockDb.Checks {
+				if check.TenantID == "" {
+					user, _, _ := strings.Cut(check.UserID, "-")
+					users = append(users, user)
+				}
+			}
+			slices.Sort(users)
+			assert.Equal(t, tc.expectedUsers, users)
+			if tc.mode == service.ForgetModeAnonymize {
+				ids := map[string]struct{}{}
+				for _, id := range []string{"safe", "danger", "recent"} {
+					ids[mockDb.Checks[id].UserID] = struct{}{}
+					assert.Equal(t, "55.75", mockDb.Checks[id].Latitude)
+					assert.Equal(t, "37.61", mockDb.Checks[id].Longitude)
+				}
+				assert.Len(t, ids, 3, "every check gets its own random id")
+			}
+			assert.Equal(t, "u1", mockDb.Checks["other_tenant"].UserID, "checks of other tenants are kept")
+		})
+	}
+
+	_, err = svc.ForgetUser(ctx, "u1", "hide")
+	assert.EqualError(t, err, "invalid mode: must be one of erase, anonymize")
+	readOnly := &identity.Identity{Role: identity.RoleAdmin, Scopes: []string{identity.ScopeChecksRead}}
+	_, err = svc.ForgetUser(identity.WithIdentity(ctx, readOnly), "u1", "")
+	assert.EqualError(t, err, "permission denied: role admin cannot checks.forget")
+}
+
+func TestService_RunExclusive(t *testing.T) {
+	mockDb := repository.NewMockDb()
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+	ctx := context.Background()
+
+	runs := []string{}
+	err := svc.RunExclusive(ctx, "checks retention", func(ctx context.Context) error {
+		runs = append(runs, "checks retention")
+		// another replica starting the same job while this one runs
+		err := svc.RunExclusive(ctx, "checks retention", func(ctx context.Context) error {
+			runs = append(runs, "checks retention again")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return svc.RunExclusive(ctx, "coarsen checks", func(ctx context.Context) error {
+			runs = append(runs, "coarsen checks")
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"checks retention", "coarsen checks"}, runs)
+	assert.Empty(t, mockDb.Locks, "locks are released after the run")
+}
+
+func TestService_ChecksRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	newMockDb := func() *repository.MockDbRepository {
+		mockDb := repository.NewMockDb()
+		old := &entities.ChecksPartition{Name: "checks_old", From: month.AddDate(0, -13, 0), To: month.AddDate(0, -12, 0)}
+		mockDb.Partitions[old.Name] = old
+		mockDb.Checks["old"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: old.From.Add(time.Hour)}
+		mockDb.Checks["old_default"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: old.From.Add(-time.Hour)}
+		mockDb.Checks["week"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now.AddDate(0, 0, -7)}
+		mockDb.Checks["recent"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now}
+		return mockDb
+	}
+
+	mockDb := newMockDb()
+	svc := service.NewService(mockDb, nil, &config.Config{ChecksPartitionInterval: config.PartitionIntervalMonth, ChecksPartitionsAhead: 2}, nil)
+	created, err := svc.EnsureChecksPartitions(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, 3, created)
+	assert.Contains(t, mockDb.Partitions, "checks_p"+month.Format("200601"))
+	created, err = svc.EnsureChecksPartitions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created, "existing partitions are not created again")
+	svc = service.NewService(mockDb, nil, &config.Config{ChecksPartitionInterval: config.PartitionIntervalDay, ChecksPartitionsAhead: 2}, nil)
+	created, err = svc.EnsureChecksPartitions(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created, "days covered by monthly partitions are skipped")
+
+	removed, err := service.NewService(mockDb, nil, &config.Config{}, nil).ApplyChecksRetention(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed, "checks are kept forever by default")
+	assert.Len(t, mockDb.Checks, 4)
+
+	testCases := []struct {
+		mode             string
+		expectedArchived []string
+		expectedForgot   int64
+	}{
+		{mode: config.RetentionModeArchive, expectedArchived: []string{"checks_old"}, expectedForgot: 4},
+		{mode: config.RetentionModeDrop, expectedForgot: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			mockDb := newMockDb()
+			svc := service.NewService(mockDb, nil, &config.Config{ChecksRetentionDays: 365, ChecksRetentionMode: tc.mode}, nil)
+
+			removed, err := svc.ApplyChecksRetention(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, 1, removed)
+			assert.Equal(t, tc.expectedArchived, mockDb.Archived)
+			assert.NotContains(t, mockDb.Partitions, "checks_old")
+			assert.NotContains(t, mockDb.Checks, "old")
+			assert.NotContains(t, mockDb.Checks, "old_default", "old checks of the default partition are removed")
+			assert.Contains(t, mockDb.Checks, "week")
+
+			res, err := svc.ForgetUser(ctx, "u1", "")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedForgot, res.ChecksAffected, "archived checks are erased as well")
+			assert.Empty(t, mockDb.ArchivedChecks)
+		})
+	}
+
+	mockDb = newMockDb()
+	mockDb.ArchivedChecks["archived"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now.AddDate(-2, 0, 0)}
+	svc = service.NewService(mockDb, nil, &config.Config{ChecksCoarsenAfterDays: 1, ChecksCoarsenDecimals: 3}, nil)
+	count, err := svc.CoarsenChecks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	assert.Equal(t, "55.751", mockDb.ArchivedChecks["archived"].Latitude, "archived checks are coarsened as well")
+	assert.Equal(t, "55.751", mockDb.Checks["week"].Latitude)
+	assert.Equal(t, "37.612", mockDb.Checks["week"].Longitude)
+	assert.Equal(t, "55.7512345", mockDb.Checks["recent"].Latitude, "recent checks keep precise coordinates")
+	count, err = svc.CoarsenChecks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count, "coarsened checks are not changed again")
+
+	// a precise check behind the progress mark shows that history is not scanned again
+	mockDb.Checks["behind"] = &repository.Check{UserID: "u1", Latitude: "55.7512345", Longitude: "37.6123456", CreatedDate: now.AddDate(0, 0, -3)}
+	count, err = svc.CoarsenChecks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.Equal(t, "55.7512345", mockDb.Checks["behind"].Latitude)
+	svc = service.NewService(mockDb, nil, &config.Config{ChecksCoarsenAfterDays: 1, ChecksCoarsenDecimals: 2}, nil)
+	count, err = svc.CoarsenChecks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count, "fewer decimals round the whole history again")
+	assert.Equal(t, "55.75", mockDb.Checks["behind"].Latitude)
+}
+
+func TestService_BulkIncidents(t *testing.T) {
+	const (
+		fireActive   = "00000000-0000-0000-0000-000000000001"
+		fireResolved = "00000000-0000-0000-0000-000000000002"
+		floodActive  = "00000000-0000-0000-0000-000000000003"
+		missing      = "00000000-0000-0000-0000-000000000009"
+	)
+	cfg := &config.Config{MaxRadius: 5000, DefaultRadius: 100}
+	resolved := service.StatusResolved
+
+	testCases := []struct {
+		name             string
+		req              *dto.BulkRequest
+		expectedError    string
+		expectedAffected []string
+		expectedSkipped  []string
+		expectedStatus   map[string]string
+		expectedDeleted  []string
+	}{
+		{
+			name: "resolve by filter",
+			req: &dto.BulkRequest{
+				Operation: service.BulkOperationUpdateStatus,
+				Filter:    &dto.BulkFilter{Types: []string{"fire"}},
+				Status:    &resolved,
+			},
+			expectedAffected: []string{fireActive},
+			expectedSkipped:  []string{fireResolved},
+			expectedStatus:   map[string]string{fireActive: service.StatusResolved, floodActive: service.StatusActive},
+		},
+		{
+			name: "dry run writes nothing",
+			req: &dto.BulkRequest{
+				Operation: service.BulkOperationUpdateStatus,
+				Filter:    &dto.BulkFilter{Types: []string{"fire"}},
+				Status:    &resolved,
+				DryRun:    true,
+			},
+			expectedAffected: []string{fireActive},
+			expectedSkipped:  []string{fireResolved},
+			expectedStatus:   map[string]string{fireActive: service.StatusActive},
+		},
+		{
+			name: "archive by ids",
+			req: &dto.BulkRequest{
+				Operation: service.BulkOperationArchive,
+				IDs:       []string{floodActive, missing, floodActive},
+			},
+			expectedAffected: []string{floodActive},
+			expectedSkipped:  []string{missing},
+			expectedStatus:   map[string]string{floodActive: service.StatusArchived, fireActive: service.StatusActive},
+		},
+		{
+			name: "delete by ids",
+			req: &dto.BulkRequest{
+				Operation: service.BulkOperationDelete,
+				IDs:       []string{fireActive, fireResolved},
+			},
+			expectedAffected: []string{fireActive, fireResolved},
+			expectedSkipped:  []string{},
+			expectedDeleted:  []string{fireActive, fireResolved},
+		},
+		{
+			name: "change radius",
+			req: &dto.BulkRequest{
+				Operation: service.BulkOperationChangeRadius,
+				Filter:    &dto.BulkFilter{RadiusMax: getIntPtr(100)},
+				Radius:    getIntPtr(100),
+			},
+			expectedAffected: []string{fireActive},
+			expectedSkipped:  []string{fireResolved, floodActive},
+		},
+		{
+			name:          "ids with filter",
+			req:           &dto.BulkRequest{Operation: service.BulkOperationDelete, IDs: []string{fireActive}, Filter: &dto.BulkFilter{Types: []string{"fire"}}},
+			expectedError: "ids cannot be combined with filter",
+		},
+		{
+			name:          "empty filter",
+			req:           &dto.BulkRequest{Operation: service.BulkOperationDelete, Filter: &dto.BulkFilter{}},
+			expectedError: "filter cannot be empty",
+		},
+		{
+			name:          "status with archive",
+			req:           &dto.BulkRequest{Operation: service.BulkOperationArchive, IDs: []string{fireActive}, Status: &resolved},
+			expectedError: "status cannot be used with archive",
+		},
+		{
+			name:          "unknown operation",
+			req:           &dto.BulkRequest{Operation: "rename", IDs: []string{fireActive}},
+			expectedError: "invalid operation",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			mockDb.Storage[fireActive] = &entities.ReadIncident{Id: fireActive, Name: "Пожар", Type: "fire", Radius: 50, Status: service.StatusActive, IsActive: true}
+			mockDb.Storage[fireResolved] = &entities.ReadIncident{Id: fireResolved, Name: "Пожар 2", Type: "fire", Radius: 100, Status: service.StatusResolved}
+			mockDb.Storage[floodActive] = &entities.ReadIncident{Id: floodActive, Name: "Потоп", Type: "flood", Radius: 100, Status: service.StatusActive, IsActive: true}
+			svc := service.NewService(mockDb, nil, cfg, nil)
+
+			res, err := svc.BulkIncidents(context.Background(), tc.req)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("error: got: %v, expect: %s\n", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, tc.expectedAffected, res.AffectedIDs)
+			skipped := []string{}
+			for _, item := range res.Skipped {
+				skipped = append(skipped, item.ID)
+			}
+			assert.Equal(t, tc.expectedSkipped, skipped)
+			for id, status := range tc.expectedStatus {
+				assert.Equal(t, status, mockDb.Storage[id].Status, id)
+			}
+			for _, id := range tc.expectedDeleted {
+				assert.NotNil(t, mockDb.Storage[id].DeletedDate, id)
+			}
+			if tc.req.Operation == service.BulkOperationChangeRadius {
+				assert.Equal(t, 100, mockDb.Storage[fireActive].Radius)
+			}
+			if tc.req.DryRun {
+				assert.Empty(t, mockDb.Audit)
+			} else {
+				assert.Len(t, mockDb.Audit, len(tc.expectedAffected))
+			}
+		})
+	}
+}
+
+func TestService_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	mockDb := repository.NewMockDb()
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	issued, err := svc.IssueAPIKey(ctx, &dto.APIKeyIssueRequest{
+		Name:   "ops",
+		Role:   identity.RoleOperator,
+		Scopes: []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite, identity.ScopeIncidentsRead},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.True(t, strings.HasPrefix(issued.Key, issued.Prefix+"_"))
+	assert.Equal(t, []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite}, issued.Scopes)
+	assert.Len(t, mockDb.APIKeys[issued.ID].KeyHash, 64, "only sha256 of the key is stored")
+
+	id, err := svc.AuthenticateAPIKey(ctx, issued.Key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, "api-key:ops", id.Subject)
+	assert.Equal(t, identity.RoleOperator, id.Role)
+	assert.Equal(t, identity.DefaultTenant, id.Tenant)
+	assert.True(t, id.HasScope(identity.ScopeIncidentsWrite))
+	assert.False(t, id.HasScope(identity.ScopeKeysAdmin))
+	assert.NotNil(t, mockDb.APIKeys[issued.ID].LastUsedAt)
+
+	rotated, err := svc.RotateAPIKey(ctx, issued.ID, &dto.APIKeyRotateRequest{GraceMinutes: getIntPtr(10)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.NotEqual(t, issued.Key, rotated.Key)
+	assert.Equal(t, issued.Scopes, rotated.Scopes)
+	assert.Equal(t, identity.RoleOperator, rotated.Role)
+	_, err = svc.AuthenticateAPIKey(ctx, issued.Key)
+	assert.NoError(t, err, "old key works during grace period")
+
+	mockDb.APIKeys[issued.ID].ExpiresAt = getTimePtr(time.Now().UTC().Add(-time.Second))
+	_, err = svc.AuthenticateAPIKey(ctx, issued.Key)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+
+	assert.NoError(t, svc.RevokeAPIKey(ctx, rotated.ID))
+	_, err = svc.AuthenticateAPIKey(ctx, rotated.Key)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+
+	keys, err := svc.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	statuses := map[string]string{}
+	for _, key := range keys {
+		statuses[key.ID] = key.Status
+	}
+	assert.Equal(t, map[string]string{issued.ID: dto.APIKeyStatusExpired, rotated.ID: dto.APIKeyStatusRevoked}, statuses)
+
+	moscowKey, err := svc.IssueAPIKey(ctx, &dto.APIKeyIssueRequest{Name: "moscow", Role: identity.RoleViewer, Scopes: []string{identity.ScopeStatsRead}, Tenant: "moscow"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, "moscow", moscowKey.Tenant)
+	moscowID, err := svc.AuthenticateAPIKey(ctx, moscowKey.Key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, "moscow", moscowID.Tenant)
+	moscowAdmin := &identity.Identity{Subject: "api-key:moscow-admin", Role: identity.RoleAdmin, Tenant: "moscow", Scopes: identity.Scopes}
+	keys, err = svc.ListAPIKeys(identity.WithIdentity(ctx, moscowAdmin))
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 1, "keys of other tenants are not listed") {
+		assert.Equal(t, moscowKey.ID, keys[0].ID)
+	}
+
+	testCases := []struct {
+		name          string
+		call          func() error
+		expectedError string
+	}{
+		{
+			name: "unknown scope",
+			call: func() error {
+				_, err := svc.IssueAPIKey(ctx, &dto.APIKeyIssueRequest{Name: "x", Role: identity.RoleViewer, Scopes: []string{"incidents:delete"}})
+				return err
+			},
+			expectedError: `invalid scope "incidents:delete"`,
+		},
+		{
+			name: "unknown role",
+			call: func() error {
+				_, err := svc.IssueAPIKey(ctx, &dto.APIKeyIssueRequest{Name: "x", Role: "root", Scopes: []string{identity.ScopeStatsRead}})
+				return err
+			},
+			expectedError: `invalid role "root"`,
+		},
+		{
+			name: "issued by operator",
+			call: func() error {
+				_, err := svc.IssueAPIKey(identity.WithIdentity(ctx, id), &dto.APIKeyIssueRequest{Name: "x", Role: identity.RoleAdmin, Scopes: identity.Scopes})
+				return err
+			},
+			expectedError: "permission denied: role operator cannot keys.manage",
+		},
+		{
+			name: "issued for other tenant by tenant admin",
+			call: func() error {
+				tenantAdmin := &identity.Identity{Subject: "api-key:moscow", Role: identity.RoleAdmin, Tenant: "moscow", Scopes: identity.Scopes}
+				_, err := svc.IssueAPIKey(identity.WithIdentity(ctx, tenantAdmin), &dto.APIKeyIssueRequest{Name: "x", Role: identity.RoleViewer, Scopes: []string{identity.ScopeStatsRead}, Tenant: "kazan"})
+				return err
+			},
+			expectedError: "permission denied: tenant moscow cannot issue keys for tenant kazan",
+		},
+		{
+			name: "revoked by other tenant",
+			call: func() error {
+				tenantAdmin := &identity.Identity{Subject: "api-key:moscow", Role: identity.RoleAdmin, Tenant: "moscow", Scopes: identity.Scopes}
+				return svc.RevokeAPIKey(identity.WithIdentity(ctx, tenantAdmin), issued.ID)
+			},
+			expectedError: sql.ErrNoRows.Error(),
+		},
+		{
+			name: "expiry in the past",
+			call: func() error {
+				_, err := svc.IssueAPIKey(ctx, &dto.APIKeyIssueRequest{Name: "x", Role: identity.RoleViewer, Scopes: []string{identity.ScopeStatsRead}, ExpiresAt: getTimePtr(time.Now().Add(-time.Hour))})
+				return err
+			},
+			expectedError: "expires_at cannot be in the past",
+		},
+		{
+			name:          "revoke twice",
+			call:          func() error { return svc.RevokeAPIKey(ctx, rotated.ID) },
+			expectedError: "api key already revoked",
+		},
+		{
+			name: "rotate revoked",
+			call: func() error {
+				_, err := svc.RotateAPIKey(ctx, rotated.ID, &dto.APIKeyRotateRequest{})
+				return err
+			},
+			expectedError: "api key already revoked",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("error: got: %v, expect: %s\n", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestService_BootstrapAPIKey(t *testing.T) {
+	ctx := context.Background()
+	mockDb := repository.NewMockDb()
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	assert.NoError(t, svc.BootstrapAPIKey(ctx, ""))
+	assert.Empty(t, mockDb.APIKeys)
+	assert.NoError(t, svc.BootstrapAPIKey(ctx, "1234"))
+	assert.NoError(t, svc.BootstrapAPIKey(ctx, "1234"))
+	assert.Len(t, mockDb.APIKeys, 1)
+
+	id, err := svc.AuthenticateAPIKey(ctx, "1234")
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, identity.Scopes, id.Scopes)
+	assert.Equal(t, identity.RoleAdmin, id.Role)
+}
+
+func TestService_Authorization(t *testing.T) {
+	writeScopes := []string{identity.ScopeIncidentsRead, identity.ScopeIncidentsWrite}
+	operator := &identity.Identity{Subject: "api-key:ops", Role: identity.RoleOperator, Scopes: writeScopes}
+	admin := &identity.Identity{Subject: "api-key:admin", Role: identity.RoleAdmin, Scopes: writeScopes}
+	viewer := &identity.Identity{Subject: "api-key:viewer", Role: identity.RoleViewer, Scopes: writeScopes}
+
+	newIncident := func(status string) *entities.ReadIncident {
+		return &entities.ReadIncident{
+			Id:          "00000000-0000-0000-0000-000000000001",
+			Name:        "fire",
+			Type:        "fire",
+			Status:      status,
+			Latitude:    "55.75",
+			Longitude:   "37.61",
+			Radius:      100,
+			IsActive:    status != service.StatusArchived,
+			CreatedDate: time.Now().UTC(),
+			Version:     1,
+		}
+	}
+	testCases := []struct {
+		name          string
+		identity      *identity.Identity
+		status        string
+		call          func(ctx context.Context, svc *service.Service, id string) error
+		expectedError string
+	}{
+		{
+			name:     "operator_updates_active",
+			identity: operator,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.UpdateIncidentByID(ctx, id, &dto.UpdateRequest{Radius: getIntPtr(200)}, nil)
+				return err
+			},
+		},
+		{
+			name:     "operator_archives",
+			identity: operator,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.DeactivateIncidentByID(ctx, id, nil)
+				return err
+			},
+		},
+		{
+			name:     "viewer_cannot_update",
+			identity: viewer,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.UpdateIncidentByID(ctx, id, &dto.UpdateRequest{Radius: getIntPtr(200)}, nil)
+				return err
+			},
+			expectedError: "permission denied: role viewer cannot incidents.update",
+		},
+		{
+			name:     "operator_cannot_force_delete",
+			identity: operator,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				return svc.DeleteIncidentByID(ctx, id, nil)
+			},
+			expectedError: "permission denied: role operator cannot incidents.force_delete",
+		},
+		{
+			name:     "admin_force_deletes",
+			identity: admin,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				return svc.DeleteIncidentByID(ctx, id, nil)
+			},
+		},
+		{
+			name:     "operator_cannot_edit_archived",
+			identity: operator,
+			status:   service.StatusArchived,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.UpdateIncidentByID(ctx, id, &dto.UpdateRequest{Name: getStrPtr("wildfire")}, nil)
+				return err
+			},
+			expectedError: "permission denied: role operator cannot incidents.edit_archived",
+		},
+		{
+			name:     "operator_cannot_restore",
+			identity: operator,
+			status:   service.StatusArchived,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.RestoreIncidentByID(ctx, id, &dto.RestoreRequest{}, nil)
+				return err
+			},
+			expectedError: "permission denied: role operator cannot incidents.restore",
+		},
+		{
+			name:     "operator_cannot_bulk_delete",
+			identity: operator,
+			status:   service.StatusActive,
+			call: func(ctx context.Context, svc *service.Service, id string) error {
+				_, err := svc.BulkIncidents(ctx, &dto.BulkRequest{Operation: service.BulkOperationDelete, IDs: []string{id}})
+				return err
+			},
+			expectedError: "permission denied: role operator cannot incidents.force_delete",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			read := newIncident(tc.status)
+			mockDb.Storage[read.Id] = read
+			svc := service.NewService(mockDb, nil, &config.Config{MaxRadius: 50000}, nil)
+
+			err := tc.call(identity.WithIdentity(context.Background(), tc.identity), svc, read.Id)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if err == nil || err.Error() != tc.expectedError {
+				t.Fatalf("error: got: %v, expect: %s\n", err, tc.expectedError)
+			}
+			assert.Empty(t, mockDb.Audit, "denied action must not write anything")
+		})
+	}
+}
+
+func TestService_TenantIsolation(t *testing.T) {
+	newIncident := func(id, tenant string) *entities.ReadIncident {
+		return &entities.ReadIncident{
+			Id:          id,
+			TenantID:    tenant,
+			Name:        "fire",
+			Type:        "fire",
+			Status:      service.StatusActive,
+			Latitude:    "55.75",
+			Longitude:   "37.61",
+			Radius:      1000,
+			IsActive:    true,
+			CreatedDate: time.Now().UTC(),
+			Version:     1,
+		}
+	}
+	defaultID := "00000000-0000-0000-0000-000000000001"
+	moscowID := "00000000-0000-0000-0000-000000000002"
+	mockDb := repository.NewMockDb()
+	mockDb.Storage[defaultID] = newIncident(defaultID, "")
+	mockDb.Storage[moscowID] = newIncident(moscowID, "moscow")
+	mockCache := repository.NewCacheMock()
+	mockWebhook := webhook_manager.NewMockWebhookManager()
+	cfg := &config.Config{
+		MaxRadius:       50000,
+		DefaultRadius:   5000,
+		MaxRowsInPage:   10,
+		StatsTimeWindow: 100,
+		Tenants: map[string]*config.TenantConfig{
+			"moscow": {MaxRadius: 500, StatsTimeWindow: 60, WebhookURL: "http://moscow/hook"},
+		},
+	}
+	svc := service.NewService(mockDb, mockCache, cfg, mockWebhook)
+	moscow := identity.WithIdentity(context.Background(), &identity.Identity{
+		Subject: "api-key:moscow",
+		Role:    identity.RoleAdmin,
+		Tenant:  "moscow",
+		Scopes:  identity.Scopes,
+	})
+
+	_, err := svc.GetIncidentInfoByID(moscow, defaultID)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "incident of other tenant must look missing")
+	res, err := svc.GetIncidentInfoByID(moscow, moscowID)
+	assert.NoError(t, err)
+	assert.Equal(t, moscowID, res.ID)
+	_, err = mockCache.GetActiveIncident(context.Background(), identity.DefaultTenant, moscowID)
+	assert.Error(t, err, "cached incident must not be visible to other tenant")
+
+	page, err := svc.GetPagination(moscow, &dto.PaginationQueryParams{})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Incidents, 1) {
+		assert.Equal(t, moscowID, page.Incidents[0].ID)
+	}
+
+	_, err = svc.UpdateIncidentByID(moscow, defaultID, &dto.UpdateRequest{Radius: getIntPtr(200)}, nil)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = svc.UpdateIncidentByID(moscow, moscowID, &dto.UpdateRequest{Radius: getIntPtr(600)}, nil)
+	assert.EqualError(t, err, "radius cannot be > 500", "tenant max radius must be used")
+
+	assert.NoError(t, svc.DeleteIncidentByID(moscow, defaultID, nil))
+	assert.Nil(t, mockDb.Storage[defaultID].DeletedDate, "incident of other tenant must stay untouched")
+
+	check, err := svc.LocationCheck(identity.WithTenant(context.Background(), "moscow"), &dto.LocationCheckRequest{
+		UserID: "user-1", Latitude: "55.75", Longitude: "37.61",
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, check.DetectedIncidentsID, 1) {
+		assert.Equal(t, moscowID, check.DetectedIncidentsID[0].ID)
+	}
+	if assert.Len(t, mockWebhook.Storage, 1) {
+		assert.Equal(t, "moscow", mockWebhook.Storage[0].Tenant)
+		assert.Equal(t, "http://moscow/hook", mockWebhook.Storage[0].Url)
+	}
+
+	stats, err := svc.GetChecksStatistics(moscow, &dto.StatsQueryParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalUniqueUser)
+	assert.Equal(t, 60, stats.TimeStatWindow)
+	stats, err = svc.GetChecksStatistics(context.Background(), &dto.StatsQueryParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalUniqueUser)
+	assert.Equal(t, 100, stats.TimeStatWindow)
+}
+
+func TestService_GetChecksStatistics(t *testing.T) {
+	now := time.Now().UTC()
+	fireID := "00000000-0000-0000-0000-000000000001"
+	floodID := "00000000-0000-0000-0000-000000000002"
+	mockDb := repository.NewMockDb()
+	mockDb.Storage[fireID] = &entities.ReadIncident{Id: fireID, Name: "fire", Type: "fire", Status: service.StatusActive, IsActive: true}
+	mockDb.Storage[floodID] = &entities.ReadIncident{Id: floodID, Name: "flood", Type: "flood", Status: service.StatusResolved}
+	mockDb.Checks["c1"] = &repository.Check{UserID: "u1", IsDanger: true, DangerIds: []string{fireID}, CreatedDate: now.Add(-30 * time.Minute)}
+	mockDb.Checks["c2"] = &repository.Check{UserID: "u1", IsDanger: true, DangerIds: []string{fireID}, CreatedDate: now.Add(-10 * time.Minute)}
+	mockDb.Checks["c3"] = &repository.Check{UserID: "u2", IsDanger: true, DangerIds: []string{fireID, floodID}, CreatedDate: now.Add(-3 * time.Hour)}
+	mockDb.Checks["safe"] = &repository.Check{UserID: "u3", CreatedDate: now.Add(-5 * time.Minute)}
+	svc := service.NewService(mockDb, nil, &config.Config{StatsTimeWindow: 60}, nil)
+
+	testCases := []struct {
+		name           string
+		query          *dto.StatsQueryParams
+		expectedWindow int
+		expectedStats  []dto.IncidentStat
+		expectedError  string
+	}{
+		{
+			name:           "configured window",
+			query:          &dto.StatsQueryParams{},
+			expectedWindow: 60,
+			expectedStats: []dto.IncidentStat{{
+				IncidentBaseResponse: dto.IncidentBaseResponse{ID: fireID, Name: "fire", Type: "fire"},
+				Status:               service.StatusActive,
+				UserCount:            1,
+				DangerousChecks:      2,
+				FirstCheckDate:       now.Add(-30 * time.Minute),
+				LastCheckDate:        now.Add(-10 * time.Minute),
+			}},
+		},
+		{
+			name:           "window minutes",
+			query:          &dto.StatsQueryParams{WindowMinutes: getIntPtr(240), Types: []string{"fire"}},
+			expectedWindow: 240,
+			expectedStats: []dto.IncidentStat{{
+				IncidentBaseResponse: dto.IncidentBaseResponse{ID: fireID, Name: "fire", Type: "fire"},
+				Status:               service.StatusActive,
+				UserCount:            2,
+				DangerousChecks:      3,
+				FirstCheckDate:       now.Add(-3 * time.Hour),
+				LastCheckDate:        now.Add(-10 * time.Minute),
+			}},
+		},
+		{
+			name:           "inactive incidents",
+			query:          &dto.StatsQueryParams{From: getTimePtr(now.Add(-4 * time.Hour)), To: getTimePtr(now.Add(-2 * time.Hour)), IncludeInactive: true, Types: []string{"flood"}},
+			expectedWindow: 120,
+			expectedStats: []dto.IncidentStat{{
+				IncidentBaseResponse: dto.IncidentBaseResponse{ID: floodID, Name: "flood", Type: "flood"},
+				Status:               service.StatusResolved,
+				UserCount:            1,
+				DangerousChecks:      1,
+				FirstCheckDate:       now.Add(-3 * time.Hour),
+				LastCheckDate:        now.Add(-3 * time.Hour),
+			}},
+		},
+		{
+			name:           "resolved incident hidden by default",
+			query:          &dto.StatsQueryParams{WindowMinutes: getIntPtr(240), Types: []string{"flood"}},
+			expectedWindow: 240,
+		},
+		{
+			name:          "from after now",
+			query:         &dto.StatsQueryParams{From: getTimePtr(now.Add(time.Hour))},
+			expectedError: "from cannot be after to",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := svc.GetChecksStatistics(context.Background(), tc.query)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("error: got: %v, expect: %s\n", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, tc.expectedWindow, res.TimeStatWindow)
+			assert.Equal(t, tc.expectedStats, res.IncidentsStat)
+			assert.Equal(t, len(tc.expectedStats), res.TotalIncidents)
+		})
+	}
+}
+
+func TestService_GetChecksHeatmap(t *testing.T) {
+	now := time.Now().UTC()
+	mockDb := repository.NewMockDb()
+	mockDb.Checks["c1"] = &repository.Check{UserID: "u1", Latitude: "55.7558", Longitude: "37.6173", IsDanger: true, CreatedDate: now.Add(-time.Hour)}
+	mockDb.Checks["c2"] = &repository.Check{UserID: "u2", Latitude: "55.7559", Longitude: "37.6174", CreatedDate: now.Add(-time.Hour)}
+	mockDb.Checks["c3"] = &repository.Check{UserID: "u3", Latitude: "59.9343", Longitude: "30.3351", CreatedDate: now.Add(-time.Hour)}
+	mockDb.Checks["old"] = &repository.Check{UserID: "u4", Latitude: "59.9343", Longitude: "30.3351", CreatedDate: now.Add(-48 * time.Hour)}
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	res, err := svc.GetChecksHeatmap(context.Background(), &dto.HeatmapQueryParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, "FeatureCollection", res.Type)
+	if assert.Len(t, res.Features, 2) {
+		moscow := res.Features[0]
+		assert.Equal(t, dto.HeatmapProperties{Geohash: "ucfv0n", Checks: 2, DangerousChecks: 1}, moscow.Properties)
+		assert.Equal(t, "Polygon", moscow.Geometry.Type)
+		ring := moscow.Geometry.Coordinates[0]
+		assert.Len(t, ring, 5)
+		assert.Equal(t, ring[0], ring[4], "polygon ring must be closed")
+		assert.Equal(t, "udts", res.Features[1].Properties.Geohash[:4])
+	}
+
+	res, err = svc.GetChecksHeatmap(context.Background(), &dto.HeatmapQueryParams{Layer: dto.HeatmapLayerDangerous, Precision: getIntPtr(3)})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Features, 1) {
+		assert.Equal(t, dto.HeatmapProperties{Geohash: "ucf", Checks: 2, DangerousChecks: 1}, res.Features[0].Properties)
+	}
+
+	res, err = svc.GetChecksHeatmap(context.Background(), &dto.HeatmapQueryParams{BBox: []float64{30, 59, 31, 60}})
+	assert.NoError(t, err)
+	assert.Len(t, res.Features, 1)
+
+	_, err = svc.GetChecksHeatmap(context.Background(), &dto.HeatmapQueryParams{From: getTimePtr(now.Add(-40 * 24 * time.Hour))})
+	assert.EqualError(t, err, "heatmap window cannot be longer than 31 days")
+}
+
+func TestService_Hotspots(t *testing.T) {
+	now := time.Now().UTC()
+	mockDb := repository.NewMockDb()
+	for i := 0; i < 6; i++ {
+		mockDb.Checks[fmt.Sprintf("cluster_%d", i)] = &repository.Check{
+			UserID:      fmt.Sprintf("u%d", i%4),
+			Latitude:    fmt.Sprintf("55.7500%d", i),
+			Longitude:   "37.61",
+			CreatedDate: now.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	mockDb.Checks["far"] = &repository.Check{UserID: "u1", Latitude: "55.80", Longitude: "37.61", CreatedDate: now}
+	mockDb.Checks["old"] = &repository.Check{UserID: "u1", Latitude: "55.75", Longitude: "37.61", CreatedDate: now.Add(-2 * time.Hour)}
+	mockDb.Checks["danger"] = &repository.Check{UserID: "u5", Latitude: "55.75", Longitude: "37.61", IsDanger: true, CreatedDate: now}
+	mockDb.Checks["moscow"] = &repository.Check{TenantID: "moscow", UserID: "u6", Latitude: "55.75", Longitude: "37.61", CreatedDate: now}
+	svc := service.NewService(mockDb, nil, &config.Config{
+		MaxRadius:            5000,
+		DefaultRadius:        100,
+		HotspotWindowMinutes: 60,
+		HotspotRadiusMeters:  100,
+		HotspotMinChecks:     5,
+		HotspotMinUsers:      4,
+	}, nil)
+	ctx := context.Background()
+
+	detected, err := svc.DetectHotspots(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, detected)
+	detected, err = svc.DetectHotspots(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, detected, "known hotspot is updated, not reported again")
+
+	hotspots, err := svc.GetHotspots(ctx, "")
+	assert.NoError(t, err)
+	if !assert.Len(t, hotspots, 1) {
+		return
+	}
+	assert.Equal(t, 6, hotspots[0].Checks)
+	assert.Equal(t, 4, hotspots[0].UniqueUsers)
+	assert.Equal(t, service.HotspotStatusCandidate, hotspots[0].Status)
+	id := hotspots[0].ID
+
+	_, err = svc.GetHotspots(ctx, "unknown")
+	assert.EqualError(t, err, "invalid status: must be one of candidate, promoted, dismissed")
+	moscow := identity.WithTenant(ctx, "moscow")
+	hotspots, err = svc.GetHotspots(moscow, "")
+	assert.NoError(t, err)
+	assert.Empty(t, hotspots)
+	_, err = svc.PromoteHotspot(moscow, id, &dto.PromoteHotspotRequest{})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	res, err := svc.PromoteHotspot(ctx, id, &dto.PromoteHotspotRequest{Name: getStrPtr("gas leak")})
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	assert.Equal(t, service.HotspotStatusPromoted, res.Hotspot.Status)
+	assert.Equal(t, res.Incident.ID, *res.Hotspot.IncidentID)
+	assert.Equal(t, "gas leak", res.Incident.Name)
+	assert.Equal(t, service.HotspotIncidentType, res.Incident.Type)
+	assert.Equal(t, service.StatusUnverified, res.Incident.Status)
+	assert.Contains(t, mockDb.Storage, res.Incident.ID)
+
+	_, err = svc.PromoteHotspot(ctx, id, &dto.PromoteHotspotRequest{})
+	assert.EqualError(t, err, "hotspot already promoted")
+	_, err = svc.DismissHotspot(ctx, id)
+	assert.EqualError(t, err, "hotspot already promoted")
+	detected, err = svc.DetectHotspots(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, detected, "promoted hotspot is not reopened")
+}
+
+func TestService_GetChecksTimeSeries(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(3 * time.Hour)
+	fireID := "00000000-0000-0000-0000-000000000001"
+	floodID := "00000000-0000-0000-0000-000000000002"
+	mockDb := repository.NewMockDb()
+	mockDb.Storage[fireID] = &entities.ReadIncident{Id: fireID, Type: "fire", Latitude: "55.75", Longitude: "37.61", Radius: 1000}
+	mockDb.Storage[floodID] = &entities.ReadIncident{Id: floodID, Type: "flood", Latitude: "59.93", Longitude: "30.33", Radius: 1000}
+	mockDb.Checks["c1"] = &repository.Check{UserID: "u1", Latitude: "55.75", Longitude: "37.61", IsDanger: true, DangerIds: []string{fireID}, CreatedDate: from.Add(10 * time.Minute)}
+	mockDb.Checks["c2"] = &repository.Check{UserID: "u1", Latitude: "55.75", Longitude: "37.61", CreatedDate: from.Add(20 * time.Minute)}
+	mockDb.Checks["c3"] = &repository.Check{UserID: "u2", Latitude: "59.93", Longitude: "30.33", IsDanger: true, DangerIds: []string{floodID}, CreatedDate: from.Add(150 * time.Minute)}
+	mockDb.Checks["outside"] = &repository.Check{UserID: "u3", Latitude: "55.75", Longitude: "37.61", CreatedDate: to}
+	mockDb.Checks["other tenant"] = &repository.Check{TenantID: "moscow", UserID: "u4", Latitude: "55.75", Longitude: "37.61", CreatedDate: from}
+	svc := service.NewService(mockDb, nil, &config.Config{}, nil)
+
+	testCases := []struct {
+		name            string
+		query           *dto.ChecksTimeSeriesQueryParams
+		expectedBuckets []dto.ChecksBucket
+		expectedError   string
+	}{
+		{
+			name:  "all checks",
+			query: &dto.ChecksTimeSeriesQueryParams{From: &from, To: &to},
+			expectedBuckets: []dto.ChecksBucket{
+				{Start: from, Checks: 2, DangerousChecks: 1, UniqueUsers: 1},
+				{Start: from.Add(time.Hour)},
+				{Start: from.Add(2 * time.Hour), Checks: 1, DangerousChecks: 1, UniqueUsers: 1},
+			},
+		},
+		{
+			name:  "incident",
+			query: &dto.ChecksTimeSeriesQueryParams{From: &from, To: getTimePtr(from.Add(time.Hour)), Bucket: "5m", IncidentID: fireID},
+			expectedBuckets: func() []dto.ChecksBucket {
+				res := []dto.ChecksBucket{}
+				for i := 0; i < 12; i++ {
+					res = append(res, dto.ChecksBucket{Start: from.Add(time.Duration(i) * 5 * time.Minute)})
+				}
+				// c2 is inside the area, but the user was not warned about the incident
+				res[2] = dto.ChecksBucket{Start: from.Add(10 * time.Minute), Checks: 1, DangerousChecks: 1, UniqueUsers: 1}
+				return res
+			}(),
+		},
+		{
+			name:  "type",
+			query: &dto.ChecksTimeSeriesQueryParams{From: &from, To: &to, Bucket: "1d", Type: "flood"},
+			expectedBuckets: []dto.ChecksBucket{
+				{Start: from, Checks: 1, DangerousChecks: 1, UniqueUsers: 1},
+			},
+		},
+		{
+			name:          "unknown bucket",
+			query:         &dto.ChecksTimeSeriesQueryParams{Bucket: "1w"},
+			expectedError: "invalid bucket: must be one of [5m 1h 1d]",
+		},
+		{
+			name:          "too many buckets",
+			query:         &dto.ChecksTimeSeriesQueryParams{From: getTimePtr(from.Add(-10 * 24 * time.Hour)), To: &to, Bucket: "5m"},
+			expectedError: "too many buckets: window must be at most 2000 buckets of 5m",
+		},
+		{
+			name:          "unknown incident",
+			query:         &dto.ChecksTimeSeriesQueryParams{IncidentID: "00000000-0000-0000-0000-000000000003"},
+			expectedError: sql.ErrNoRows.Error(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := svc.GetChecksTimeSeries(context.Background(), tc.query)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("error: got: %v, expect: %s\n", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			assert.Equal(t, tc.expectedBuckets, res.Buckets)
+		})
+	}
+
+	moscow := identity.WithTenant(context.Background(), "moscow")
+	_, err := svc.GetChecksTimeSeries(moscow, &dto.ChecksTimeSeriesQueryParams{IncidentID: fireID})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "incident of other tenant must look missing")
+	res, err := svc.GetChecksTimeSeries(moscow, &dto.ChecksTimeSeriesQueryParams{From: &from, To: getTimePtr(from.Add(time.Hour))})
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.ChecksBucket{{Start: from, Checks: 1, UniqueUsers: 1}}, res.Buckets)
+}
+
+func TestService_LocationCheck(t *testing.T) {
+	inc1 := &entities.ReadIncident{
+		Id:        "inc_1",
+		Latitude:  "55.755826",
+		Longitude: "37.617300",
+		Status:    service.StatusActive,
+		IsActive:  true,
+		Radius:    2000,
+	}
+	inc2 := &entities.ReadIncident{
+		Id:        "inc_2",
+		Latitude:  "55.7735",
+		Longitude: "37.6200",
+		Status:    service.StatusActive,
+		IsActive:  true,
+		Radius:    1000,
+	}
+	inc3 := &entities.ReadIncident{
+		Id:          "inc_3",
+		Latitude:    "37.1406",
+		Longitude:   "115.4840",
+		Status:      service.StatusActive,
+		IsActive:    true,
+		Description: getStrPtr("zone-51!"),
+		Radius:      1000,
+	}
+	inc4 := &entities.ReadIncident{
+		Id:          "inc_4",
+		Latitude:    "37",
+		Longitude:   "115",
+		Status:      service.StatusArchived,
+		IsActive:    false,
+		Description: getStrPtr("inactive"),
+		Radius:      1000,
+	}
+	testCases := []struct {
+		name                  string
+		checkBody             *dto.LocationCheckRequest
+		expectCheck           bool
+		expectedIds           []string
+		expectedIsDanger      bool
+		expectedErrContain    string
+		ContainsIdsInWeebhook bool
+	}{
+		{
+			name: "check_coord_inc_1",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "new_user",
+				Latitude:  "55.755826",
+				Longitude: "37.617300",
+			},
+			expectCheck:      true,
+			expectedIds:      []string{"inc_1"},
+			expectedIsDanger: true,
+		},
+		{
+			name: "check_coord_inc_1_and_inc_2",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "test_user",
+				Latitude:  "55.7650",
+				Longitude: "37.6184",
+			},
+			expectCheck:      true,
+			expectedIds:      []string{"inc_1", "inc_2"},
+			expectedIsDanger: true,
+		},
+		{
+			name: "check_inactive_incident",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "test_user",
+				Latitude:  "37",
+				Longitude: "115",
+			},
+			expectCheck:      true,
+			expectedIsDanger: false,
+		},
+
+		{
+			name: "invalid_user_id_empty",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "",
+				Latitude:  "37",
+				Longitude: "115",
+			},
+			expectCheck:        false,
+			expectedIsDanger:   false,
+			expectedErrContain: "user_id cannot be empty",
+		},
+		{
+			name: "invalid_latitude_empty",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "user_1",
+				Latitude:  "",
+				Longitude: "115",
+			},
+			expectCheck:        false,
+			expectedIsDanger:   false,
+			expectedErrContain: "latitude incorrect parse",
+		},
+
+		{
+			name: "вне всех зон",
+			checkBody: &dto.LocationCheckRequest{
+				UserID:    "user-out",
+				Latitude:  "55.9000",
+				Longitude: "37.8000",
+			},
+			expectedIsDanger: false,
+			expectCheck:      true,
+			expectedIds:      []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDb := repository.NewMockDb()
+			mockCache := repository.NewCacheMock()
+			mockWebhook := webhook_manager.NewMockWebhookManager()
+			svc := service.NewService(mockDb, mockCache, nil, mockWebhook)
+
+			mockDb.Storage[inc1.Id] = inc1
+			mockDb.Storage[inc2.Id] = inc2
+			mockDb.Storage[inc3.Id] = inc3
+			mockDb.Storage[inc4.Id] = inc4
+
+			res, err := svc.LocationCheck(context.Background(), tc.checkBody)
+
+			if err != nil {
+				if tc.expectedErrContain != "" {
+					if !strings.Contains(err.Error(), tc.expectedErrContain) {
+						t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedErrContain)
+					}
+				} else {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				}
+			}
+			if tc.expectCheck {
+				if _, ok := mockDb.Checks[res.ID]; !ok {
+					t.Errorf("check_id: %s, not contains in checks\n", res.ID)
+				}
+				if mockDb.Tx != nil {
+					if !mockDb.Tx.Committed {
+						t.Errorf("tx cannot commit\n")
+					}
+				} else {
+					t.Errorf("tx cannot start")
+				}
+			} else {
+				if mockDb.Tx != nil {
+					t.Errorf("tx cannot be started\n")
+				}
+				if res != nil {
+					t.Errorf("unexpected result\n")
 				}
 			}
 			if res != nil {
@@ -1147,3 +3029,59 @@ func getStrPtr(s string) *string {
 func getIntPtr(i int) *int {
 	return &i
 }
+
+func getTimePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestService_GetIncidentHistory(t *testing.T) {
+	deletedDate := time.Now().UTC()
+	testCases := []struct {
+		name        string
+		stored      *entities.ReadIncident
+		expectFound bool
+	}{
+		{
+			name:        "active",
+			stored:      &entities.ReadIncident{Status: service.StatusActive},
+			expectFound: true,
+		},
+		{
+			name:        "in_trash",
+			stored:      &entities.ReadIncident{Status: service.StatusActive, DeletedDate: &deletedDate},
+			expectFound: true,
+		},
+		{
+			name:   "other_tenant",
+			stored: &entities.ReadIncident{Status: service.StatusActive, TenantID: "moscow"},
+		},
+		{
+			name: "unknown",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := "00000000-0000-0000-0000-000000000001"
+			mockDb := repository.NewMockDb()
+			if tc.stored != nil {
+				tc.stored.Id = id
+				mockDb.Storage[id] = tc.stored
+			}
+			mockDb.Audit = append(mockDb.Audit, &entities.AuditRecord{
+				TenantID:   identity.DefaultTenant,
+				IncidentID: id,
+				Action:     service.AuditActionCreate,
+			})
+			svc := service.NewService(mockDb, nil, &config.Config{MaxRowsInPage: 10}, nil)
+
+			res, err := svc.GetIncidentHistory(context.Background(), id, &dto.AuditQueryParams{})
+			if !tc.expectFound {
+				assert.ErrorIs(t, err, sql.ErrNoRows, "history must answer like GET /incidents/{id}")
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Len(t, res.Records, 1)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+// RunExclusive runs the job when no other replica is running it, otherwise the run is skipped:
+// background jobs start on every replica and would race on DDL and on the rows they insert.
+// A skipped job runs again on the next tick.
+func (s *Service) RunExclusive(ctx context.Context, job string, fn func(ctx context.Context) error) error {
+	unlock, ok, err := s.db.TryAdvisoryLock(ctx, jobLockKey(job))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.changeLogger.Printf("INFO: job %s skipped, it is running on another replica", job)
+		return nil
+	}
+	defer unlock()
+	return fn(ctx)
+}
+
+// jobLockKey maps the job name to the key of its advisory lock.
+func jobLockKey(job string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("incidents_service:" + job))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/Piccadilly98/incidents_service/internal/jsonpatch"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	PatchTypeMerge = "application/merge-patch+json"
+	PatchTypeJSON  = "application/json-patch+json"
+
+	patchFieldStatusReason = "status_reason"
+)
+
+// patchReadOnlyFields can be checked by "test" operation but never changed.
+var patchReadOnlyFields = []string{"id", "latitude", "longitude", "is_active", "version"}
+
+// PatchIncidentByID applies merge patch or json patch to the incident document
+// and runs the result through the same validation as PUT.
+func (s *Service) PatchIncidentByID(ctx context.Context, id string, patchType string, patch []byte, expectedVersion *int) (*dto.IncidentAdminResponse, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	read, err := s.getIncidentForWrite(ctx, id, expectedVersion, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := incidentPatchDocument(read)
+	var patched map[string]any
+	switch patchType {
+	case PatchTypeMerge:
+		patched, err = jsonpatch.ApplyMergePatch(doc, patch)
+	case PatchTypeJSON:
+		patched, err = jsonpatch.Apply(doc, patch)
+	default:
+		return nil, fmt.Errorf("invalid patch: unsupported content type %s", patchType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	req, err := patchedDocumentToUpdateRequest(doc, patched)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.updateIncident(ctx, id, read, req, tx)
+}
+
+func incidentPatchDocument(read *entities.ReadIncident) map[string]any {
+	doc := map[string]any{
+		"id":        read.Id,
+		"name":      read.Name,
+		"type":      read.Type,
+		"latitude":  read.Latitude,
+		"longitude": read.Longitude,
+		"radius":    float64(read.Radius),
+		"status":    read.Status,
+		"is_active": read.IsActive,
+		"version":   float64(read.Version),
+	}
+	if read.Description != nil {
+		doc["description"] = *read.Description
+	}
+	return doc
+}
+
+// patchedDocumentToUpdateRequest compares patched document with the original one
+// and keeps only changed fields, so unchanged values do not count as updates.
+func patchedDocumentToUpdateRequest(original, patched map[string]any) (*dto.UpdateRequest, error) {
+	for key := range patched {
+		if _, ok := original[key]; !ok && key != "description" && key != patchFieldStatusReason {
+			return nil, fmt.Errorf("field %s cannot be patched", key)
+		}
+	}
+	for _, key := range patchReadOnlyFields {
+		if !reflect.DeepEqual(original[key], patched[key]) {
+			return nil, fmt.Errorf("field %s cannot be patched", key)
+		}
+	}
+
+	req := &dto.UpdateRequest{}
+	var err error
+	if req.Name, err = patchedString(original, patched, "name"); err != nil {
+		return nil, err
+	}
+	if req.Type, err = patchedString(original, patched, "type"); err != nil {
+		return nil, err
+	}
+	if req.Status, err = patchedString(original, patched, "status"); err != nil {
+		return nil, err
+	}
+
+	if value, ok := patched["description"]; ok && value != nil {
+		description, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("description must be string")
+		}
+		if original["description"] != description {
+			req.Description = &description
+		}
+	} else if _, had := original["description"]; had {
+		req.ClearDescription = true
+	}
+
+	radius, ok := patched["radius"]
+	if !ok || radius == nil {
+		return nil, fmt.Errorf("radius cannot be null")
+	}
+	radiusNum, ok := radius.(float64)
+	if !ok || radiusNum != math.Trunc(radiusNum) {
+		return nil, fmt.Errorf("radius must be integer")
+	}
+	if radiusNum != original["radius"] {
+		value := int(radiusNum)
+		req.Radius = &value
+	}
+
+	if value, ok := patched[patchFieldStatusReason]; ok && value != nil {
+		reason, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("status_reason must be string")
+		}
+		req.StatusReason = &reason
+	}
+	return req, nil
+}
+
+func patchedString(original, patched map[string]any, key string) (*string, error) {
+	value, ok := patched[key]
+	if !ok || value == nil {
+		return nil, fmt.Errorf("%s cannot be null", key)
+	}
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be string", key)
+	}
+	if original[key] == str {
+		return nil, nil
+	}
+	return &str, nil
+}
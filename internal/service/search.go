@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+)
+
+func (s *Service) SearchIncidents(ctx context.Context, query *dto.SearchQueryParams) (*dto.SearchResponse, error) {
+	if query.Radius != nil {
+		_, err := s.processingRadius(ctx, query.Radius)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, status := range query.Statuses {
+		if !isKnownStatus(status) {
+			return nil, fmt.Errorf("invalid status: %s", status)
+		}
+	}
+	pageSize := s.config.MaxRowsInPage
+	if query.Limit != nil {
+		if *query.Limit > s.config.MaxRowsInPage {
+			return nil, fmt.Errorf("limit cannot be > %d", s.config.MaxRowsInPage)
+		}
+		pageSize = *query.Limit
+	}
+	entit := s.toPaginationEntity(ctx, &query.PaginationQueryParams, "", false)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	count, err := s.db.GetCountSearchIncidents(ctx, query.Query, entit, tx)
+	if err != nil {
+		return nil, err
+	}
+	pages := s.GetCountPages(count, pageSize)
+	pageNum := query.PageNum
+	entit.Limit = pageSize
+	if pageNum != nil {
+		if *pageNum > pages && *pageNum != 1 {
+			return nil, fmt.Errorf("invalid page: max %d", pages)
+		}
+		entit.Offset = pageSize * (*pageNum - 1)
+	}
+	results, err := s.db.SearchIncidents(ctx, query.Query, entit, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return dto.ToSearchResponse(results, pages, count, pageNum), nil
+}
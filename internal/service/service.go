@@ -2,20 +2,27 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
+	"github.com/Piccadilly98/incidents_service/internal/identity"
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
 	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
 )
 
 func (s *Service) RegistrationIncident(ctx context.Context, req *dto.RegistrationIncidentRequest) (*dto.IncidentAdminResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsCreate); err != nil {
+		return nil, err
+	}
 	err := req.Validate()
 	if err != nil {
 		return nil, err
 	}
-	entit, err := s.FromDtoToEntitie(req)
+	entit, err := s.FromDtoToEntitie(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -25,31 +32,74 @@ func (s *Service) RegistrationIncident(ctx context.Context, req *dto.Registratio
 	}
 
 	defer tx.Rollback()
-	id, err := s.db.RegistrationIncident(ctx, entit, tx)
+	res, err := s.createIncident(ctx, entit, tx)
 	if err != nil {
 		return nil, err
 	}
-	res, err := s.db.GetInfoByIncidentID(ctx, id, tx)
+	s.cacheIncident(ctx, res.TenantID, res.Id, res)
+	if err = s.commitCached(ctx, tx, res.TenantID, res.Id); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: Create new incident with id: %s", res.Id)
+	return dto.CreateAdminResponse(res, nil), nil
+}
+
+// createIncident inserts the incident with its first status transition and audit record.
+func (s *Service) createIncident(ctx context.Context, entit *entities.RegistrationIncidentEntitie, exec repository.Executor) (*entities.ReadIncident, error) {
+	id, err := s.db.RegistrationIncident(ctx, entit, exec)
 	if err != nil {
 		return nil, err
 	}
-	err = tx.Commit()
+	err = s.recordStatusTransition(ctx, id, nil, entit.Status, nil, exec)
 	if err != nil {
 		return nil, err
 	}
-	if s.cache != nil {
-		if res.IsActive {
-			err := s.cache.SetActiveIncident(ctx, res)
-			if err != nil {
-				s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", res.Id, err.Error())
+	res, err := s.db.GetInfoByIncidentID(ctx, id, exec)
+	if err != nil {
+		return nil, err
+	}
+	err = s.writeAudit(ctx, AuditActionCreate, id, nil, res, exec)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// cacheIncident puts the incident written by tx into the cache before commit. The row stays locked
+// until then, so writers and readers filling the cache (see GetIncidentInfoByID) change the entry
+// in the order of their transactions and an older version never overwrites a newer one.
+// A nil or inactive incident is removed from the cache.
+func (s *Service) cacheIncident(ctx context.Context, tenant, id string, model *entities.ReadIncident) {
+	if s.cache == nil {
+		return
+	}
+	if model != nil && model.IsActive {
+		err := s.cache.SetActiveIncident(ctx, model)
+		if err != nil {
+			s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", id, err.Error())
+		}
+		return
+	}
+	err := s.cache.DeleteActiveIncident(ctx, tenant, id)
+	if err != nil {
+		s.cacheLogger.Printf("ERROR IN DEL WITH ID: %s, err: %s\n", id, err.Error())
+	}
+}
+
+// commitCached commits tx after cacheIncident, cached copies of ids are dropped when the commit fails.
+func (s *Service) commitCached(ctx context.Context, tx repository.Tx, tenant string, ids ...string) error {
+	err := tx.Commit()
+	if err != nil && s.cache != nil {
+		for _, id := range ids {
+			if err := s.cache.DeleteActiveIncident(ctx, tenant, id); err != nil {
+				s.cacheLogger.Printf("ERROR IN DEL WITH ID: %s, err: %s\n", id, err.Error())
 			}
 		}
 	}
-	s.changeLogger.Printf("INFO: Create new incident with id: %s", id)
-	return dto.CreateAdminResponse(res, nil), nil
+	return err
 }
 
-func (s *Service) FromDtoToEntitie(req *dto.RegistrationIncidentRequest) (*entities.RegistrationIncidentEntitie, error) {
+func (s *Service) FromDtoToEntitie(ctx context.Context, req *dto.RegistrationIncidentRequest) (*entities.RegistrationIncidentEntitie, error) {
 	if len(req.Name) > 100 {
 		return nil, fmt.Errorf("very long name")
 	}
@@ -57,12 +107,13 @@ func (s *Service) FromDtoToEntitie(req *dto.RegistrationIncidentRequest) (*entit
 		return nil, fmt.Errorf("very long type")
 	}
 	entit := req.ToBaseEntity()
+	entit.TenantID = identity.Tenant(ctx)
 	var err error
 	entit.Status, err = s.processingStatus(req.Status)
 	if err != nil {
 		return nil, err
 	}
-	entit.Radius, err = s.processingRadius(req.RadiusInMeters)
+	entit.Radius, err = s.processingRadius(ctx, req.RadiusInMeters)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +131,7 @@ func (s *Service) processingStatus(statusReq *string) (string, error) {
 		if len(*statusReq) > 20 {
 			return "", fmt.Errorf("very long status")
 		}
-		if *statusReq != StatusActive && *statusReq != StatusResolved && *statusReq != StatusArchived {
+		if !isKnownStatus(*statusReq) {
 			return "", fmt.Errorf("invalid status")
 		}
 		status = *statusReq
@@ -88,12 +139,13 @@ func (s *Service) processingStatus(statusReq *string) (string, error) {
 	return status, nil
 }
 
-func (s *Service) processingRadius(radiusResp *int) (int, error) {
-	radius := s.config.DefaultRadius
+func (s *Service) processingRadius(ctx context.Context, radiusResp *int) (int, error) {
+	cfg := s.tenantConfig(ctx)
+	radius := cfg.DefaultRadius
 
 	if radiusResp != nil {
-		if *radiusResp > s.config.MaxRadius {
-			return 0, fmt.Errorf("radius cannot be > %d", s.config.MaxRadius)
+		if *radiusResp > cfg.MaxRadius {
+			return 0, fmt.Errorf("radius cannot be > %d", cfg.MaxRadius)
 		}
 		if *radiusResp <= 0 {
 			return 0, fmt.Errorf("radius cannot be <= 0")
@@ -105,21 +157,16 @@ func (s *Service) processingRadius(radiusResp *int) (int, error) {
 }
 
 func (s *Service) processingIsActive(status string) (bool, error) {
-	switch status {
-	case StatusActive:
-		return true, nil
-	case StatusResolved:
-		return false, nil
-	case StatusArchived:
-		return false, nil
-	default:
+	info, ok := statuses[status]
+	if !ok {
 		return false, fmt.Errorf("unexpected status")
 	}
+	return info.isActive, nil
 }
 
 func (s *Service) processingResolvedTime(status string) *time.Time {
 	var res *time.Time
-	if status == StatusResolved || status == StatusArchived {
+	if statuses[status].isClosed {
 		now := time.Now().UTC()
 		res = &now
 	}
@@ -131,75 +178,152 @@ func (s *Service) GetIncidentInfoByID(ctx context.Context, id string) (*dto.Inci
 	var read *entities.ReadIncident
 	var err error
 	if s.cache != nil {
-		read, err = s.cache.GetActiveIncident(ctx, id)
+		read, err = s.cache.GetActiveIncident(ctx, identity.Tenant(ctx), id)
 		if err != nil {
 			read = nil
 			s.cacheLogger.Printf("ERROR IN GET WITH ID: %s, err: %s\n", id, err.Error())
 		}
 	}
 	if read == nil {
-		read, err = s.db.GetInfoByIncidentID(ctx, id, nil)
+		read, err = s.getIncidentForCache(ctx, id)
 		if err != nil {
 			return nil, err
 		}
-		if s.cache != nil {
-			if read.IsActive {
-				err := s.cache.SetActiveIncident(ctx, read)
-				if err != nil {
-					s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", read.Id, err.Error())
-				}
-			}
-		}
 	}
 	return dto.CreateAdminResponse(read, nil), nil
 }
 
-func (s *Service) UpdateIncidentByID(ctx context.Context, id string, req *dto.UpdateRequest) (*dto.IncidentAdminResponse, error) {
-	err := req.Validate()
+// getIncidentForCache reads the incident on cache miss. The row is read with a share lock that is held
+// until the copy is cached, so a concurrent write waits for it and then replaces the entry with its version,
+// see cacheIncident.
+func (s *Service) getIncidentForCache(ctx context.Context, id string) (*entities.ReadIncident, error) {
+	if s.cache == nil {
+		read, err := s.db.GetInfoByIncidentID(ctx, id, nil)
+		if err != nil {
+			return nil, err
+		}
+		return read, checkTenant(ctx, read)
+	}
+	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	var read *entities.ReadIncident
-	if s.cache != nil {
-		read, err = s.cache.GetActiveIncident(ctx, id)
+	defer tx.Rollback()
+	read, err := s.db.GetInfoByIncidentIDForShare(ctx, id, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTenant(ctx, read); err != nil {
+		return nil, err
+	}
+	if read.IsActive {
+		err := s.cache.SetActiveIncident(ctx, read)
 		if err != nil {
-			read = nil
-			s.cacheLogger.Printf("ERROR IN GET WITH ID: %s, err: %s\n", id, err.Error())
+			s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", read.Id, err.Error())
 		}
 	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return read, nil
+}
+
+func (s *Service) UpdateIncidentByID(ctx context.Context, id string, req *dto.UpdateRequest, expectedVersion *int) (*dto.IncidentAdminResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
-	if read == nil {
-		read, err = s.db.GetInfoByIncidentID(ctx, id, tx)
-		if err != nil {
-			return nil, err
-		}
+	read, err := s.getIncidentForWrite(ctx, id, expectedVersion, tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateIncident(ctx, id, read, req, tx)
+}
+
+// updateIncident applies validated request to the locked incident and commits tx,
+// shared by PUT and PATCH.
+func (s *Service) updateIncident(ctx context.Context, id string, read *entities.ReadIncident, req *dto.UpdateRequest, tx repository.Tx) (*dto.IncidentAdminResponse, error) {
+	if err := s.authorizeUpdate(ctx, read, req); err != nil {
+		return nil, err
 	}
 	if err := s.processingIncidentIDForUpdate(read, req, id); err != nil {
 		return nil, err
 	}
-	res := s.toUpdateEntity(read, req)
-	model, err := s.db.UpdateIncidentByID(ctx, id, res, tx)
+	model, err := s.writeIncidentUpdate(ctx, id, read, req, AuditActionUpdate, tx)
 	if err != nil {
 		return nil, err
 	}
-	err = tx.Commit()
+	s.cacheIncident(ctx, model.TenantID, id, model)
+	if err = s.commitCached(ctx, tx, model.TenantID, id); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: incident %s updated successfully", id)
+	return dto.CreateAdminResponse(model, nil), nil
+}
+
+// authorizeUpdate picks the permission by the incident state: archived incidents are edited only by admins,
+// moving an incident to archived is the same action as DELETE without force.
+func (s *Service) authorizeUpdate(ctx context.Context, read *entities.ReadIncident, req *dto.UpdateRequest) error {
+	perm := identity.PermIncidentsUpdate
+	switch {
+	case read.Status == StatusArchived:
+		perm = identity.PermIncidentsEditArchived
+	case req.Status != nil && *req.Status == StatusArchived:
+		perm = identity.PermIncidentsArchive
+	}
+	return s.authorize(ctx, perm)
+}
+
+// writeIncidentUpdate stores an already checked update together with the status transition and audit record.
+func (s *Service) writeIncidentUpdate(ctx context.Context, id string, read *entities.ReadIncident, req *dto.UpdateRequest, action string, exec repository.Executor) (*entities.ReadIncident, error) {
+	fromStatus := read.Status
+	model, err := s.db.UpdateIncidentByID(ctx, id, s.toUpdateEntity(read, req), exec)
 	if err != nil {
 		return nil, err
 	}
-	if s.cache != nil {
-		if res.IsActive {
-			err := s.cache.SetActiveIncident(ctx, model)
-			if err != nil {
-				s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", model.Id, err.Error())
-			}
+	if req.Status != nil && *req.Status != fromStatus {
+		err = s.recordStatusTransition(ctx, id, &fromStatus, *req.Status, req.StatusReason, exec)
+		if err != nil {
+			return nil, err
 		}
 	}
-	s.changeLogger.Printf("INFO: incident %s updated successfully", id)
-	return dto.CreateAdminResponse(model, nil), nil
+	err = s.writeAudit(ctx, action, id, read, model, exec)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// getIncidentForWrite reads incident from db with row lock, cache is skipped on purpose:
+// stale cached copy would break version check.
+func (s *Service) getIncidentForWrite(ctx context.Context, id string, expectedVersion *int, exec repository.Executor) (*entities.ReadIncident, error) {
+	if expectedVersion == nil && s.config != nil && s.config.RequireIfMatch {
+		return nil, fmt.Errorf("precondition required: If-Match header with incident ETag")
+	}
+	read, err := s.db.GetInfoByIncidentIDForUpdate(ctx, id, exec)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIncidentForWrite(ctx, read, expectedVersion); err != nil {
+		return nil, err
+	}
+	return read, nil
+}
+
+// checkIncidentForWrite hides incidents of other tenants and compares the version with If-Match.
+func checkIncidentForWrite(ctx context.Context, read *entities.ReadIncident, expectedVersion *int) error {
+	if err := checkTenant(ctx, read); err != nil {
+		return err
+	}
+	if expectedVersion != nil && read.Version != *expectedVersion {
+		return fmt.Errorf("precondition failed: incident version is %d", read.Version)
+	}
+	return nil
 }
 
 func (s *Service) processingIncidentIDForUpdate(res *entities.ReadIncident, req *dto.UpdateRequest, id string) error {
@@ -213,12 +337,16 @@ func (s *Service) processingIncidentIDForUpdate(res *entities.ReadIncident, req
 		if *req.Description != *res.Description {
 			hasChanges = true
 		}
-	} else if (req.Description != nil && res.Description == nil) || (req.Description == nil && res.Description != nil) {
+	} else if req.Description != nil && res.Description == nil {
+		hasChanges = true
+	}
+	if req.ClearDescription && res.Description != nil {
 		hasChanges = true
 	}
 	if req.Radius != nil {
-		if *req.Radius > s.config.MaxRadius {
-			return fmt.Errorf("radius cannot be > %d", s.config.MaxRadius)
+		maxRadius := s.config.ForTenant(res.TenantID).MaxRadius
+		if *req.Radius > maxRadius {
+			return fmt.Errorf("radius cannot be > %d", maxRadius)
 		}
 		if *req.Radius <= 0 {
 			return fmt.Errorf("radius cannot be <= 0")
@@ -239,10 +367,13 @@ func (s *Service) processingIncidentIDForUpdate(res *entities.ReadIncident, req
 		}
 	}
 	if req.Status != nil {
-		if *req.Status != StatusActive && *req.Status != StatusResolved && *req.Status != StatusArchived {
+		if !isKnownStatus(*req.Status) {
 			return fmt.Errorf("invalid status")
 		}
 		if *req.Status != res.Status {
+			if err := s.checkStatusTransition(res.Status, *req.Status); err != nil {
+				return err
+			}
 			hasChanges = true
 		}
 	}
@@ -256,39 +387,26 @@ func (s *Service) toUpdateEntity(res *entities.ReadIncident, req *dto.UpdateRequ
 	isActive := res.IsActive
 	var resolvedTime *time.Time = res.ResolvedDate
 	if req.Status != nil {
-		switch *req.Status {
-		case StatusResolved, StatusArchived:
-			isActive = false
-			now := time.Now().UTC()
-			resolvedTime = &now
-		case StatusActive:
-			isActive = true
-			resolvedTime = nil
+		if info, ok := statuses[*req.Status]; ok {
+			isActive = info.isActive
+			resolvedTime = s.processingResolvedTime(*req.Status)
 		}
 	}
 	return req.ToEntity(resolvedTime, isActive)
 }
 
-func (s *Service) DeactivateIncidentByID(ctx context.Context, id string) (*dto.IncidentAdminResponse, error) {
-	var err error
-	var read *entities.ReadIncident
-	if s.cache != nil {
-		read, err = s.cache.GetActiveIncident(ctx, id)
-		if err != nil {
-			read = nil
-			s.cacheLogger.Printf("ERROR IN GET WITH ID: %s, err: %s\n", id, err.Error())
-		}
+func (s *Service) DeactivateIncidentByID(ctx context.Context, id string, expectedVersion *int) (*dto.IncidentAdminResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsArchive); err != nil {
+		return nil, err
 	}
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
-	if read == nil {
-		read, err = s.db.GetInfoByIncidentID(ctx, id, tx)
-		if err != nil {
-			return nil, err
-		}
+	read, err := s.getIncidentForWrite(ctx, id, expectedVersion, tx)
+	if err != nil {
+		return nil, err
 	}
 	if read.Status == StatusArchived {
 		return nil, fmt.Errorf("incident already archived")
@@ -302,78 +420,128 @@ func (s *Service) DeactivateIncidentByID(ctx context.Context, id string) (*dto.I
 		return nil, err
 	}
 
+	fromStatus := read.Status
 	updateEntity := s.toUpdateEntity(read, req)
 
 	updated, err := s.db.UpdateIncidentByID(ctx, id, updateEntity, tx)
 	if err != nil {
 		return nil, err
 	}
+	err = s.recordStatusTransition(ctx, id, &fromStatus, StatusArchived, nil, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.writeAudit(ctx, AuditActionDeactivate, id, read, updated, tx)
+	if err != nil {
+		return nil, err
+	}
 
+	s.cacheIncident(ctx, updated.TenantID, id, nil)
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
-	if s.cache != nil {
-		err := s.cache.DeleteActiveIncident(ctx, id)
-		if err != nil {
-			s.cacheLogger.Printf("ERROR IN DEL WITH ID: %s, err: %s\n", id, err.Error())
-		}
-	}
 	s.changeLogger.Printf("INFO: incident %s deactivated", id)
 
 	return dto.CreateAdminResponse(updated, nil), nil
 }
 
-func (s *Service) DeleteIncidentByID(ctx context.Context, id string) error {
-	err := s.db.DeleteIncidentByID(ctx, id, nil)
+func (s *Service) DeleteIncidentByID(ctx context.Context, id string, expectedVersion *int) error {
+	if err := s.authorize(ctx, identity.PermIncidentsForceDelete); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
-	if s.cache != nil {
-		err := s.cache.DeleteActiveIncident(ctx, id)
+	defer tx.Rollback()
+	before, err := s.getIncidentForWrite(ctx, id, expectedVersion, tx)
+	// without expected version force delete stays idempotent for missing incidents
+	if err != nil && (expectedVersion != nil || !errors.Is(err, sql.ErrNoRows)) {
+		return err
+	}
+	// an incident of another tenant looks missing as well and must stay untouched
+	if before != nil {
+		err = s.db.DeleteIncidentByID(ctx, id, tx)
 		if err != nil {
-			s.cacheLogger.Printf("ERROR IN DEL WITH ID: %s, err: %s\n", id, err.Error())
+			return err
+		}
+		err = s.writeAudit(ctx, AuditActionForceDelete, id, before, nil, tx)
+		if err != nil {
+			return err
 		}
 	}
-	s.changeLogger.Printf("CRITICAL: incident %s force deleted", id)
+	s.cacheIncident(ctx, identity.Tenant(ctx), id, nil)
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	s.changeLogger.Printf("CRITICAL: incident %s force deleted, moved to trash", id)
 	return nil
 }
 
 func (s *Service) GetPagination(ctx context.Context, query *dto.PaginationQueryParams) (*dto.PaginationResponse, error) {
 	if query.Radius != nil {
-		_, err := s.processingRadius(query.Radius)
+		_, err := s.processingRadius(ctx, query.Radius)
 		if err != nil {
 			return nil, err
 		}
 	}
-	if query.Status != "" {
-		if query.Status != StatusActive && query.Status != StatusArchived && query.Status != StatusResolved {
-			return nil, fmt.Errorf("invalid status")
+	for _, status := range query.Statuses {
+		if !isKnownStatus(status) {
+			return nil, fmt.Errorf("invalid status: %s", status)
 		}
 	}
+	if query.Sort == "" {
+		query.Sort = DefaultIncidentSort
+	}
+	sortField, sortDesc, err := parseIncidentSort(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if query.Cursor != "" && query.PageNum != nil {
+		return nil, fmt.Errorf("page cannot be combined with cursor")
+	}
+	pageSize := s.config.MaxRowsInPage
+	if query.Limit != nil {
+		if *query.Limit > s.config.MaxRowsInPage {
+			return nil, fmt.Errorf("limit cannot be > %d", s.config.MaxRowsInPage)
+		}
+		pageSize = *query.Limit
+	}
+
+	entit := s.toPaginationEntity(ctx, query, sortField, sortDesc)
+	if query.Cursor != "" {
+		entit.After, err = decodeIncidentCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	count, err := s.db.GetCountRows(ctx, tx)
+	count, err := s.db.GetCountRows(ctx, entit, tx)
 	if err != nil {
 		return nil, err
 	}
-	offset := 0
-	limit := 0
-	pages := 0
 	var pageNum *int
-	pages = s.GetCountPages(count)
-	if query.PageNum != nil {
+	pages := s.GetCountPages(count, pageSize)
+	// keyset mode is used when the client pages by cursor or only asks for a page size,
+	// one extra row is requested to know whether the next page exists
+	keyset := query.Cursor != "" || (query.Limit != nil && query.PageNum == nil)
+	switch {
+	case query.PageNum != nil:
 		if *query.PageNum > pages {
 			return nil, fmt.Errorf("invalid page: max %d", pages)
 		}
 		pageNum = query.PageNum
-		offset = s.config.MaxRowsInPage * (*query.PageNum - 1)
-		limit = s.config.MaxRowsInPage
+		entit.Offset = pageSize * (*query.PageNum - 1)
+		entit.Limit = pageSize
+	case keyset:
+		entit.Limit = pageSize + 1
 	}
-	entit := s.toPaginationEntity(query, offset, limit)
 	read, err := s.db.GetPaginationIncidentsInfo(ctx, entit, tx)
 	if err != nil {
 		return nil, err
@@ -382,25 +550,28 @@ func (s *Service) GetPagination(ctx context.Context, query *dto.PaginationQueryP
 		return nil, err
 	}
 
+	nextCursor := ""
+	if keyset && len(read) > pageSize {
+		read = read[:pageSize]
+		nextCursor, err = encodeIncidentCursor(query.Sort, read[len(read)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	res := []*dto.IncidentAdminResponse{}
 
+	// pages are read without row locks and are not cached: a concurrent write could be overwritten
+	// by the older copy, see cacheIncident
 	for _, model := range read {
-		if model.IsActive {
-			if s.cache != nil {
-				err := s.cache.SetActiveIncident(ctx, model)
-				if err != nil {
-					s.cacheLogger.Printf("ERROR IN SET WITH ID: %s, err: %s\n", model.Id, err.Error())
-				}
-			}
-		}
 		dto := dto.CreateAdminResponse(model, nil)
 		res = append(res, dto)
 	}
-	return dto.ToPaginationResponse(res, pages, count, pageNum), nil
+	return dto.ToPaginationResponse(res, pages, count, pageNum, query.Sort, nextCursor), nil
 }
 
-func (s *Service) GetCountPages(countRows int) int {
-	res := float64(countRows) / float64(s.config.MaxRowsInPage)
+func (s *Service) GetCountPages(countRows, pageSize int) int {
+	res := float64(countRows) / float64(pageSize)
 	integerPart, fractionalPart := math.Modf(res)
 	if fractionalPart != 0 {
 		integerPart++
@@ -408,23 +579,50 @@ func (s *Service) GetCountPages(countRows int) int {
 	return int(integerPart)
 }
 
-func (s *Service) toPaginationEntity(query *dto.PaginationQueryParams, offset, limit int) *entities.PaginationIncidents {
+func (s *Service) toPaginationEntity(ctx context.Context, query *dto.PaginationQueryParams, sortField string, sortDesc bool) *entities.PaginationIncidents {
 	id := ""
 	if query.ID != nil {
 		id = *query.ID
 	}
 	res := &entities.PaginationIncidents{
-		Offset: offset,
-		Limit:  limit,
-		Status: query.Status,
-		Name:   query.Name,
-		Type:   query.Type,
-		Radius: query.Radius,
-		ID:     id,
+		TenantID:     identity.Tenant(ctx),
+		Statuses:     query.Statuses,
+		Name:         query.Name,
+		NameContains: query.NameContains,
+		Types:        query.Types,
+		Radius:       query.Radius,
+		RadiusMin:    query.RadiusMin,
+		RadiusMax:    query.RadiusMax,
+		ID:           id,
+		IsActive:     query.IsActive,
+		CreatedFrom:  toUTC(query.CreatedFrom),
+		CreatedTo:    toUTC(query.CreatedTo),
+		UpdatedFrom:  toUTC(query.UpdatedFrom),
+		UpdatedTo:    toUTC(query.UpdatedTo),
+		ResolvedFrom: toUTC(query.ResolvedFrom),
+		ResolvedTo:   toUTC(query.ResolvedTo),
+		Sort:         sortField,
+		SortDesc:     sortDesc,
+	}
+	if query.NearLatitude != nil && query.NearLongitude != nil && query.NearMeters != nil {
+		res.Near = &entities.NearPoint{
+			Latitude:  *query.NearLatitude,
+			Longitude: *query.NearLongitude,
+			Meters:    *query.NearMeters,
+		}
 	}
 	return res
 }
 
+// toUTC converts filter bounds to UTC because incident dates are stored without time zone.
+func toUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
 func (s *Service) LocationCheck(ctx context.Context, req *dto.LocationCheckRequest) (*dto.LocationCheckResponse, error) {
 	err := req.Validate()
 	if err != nil {
@@ -437,7 +635,8 @@ func (s *Service) LocationCheck(ctx context.Context, req *dto.LocationCheckReque
 	}
 	defer tx.Rollback()
 
-	checkId, err := s.db.RegistrationCheck(ctx, req.UserID, req.Latitude, req.Longitude, tx)
+	tenant := identity.Tenant(ctx)
+	checkId, createdDate, err := s.db.RegistrationCheck(ctx, tenant, req.UserID, req.Latitude, req.Longitude, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -445,7 +644,7 @@ func (s *Service) LocationCheck(ctx context.Context, req *dto.LocationCheckReque
 		return nil, fmt.Errorf("empty id before db method")
 	}
 	s.changeLogger.Printf("INFO: Create new check with id: %s", checkId)
-	destChecks, err := s.db.GetDetectedIncidents(ctx, req.Longitude, req.Latitude, tx)
+	destChecks, err := s.db.GetDetectedIncidents(ctx, tenant, req.Longitude, req.Latitude, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -458,7 +657,11 @@ func (s *Service) LocationCheck(ctx context.Context, req *dto.LocationCheckReque
 		dangersIds = append(dangersIds, check.Incident.Id)
 	}
 
-	err = s.db.UpdateCheckByID(ctx, dangersIds, checkId, isDanger, tx)
+	err = s.db.UpdateCheckByID(ctx, dangersIds, checkId, createdDate, isDanger, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.RegistrationCheckRollup(ctx, tenant, req.UserID, dangersIds, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -478,24 +681,51 @@ func (s *Service) LocationCheck(ctx context.Context, req *dto.LocationCheckReque
 	}
 	res.DetectedIncidentsID = userIncidents
 	if isDanger && s.wm != nil {
-		s.wm.AddToQueue(*res, "", "")
+		url, method := "", ""
+		if cfg := s.tenantConfig(ctx); cfg != nil {
+			url, method = cfg.WebhookURL, cfg.WebhookMethod
+		}
+		s.wm.AddToQueue(*res, tenant, url, method)
 	}
 	return res, nil
 }
 
-func (s *Service) GetChecksStatistics(ctx context.Context) (*dto.IncidentsStatResponse, error) {
+// GetChecksStatistics counts dangerous checks per incident in the requested window,
+// the tenant's STATS_TIME_WINDOW_MINUTES is used when the query sets neither from nor window_minutes.
+func (s *Service) GetChecksStatistics(ctx context.Context, query *dto.StatsQueryParams) (*dto.IncidentsStatResponse, error) {
+	tenant := identity.Tenant(ctx)
+	cfg := s.config.ForTenant(tenant)
+	end := statsWindowEnd(query.To)
+	window := time.Duration(cfg.StatsTimeWindow) * time.Minute
+	if query.WindowMinutes != nil {
+		window = time.Duration(*query.WindowMinutes) * time.Minute
+	}
+	start := end.Add(-window)
+	if query.From != nil {
+		start = query.From.UTC().Truncate(time.Minute)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("from cannot be after to")
+	}
+	filter := &entities.IncidentStatsFilter{
+		TenantID:        tenant,
+		From:            start,
+		To:              end,
+		IncludeInactive: query.IncludeInactive,
+		Types:           query.Types,
+	}
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	count, err := s.db.GetCountUniqueUsers(ctx, tx)
+	count, err := s.db.GetCountUniqueUsers(ctx, tenant, tx)
 	if err != nil {
 		return nil, err
 	}
-	fromTime := time.Now().UTC()
-	statistics, err := s.db.GetStaticsForIncidentsWithTimeWindow(ctx, tx, s.config.StatsTimeWindow)
+	statistics, err := s.db.GetStatisticsForIncidents(ctx, filter, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -503,6 +733,6 @@ func (s *Service) GetChecksStatistics(ctx context.Context) (*dto.IncidentsStatRe
 		return nil, err
 	}
 
-	return dto.ToIncidentsStatResponse(statistics, s.config.StatsTimeWindow, fromTime, count), nil
+	return dto.ToIncidentsStatResponse(statistics, filter, count), nil
 
 }
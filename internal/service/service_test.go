@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -156,7 +157,7 @@ func TestService_processingRadius(t *testing.T) {
 			req := &dto.RegistrationIncidentRequest{
 				RadiusInMeters: tc.requestRadius,
 			}
-			res, err := s.processingRadius(req.RadiusInMeters)
+			res, err := s.processingRadius(context.Background(), req.RadiusInMeters)
 			if err != nil {
 				if !strings.Contains(err.Error(), tc.expectedError) {
 					t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.expectedError)
@@ -200,6 +201,21 @@ func TestService_processingIsActive(t *testing.T) {
 			status:         StatusArchived,
 			expectedResult: false,
 		},
+		{
+			name:           "status_monitoring",
+			status:         StatusMonitoring,
+			expectedResult: true,
+		},
+		{
+			name:           "status_unverified",
+			status:         StatusUnverified,
+			expectedResult: false,
+		},
+		{
+			name:           "status_scheduled",
+			status:         StatusScheduled,
+			expectedResult: false,
+		},
 		{
 			name:           "invalid_status_empty",
 			status:         "",
@@ -572,7 +588,7 @@ func TestService_FromDtoToEntitie_Integration(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			entity, err := s.FromDtoToEntitie(tc.request)
+			entity, err := s.FromDtoToEntitie(context.Background(), tc.request)
 			if err != nil {
 				if tc.expectedError == "" {
 					t.Errorf("ERROR: got unexpected error: %v", err)
@@ -835,6 +851,25 @@ func Test_processingIncidentIDForUpdate(t *testing.T) {
 			res:  &entities.ReadIncident{Description: getPtrStr("old")},
 			resp: &dto.UpdateRequest{Description: getPtrStr("")},
 		},
+		{
+			name: "invalid_transition_resolved_to_scheduled",
+			res: &entities.ReadIncident{
+				Status: StatusResolved,
+			},
+			resp: &dto.UpdateRequest{
+				Status: getPtrStr(StatusScheduled),
+			},
+			wantedError: fmt.Errorf("status transition not allowed: resolved -> scheduled"),
+		},
+		{
+			name: "valid_transition_unverified_to_monitoring",
+			res: &entities.ReadIncident{
+				Status: StatusUnverified,
+			},
+			resp: &dto.UpdateRequest{
+				Status: getPtrStr(StatusMonitoring),
+			},
+		},
 		{
 			name:        "changes_radius_zero",
 			res:         &entities.ReadIncident{Description: getPtrStr("old")},
@@ -1085,3 +1120,244 @@ func TestToEntity(t *testing.T) {
 		})
 	}
 }
+
+func TestService_checkStatusTransition(t *testing.T) {
+	testCases := []struct {
+		name        string
+		from        string
+		to          string
+		wantedError string
+	}{
+		{
+			name: "active_to_resolved",
+			from: StatusActive,
+			to:   StatusResolved,
+		},
+		{
+			name: "resolved_to_active",
+			from: StatusResolved,
+			to:   StatusActive,
+		},
+		{
+			name: "scheduled_to_active",
+			from: StatusScheduled,
+			to:   StatusActive,
+		},
+		{
+			name:        "active_to_unverified",
+			from:        StatusActive,
+			to:          StatusUnverified,
+			wantedError: "status transition not allowed: active -> unverified, allowed: monitoring, resolved, archived",
+		},
+		{
+			name:        "archived_is_final",
+			from:        StatusArchived,
+			to:          StatusActive,
+			wantedError: "status transition not allowed: archived -> active, allowed: none",
+		},
+		{
+			name:        "unknown_from_status",
+			from:        "random",
+			to:          StatusActive,
+			wantedError: "status transition not allowed: random -> active, allowed: none",
+		},
+	}
+
+	s := &Service{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.checkStatusTransition(tc.from, tc.to)
+			if err != nil {
+				if tc.wantedError == "" {
+					t.Errorf("unexpected error: %s\n", err.Error())
+				} else if err.Error() != tc.wantedError {
+					t.Errorf("ERROR: got: %s, expect: %s\n", err.Error(), tc.wantedError)
+				}
+			} else if tc.wantedError != "" {
+				t.Errorf("ERROR: got: nil, expect: %s\n", tc.wantedError)
+			}
+		})
+	}
+}
+
+func Test_diffIncidents(t *testing.T) {
+	desc := "description"
+	base := func() *entities.ReadIncident {
+		return &entities.ReadIncident{
+			Name:        "name",
+			Type:        "type",
+			Description: &desc,
+			Latitude:    "55.1",
+			Longitude:   "37.1",
+			Radius:      100,
+			Status:      StatusActive,
+			IsActive:    true,
+		}
+	}
+	resolved := base()
+	resolved.Status = StatusResolved
+	resolved.IsActive = false
+	resolvedDate := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	resolved.ResolvedDate = &resolvedDate
+
+	renamed := base()
+	renamed.Name = "new name"
+	renamed.Description = nil
+
+	testCases := []struct {
+		name     string
+		before   *entities.ReadIncident
+		after    *entities.ReadIncident
+		expected map[string]entities.FieldChange
+	}{
+		{
+			name:     "no_changes",
+			before:   base(),
+			after:    base(),
+			expected: map[string]entities.FieldChange{},
+		},
+		{
+			name:   "status_resolved",
+			before: base(),
+			after:  resolved,
+			expected: map[string]entities.FieldChange{
+				"status":        {Before: StatusActive, After: StatusResolved},
+				"is_active":     {Before: true, After: false},
+				"resolved_date": {Before: nil, After: "2026-01-02T03:04:05Z"},
+			},
+		},
+		{
+			name:   "name_changed_description_cleared",
+			before: base(),
+			after:  renamed,
+			expected: map[string]entities.FieldChange{
+				"name":        {Before: "name", After: "new name"},
+				"description": {Before: desc, After: nil},
+			},
+		},
+		{
+			name:   "created",
+			before: nil,
+			after:  renamed,
+			expected: map[string]entities.FieldChange{
+				"name":      {Before: nil, After: "new name"},
+				"type":      {Before: nil, After: "type"},
+				"latitude":  {Before: nil, After: "55.1"},
+				"longitude": {Before: nil, After: "37.1"},
+				"radius":    {Before: nil, After: 100},
+				"status":    {Before: nil, After: StatusActive},
+				"is_active": {Before: nil, After: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffIncidents(tc.before, tc.after)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("changes count: got: %d (%v), expect: %d\n", len(got), got, len(tc.expected))
+			}
+			for field, change := range tc.expected {
+				gotChange, ok := got[field]
+				if !ok {
+					t.Errorf("field %s not found in changes\n", field)
+					continue
+				}
+				if gotChange != change {
+					t.Errorf("field %s: got: %v, expect: %v\n", field, gotChange, change)
+				}
+			}
+		})
+	}
+}
+
+func Test_incidentCursor(t *testing.T) {
+	updated := time.Date(2026, 10, 19, 9, 30, 0, 123456000, time.UTC)
+	incident := &entities.ReadIncident{
+		Id:          "3f1c2b9e-7a4d-4a51-9c1e-2b7f8d6a0e11",
+		Name:        "fire",
+		Radius:      250,
+		Status:      StatusActive,
+		CreatedDate: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		UpdatedDate: &updated,
+	}
+	testCases := []struct {
+		sort          string
+		expectedValue any
+	}{
+		{sort: "-created", expectedValue: incident.CreatedDate},
+		{sort: "updated", expectedValue: updated},
+		{sort: "name", expectedValue: "fire"},
+		{sort: "-radius", expectedValue: 250},
+		{sort: "status", expectedValue: StatusActive},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.sort, func(t *testing.T) {
+			cursor, err := encodeIncidentCursor(tc.sort, incident)
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			got, err := decodeIncidentCursor(cursor, tc.sort)
+			if err != nil {
+				t.Fatalf("unexpected error: %s\n", err.Error())
+			}
+			if got.ID != incident.Id {
+				t.Errorf("id: got: %s, expect: %s\n", got.ID, incident.Id)
+			}
+			if expectedTime, ok := tc.expectedValue.(time.Time); ok {
+				gotTime, _ := got.Value.(time.Time)
+				if !gotTime.Equal(expectedTime) {
+					t.Errorf("value: got: %v, expect: %v\n", got.Value, tc.expectedValue)
+				}
+			} else if got.Value != tc.expectedValue {
+				t.Errorf("value: got: %v, expect: %v\n", got.Value, tc.expectedValue)
+			}
+		})
+	}
+
+	cursor, err := encodeIncidentCursor("name", incident)
+	if err != nil {
+		t.Fatalf("unexpected error: %s\n", err.Error())
+	}
+	if _, err := decodeIncidentCursor(cursor, "-name"); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+		t.Errorf("cursor issued for another sort must be rejected, got: %v\n", err)
+	}
+}
+
+func Test_checksPartitionPeriods(t *testing.T) {
+	now := time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		interval      string
+		expectedNames []string
+		expectedTo    time.Time
+	}{
+		{
+			interval:      config.PartitionIntervalMonth,
+			expectedNames: []string{"checks_p202612", "checks_p202701", "checks_p202702"},
+			expectedTo:    time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			interval:      config.PartitionIntervalDay,
+			expectedNames: []string{"checks_p20261231", "checks_p20270101", "checks_p20270102"},
+			expectedTo:    time.Date(2027, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.interval, func(t *testing.T) {
+			periods := checksPartitionPeriods(now, tc.interval, 2)
+			names := []string{}
+			for i, period := range periods {
+				names = append(names, period.Name)
+				if i > 0 && !period.From.Equal(periods[i-1].To) {
+					t.Errorf("period %s does not start at the end of the previous one\n", period.Name)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tc.expectedNames, ",") {
+				t.Errorf("names: got: %v, expect: %v\n", names, tc.expectedNames)
+			}
+			if to := periods[len(periods)-1].To; !to.Equal(tc.expectedTo) {
+				t.Errorf("end: got: %v, expect: %v\n", to, tc.expectedTo)
+			}
+		})
+	}
+}
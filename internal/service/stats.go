@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+const (
+	DefaultTimeSeriesBucket = "1h"
+	DefaultTimeSeriesWindow = 24 * time.Hour
+	MaxTimeSeriesBuckets    = 2000
+)
+
+var timeSeriesBucketNames = []string{"5m", "1h", "1d"}
+
+var timeSeriesBuckets = map[string]time.Duration{
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// statsWindowEnd puts the end of a statistics window on the minute grid of the rollups,
+// without to the window ends after the current minute.
+func statsWindowEnd(to *time.Time) time.Time {
+	if to == nil {
+		return time.Now().UTC().Truncate(time.Minute).Add(time.Minute)
+	}
+	return to.UTC().Truncate(time.Minute)
+}
+
+// GetChecksTimeSeries counts checks of the tenant per bucket, the buckets start at from.
+// Without bounds the last DefaultTimeSeriesWindow is counted.
+func (s *Service) GetChecksTimeSeries(ctx context.Context, query *dto.ChecksTimeSeriesQueryParams) (*dto.ChecksTimeSeriesResponse, error) {
+	name := query.Bucket
+	if name == "" {
+		name = DefaultTimeSeriesBucket
+	}
+	bucket, ok := timeSeriesBuckets[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid bucket: must be one of %v", timeSeriesBucketNames)
+	}
+	end := statsWindowEnd(query.To)
+	start := end.Add(-DefaultTimeSeriesWindow)
+	if query.From != nil {
+		start = query.From.UTC().Truncate(time.Minute)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("from cannot be after to")
+	}
+	if end.Sub(start) > MaxTimeSeriesBuckets*bucket {
+		return nil, fmt.Errorf("too many buckets: window must be at most %d buckets of %s", MaxTimeSeriesBuckets, name)
+	}
+
+	tenant := identity.Tenant(ctx)
+	if query.IncidentID != "" {
+		read, err := s.db.GetInfoByIncidentID(ctx, query.IncidentID, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTenant(ctx, read); err != nil {
+			return nil, err
+		}
+	}
+	filter := &entities.ChecksTimeSeriesFilter{
+		TenantID:   tenant,
+		From:       start,
+		To:         end,
+		Bucket:     bucket,
+		IncidentID: query.IncidentID,
+		Type:       query.Type,
+	}
+	buckets, err := s.db.GetChecksTimeSeries(ctx, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToChecksTimeSeriesResponse(filter, name, buckets), nil
+}
+
+const (
+	DefaultHeatmapPrecision = 6
+	MaxHeatmapCells         = 10000
+)
+
+// GetChecksHeatmap counts checks of the tenant per geohash cell, with the dangerous layer
+// only the cells with dangerous checks are returned. Without bounds the last DefaultChecksExportWindow is counted.
+func (s *Service) GetChecksHeatmap(ctx context.Context, query *dto.HeatmapQueryParams) (*dto.HeatmapResponse, error) {
+	end := time.Now().UTC()
+	if query.To != nil {
+		end = query.To.UTC()
+	}
+	start := end.Add(-DefaultChecksExportWindow)
+	if query.From != nil {
+		start = query.From.UTC()
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("from cannot be after to")
+	}
+	if end.Sub(start) > MaxChecksExportWindow {
+		return nil, fmt.Errorf("heatmap window cannot be longer than %d days", int(MaxChecksExportWindow.Hours()/24))
+	}
+	filter := &entities.HeatmapFilter{
+		TenantID:      identity.Tenant(ctx),
+		From:          start,
+		To:            end,
+		Precision:     DefaultHeatmapPrecision,
+		DangerousOnly: query.Layer == dto.HeatmapLayerDangerous,
+		Limit:         MaxHeatmapCells + 1,
+	}
+	if query.Precision != nil {
+		filter.Precision = *query.Precision
+	}
+	if query.BBox != nil {
+		filter.BBox = &entities.BBox{MinLon: query.BBox[0], MinLat: query.BBox[1], MaxLon: query.BBox[2], MaxLat: query.BBox[3]}
+	}
+	cells, err := s.db.GetChecksHeatmap(ctx, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cells) > MaxHeatmapCells {
+		return nil, fmt.Errorf("too many cells: heatmap must be at most %d cells, use smaller bbox or precision", MaxHeatmapCells)
+	}
+	return dto.ToHeatmapResponse(cells)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/Piccadilly98/incidents_service/internal/repository"
+)
+
+type statusInfo struct {
+	// isActive - incident is a danger zone for location checks and is cached
+	isActive bool
+	// isClosed - resolved_date is set when incident moves to this status
+	isClosed bool
+}
+
+var statuses = map[string]statusInfo{
+	StatusUnverified: {isActive: false, isClosed: false},
+	StatusScheduled:  {isActive: false, isClosed: false},
+	StatusActive:     {isActive: true, isClosed: false},
+	StatusMonitoring: {isActive: true, isClosed: false},
+	StatusResolved:   {isActive: false, isClosed: true},
+	StatusArchived:   {isActive: false, isClosed: true},
+}
+
+// statusTransitions - declarative table of allowed status changes:
+// current status -> statuses to which the incident can be moved.
+// Archived incidents are final and cannot change status.
+var statusTransitions = map[string][]string{
+	StatusUnverified: {StatusActive, StatusMonitoring, StatusScheduled, StatusResolved, StatusArchived},
+	StatusScheduled:  {StatusActive, StatusResolved, StatusArchived},
+	StatusActive:     {StatusMonitoring, StatusResolved, StatusArchived},
+	StatusMonitoring: {StatusActive, StatusResolved, StatusArchived},
+	StatusResolved:   {StatusActive, StatusMonitoring, StatusArchived},
+	StatusArchived:   {},
+}
+
+func isKnownStatus(status string) bool {
+	_, ok := statuses[status]
+	return ok
+}
+
+func allowedTransitions(from string) []string {
+	return statusTransitions[from]
+}
+
+func (s *Service) checkStatusTransition(from, to string) error {
+	allowed := allowedTransitions(from)
+	if slices.Contains(allowed, to) {
+		return nil
+	}
+	allowedStr := "none"
+	if len(allowed) > 0 {
+		allowedStr = strings.Join(allowed, ", ")
+	}
+	return fmt.Errorf("status transition not allowed: %s -> %s, allowed: %s", from, to, allowedStr)
+}
+
+func (s *Service) recordStatusTransition(ctx context.Context, id string, from *string, to string, reason *string, exec repository.Executor) error {
+	return s.db.RegistrationStatusTransition(ctx, &entities.StatusTransition{
+		IncidentID: id,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      identity.Actor(ctx),
+		Reason:     reason,
+	}, exec)
+}
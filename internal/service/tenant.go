@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Piccadilly98/incidents_service/internal/config"
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+// tenantConfig returns the settings of the caller tenant, nil when the service runs without config.
+func (s *Service) tenantConfig(ctx context.Context) *config.Config {
+	return s.config.ForTenant(identity.Tenant(ctx))
+}
+
+// checkTenant hides incidents of other tenants behind not found,
+// so an id of a foreign incident tells the caller nothing.
+func checkTenant(ctx context.Context, read *entities.ReadIncident) error {
+	if read.TenantID != identity.Tenant(ctx) {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+)
+
+// RestoreIncidentByID returns force deleted or archived incident back to work.
+// Restore is an explicit admin action, so the transition table is not applied:
+// archived incident becomes active by default, deleted incident gets back its last status.
+// The incident is locked like for other writes and expectedVersion is checked against it.
+func (s *Service) RestoreIncidentByID(ctx context.Context, id string, req *dto.RestoreRequest, expectedVersion *int) (*dto.IncidentAdminResponse, error) {
+	if err := s.authorize(ctx, identity.PermIncidentsRestore); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deleted := false
+	read, err := s.getIncidentForWrite(ctx, id, expectedVersion, tx)
+	if errors.Is(err, sql.ErrNoRows) {
+		read, err = s.db.GetDeletedInfoByIncidentIDForUpdate(ctx, id, tx)
+		if err == nil {
+			err = checkIncidentForWrite(ctx, read, expectedVersion)
+		}
+		deleted = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !deleted && read.Status != StatusArchived {
+		return nil, fmt.Errorf("restore not allowed: incident is not archived or deleted")
+	}
+
+	toStatus := read.Status
+	if !deleted {
+		toStatus = StatusActive
+	}
+	if req.Status != nil {
+		if !isKnownStatus(*req.Status) {
+			return nil, fmt.Errorf("invalid status")
+		}
+		toStatus = *req.Status
+	}
+	if !deleted && toStatus == StatusArchived {
+		return nil, fmt.Errorf("restore not allowed: incident already archived")
+	}
+
+	resolvedTime := read.ResolvedDate
+	if toStatus != read.Status {
+		resolvedTime = s.processingResolvedTime(toStatus)
+	}
+	restored, err := s.db.RestoreIncidentByID(ctx, id, &entities.RestoreIncident{
+		Status:       toStatus,
+		IsActive:     statuses[toStatus].isActive,
+		ResolvedTime: resolvedTime,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+	if toStatus != read.Status {
+		fromStatus := read.Status
+		err = s.recordStatusTransition(ctx, id, &fromStatus, toStatus, req.Reason, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.writeAudit(ctx, AuditActionRestore, id, read, restored, tx)
+	if err != nil {
+		return nil, err
+	}
+	s.cacheIncident(ctx, restored.TenantID, id, restored)
+	if err = s.commitCached(ctx, tx, restored.TenantID, id); err != nil {
+		return nil, err
+	}
+	s.changeLogger.Printf("INFO: incident %s restored with status %s", id, toStatus)
+	return dto.CreateAdminResponse(restored, nil), nil
+}
+
+// PurgeDeletedIncidents permanently removes incidents which stayed in trash longer than retention period.
+func (s *Service) PurgeDeletedIncidents(ctx context.Context) (int64, error) {
+	deletedBefore := time.Now().UTC().Add(-time.Duration(s.config.TrashRetentionDays) * 24 * time.Hour)
+	count, err := s.db.PurgeDeletedIncidents(ctx, deletedBefore, nil)
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		s.changeLogger.Printf("CRITICAL: %d incidents purged from trash", count)
+	}
+	return count, nil
+}
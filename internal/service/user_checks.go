@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Piccadilly98/incidents_service/internal/identity"
+	"github.com/Piccadilly98/incidents_service/internal/models/dto"
+	"github.com/Piccadilly98/incidents_service/internal/models/entities"
+	"github.com/google/uuid"
+)
+
+func (s *Service) GetUserChecks(ctx context.Context, query *dto.UserChecksQueryParams) (*dto.UserChecksResponse, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	filter := &entities.UserChecksFilter{
+		TenantID:      identity.Tenant(ctx),
+		UserID:        query.UserID,
+		From:          toUTC(query.From),
+		To:            toUTC(query.To),
+		DangerousOnly: query.DangerousOnly,
+	}
+	if query.PageNum != nil {
+		filter.Limit = s.config.MaxRowsInPage
+		filter.Offset = s.config.MaxRowsInPage * (*query.PageNum - 1)
+	}
+	checks, err := s.db.GetUserChecks(ctx, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToUserChecksResponse(query.UserID, checks, query.PageNum), nil
+}
+
+// ForgetUser serves data subject requests: erase deletes all checks of the user,
+// anonymize keeps them for statistics, each check under its own random id with rounded coordinates,
+// so the checks cannot be joined into a movement trail of one person.
+// The user id is not written to the log, the request itself is personal data.
+func (s *Service) ForgetUser(ctx context.Context, userID, mode string) (*dto.ForgetUserResponse, error) {
+	if err := s.authorize(ctx, identity.PermChecksForget); err != nil {
+		return nil, err
+	}
+	if err := dto.ValidateUserID(userID); err != nil {
+		return nil, err
+	}
+	if mode == "" {
+		mode = ForgetModeErase
+	}
+	if mode != ForgetModeErase && mode != ForgetModeAnonymize {
+		return nil, fmt.Errorf("invalid mode: must be one of %s, %s", ForgetModeErase, ForgetModeAnonymize)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tenant := identity.Tenant(ctx)
+	var count int64
+	if mode == ForgetModeErase {
+		count, err = s.db.DeleteUserChecks(ctx, tenant, userID, tx)
+	} else {
+		count, err = s.db.AnonymizeUserChecks(ctx, &entities.UserAnonymization{
+			TenantID:    tenant,
+			UserID:      userID,
+			Pseudonym:   anonymizedUserPrefix + uuid.NewString(),
+			CheckPrefix: anonymizedUserPrefix,
+			Decimals:    anonymizedCoordinateDecimals,
+		}, tx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	if s.cache != nil {
+		if err := s.cache.DeleteUserState(ctx, tenant, userID); err != nil {
+			s.cacheLogger.Printf("ERROR IN DELETE USER STATE, err: %s\n", err.Error())
+		}
+	}
+	// queued webhooks hold the user id and coordinates of the checks
+	if s.wm != nil {
+		removed, err := s.wm.RemoveUserTasks(ctx, tenant, userID)
+		if err != nil {
+			s.cacheLogger.Printf("ERROR IN REMOVE USER WEBHOOKS, err: %s\n", err.Error())
+		} else if removed > 0 {
+			s.changeLogger.Printf("INFO: %d queued webhooks of a user of tenant %s removed", removed, tenant)
+		}
+	}
+	s.changeLogger.Printf("CRITICAL: %d checks of a user of tenant %s processed with mode %s by %s", count, tenant, mode, identity.Actor(ctx))
+	return &dto.ForgetUserResponse{UserID: userID, Mode: mode, ChecksAffected: count}, nil
+}
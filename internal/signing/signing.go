@@ -0,0 +1,41 @@
+// Package signing implements the request signature of mobile apps.
+//
+// The app signs the string
+//
+//	METHOD \n REQUEST_URI \n TIMESTAMP \n NONCE \n hex(sha256(BODY))
+//
+// with HMAC-SHA256 and its secret, and sends the hex signature with the key id,
+// timestamp (unix seconds) and nonce in the headers below.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	HeaderKeyID     = "X-App-Key-ID"
+	HeaderTimestamp = "X-App-Timestamp"
+	HeaderNonce     = "X-App-Nonce"
+	HeaderSignature = "X-App-Signature"
+)
+
+func canonical(method, requestURI, timestamp, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{strings.ToUpper(method), requestURI, timestamp, nonce, hex.EncodeToString(sum[:])}, "\n")
+}
+
+// Sign returns the hex signature of the request.
+func Sign(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical(method, requestURI, timestamp, nonce, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify compares the signature in constant time.
+func Verify(secret, signature, method, requestURI, timestamp, nonce string, body []byte) bool {
+	expected := Sign(secret, method, requestURI, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,33 @@
+package signing
+
+import "testing"
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"user_id":"u1","latitude":"55.75","longitude":"37.61"}`)
+	signature := Sign("secret", "POST", "/api/v1/location/check", "1760000000", "n1", body)
+
+	testCases := []struct {
+		name      string
+		secret    string
+		method    string
+		uri       string
+		timestamp string
+		nonce     string
+		body      []byte
+		expected  bool
+	}{
+		{name: "valid", secret: "secret", method: "post", uri: "/api/v1/location/check", timestamp: "1760000000", nonce: "n1", body: body, expected: true},
+		{name: "other_secret", secret: "other", method: "POST", uri: "/api/v1/location/check", timestamp: "1760000000", nonce: "n1", body: body},
+		{name: "other_path", secret: "secret", method: "POST", uri: "/api/v1/location/check?x=1", timestamp: "1760000000", nonce: "n1", body: body},
+		{name: "other_timestamp", secret: "secret", method: "POST", uri: "/api/v1/location/check", timestamp: "1760000001", nonce: "n1", body: body},
+		{name: "other_nonce", secret: "secret", method: "POST", uri: "/api/v1/location/check", timestamp: "1760000000", nonce: "n2", body: body},
+		{name: "other_body", secret: "secret", method: "POST", uri: "/api/v1/location/check", timestamp: "1760000000", nonce: "n1", body: []byte(`{}`)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Verify(tc.secret, signature, tc.method, tc.uri, tc.timestamp, tc.nonce, tc.body); got != tc.expected {
+				t.Errorf("got %v, expect %v", got, tc.expected)
+			}
+		})
+	}
+}
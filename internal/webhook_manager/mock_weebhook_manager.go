@@ -1,6 +1,7 @@
 package webhook_manager
 
 import (
+	"context"
 	"sync"
 
 	"github.com/Piccadilly98/incidents_service/internal/models/dto"
@@ -15,14 +16,29 @@ func NewMockWebhookManager() *MockWebhookManager {
 	return &MockWebhookManager{}
 }
 
-func (mw *MockWebhookManager) AddToQueue(result dto.LocationCheckResponse, url string, method string) {
+func (mw *MockWebhookManager) AddToQueue(result dto.LocationCheckResponse, tenant, url, method string) {
 	mw.mu.Lock()
 	defer mw.mu.Unlock()
 	mw.Storage = append(mw.Storage, &dto.WebhookTask{
 		Dto:    result,
+		Tenant: tenant,
 		Method: method,
 		Url:    url,
 	})
 }
 
+func (mw *MockWebhookManager) RemoveUserTasks(ctx context.Context, tenant, userID string) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	kept := []*dto.WebhookTask{}
+	for _, task := range mw.Storage {
+		if task.Tenant != tenant || task.Dto.UserID != userID {
+			kept = append(kept, task)
+		}
+	}
+	removed := len(mw.Storage) - len(kept)
+	mw.Storage = kept
+	return removed, nil
+}
+
 func (mw *MockWebhookManager) Stop() {}
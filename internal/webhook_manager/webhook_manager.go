@@ -81,7 +81,7 @@ func (wm *WebhookManager) Stop() {
 	wm.cancel()
 }
 
-func (wm *WebhookManager) AddToQueue(result dto.LocationCheckResponse, url, method string) {
+func (wm *WebhookManager) AddToQueue(result dto.LocationCheckResponse, tenant, url, method string) {
 	if !result.IsDanger {
 		return
 	}
@@ -93,6 +93,7 @@ func (wm *WebhookManager) AddToQueue(result dto.LocationCheckResponse, url, meth
 	}
 	body := &dto.WebhookTask{
 		Dto:    result,
+		Tenant: tenant,
 		Url:    url,
 		Method: method,
 	}
@@ -105,6 +106,11 @@ func (wm *WebhookManager) AddToQueue(result dto.LocationCheckResponse, url, meth
 	}()
 }
 
+// RemoveUserTasks drops webhooks of the user which are still waiting in the queue.
+func (wm *WebhookManager) RemoveUserTasks(ctx context.Context, tenant, userID string) (int, error) {
+	return wm.cacheQueue.RemoveUserTasks(ctx, tenant, userID)
+}
+
 func (wm *WebhookManager) StartProcessing() {
 	for {
 		select {
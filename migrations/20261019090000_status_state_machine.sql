@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_status_check;
+ALTER TABLE incidents ADD CONSTRAINT incidents_status_check
+    CHECK (status IN ('unverified', 'scheduled', 'active', 'monitoring', 'resolved', 'archived'));
+
+CREATE TABLE IF NOT EXISTS incident_status_transitions(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    reason TEXT,
+    created_date TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_status_transitions_incident ON incident_status_transitions (incident_id, created_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incident_status_transitions;
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_status_check;
+ALTER TABLE incidents ADD CONSTRAINT incidents_status_check
+    CHECK (status IN ('active', 'resolved', 'archived'));
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS incident_audit(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    incident_id UUID NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    request_id VARCHAR(100),
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_date TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_incident_audit_incident ON incident_audit (incident_id, created_date);
+CREATE INDEX IF NOT EXISTS idx_incident_audit_created ON incident_audit (created_date DESC);
+CREATE INDEX IF NOT EXISTS idx_incident_audit_actor ON incident_audit (actor);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incident_audit;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS deleted_date TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_incidents_deleted_date ON incidents (deleted_date) WHERE deleted_date IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM incidents WHERE deleted_date IS NOT NULL;
+DROP INDEX IF EXISTS idx_incidents_deleted_date;
+ALTER TABLE incidents DROP COLUMN IF EXISTS deleted_date;
+-- +goose StatementEnd
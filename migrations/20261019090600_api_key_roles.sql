@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keys issued before roles had full access, they keep it as admins
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
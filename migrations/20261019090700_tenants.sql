@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+-- names are unique inside a tenant, different cities may have incidents with the same name
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_name_key;
+ALTER TABLE incidents ADD CONSTRAINT incidents_tenant_name_key UNIQUE (tenant_id, name);
+CREATE INDEX IF NOT EXISTS idx_incidents_tenant_created ON incidents (tenant_id, created_date);
+
+ALTER TABLE checks ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_checks_tenant_created ON checks (tenant_id, created_date);
+
+ALTER TABLE incident_audit ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_incident_audit_tenant_created ON incident_audit (tenant_id, created_date);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(63) NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_incident_audit_tenant_created;
+ALTER TABLE incident_audit DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_checks_tenant_created;
+ALTER TABLE checks DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_incidents_tenant_created;
+ALTER TABLE incidents DROP CONSTRAINT IF EXISTS incidents_tenant_name_key;
+ALTER TABLE incidents ADD CONSTRAINT incidents_name_key UNIQUE (name);
+ALTER TABLE incidents DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
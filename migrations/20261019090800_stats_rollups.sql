@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- per minute counters of checks, statistics endpoints read them instead of scanning checks
+CREATE TABLE IF NOT EXISTS check_rollups(
+    tenant_id VARCHAR(63) NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    checks INTEGER NOT NULL DEFAULT 0,
+    dangerous_checks INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, bucket_start)
+);
+-- exact sets of users per minute, unique users of a window are counted over them
+CREATE TABLE IF NOT EXISTS check_rollup_users(
+    tenant_id VARCHAR(63) NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (tenant_id, bucket_start, user_id)
+);
+-- dangerous checks per incident from detected_incident_ids
+CREATE TABLE IF NOT EXISTS incident_check_rollups(
+    tenant_id VARCHAR(63) NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    incident_id UUID NOT NULL,
+    dangerous_checks INTEGER NOT NULL DEFAULT 0,
+    first_check_date TIMESTAMP NOT NULL,
+    last_check_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, bucket_start, incident_id)
+);
+CREATE TABLE IF NOT EXISTS incident_check_rollup_users(
+    tenant_id VARCHAR(63) NOT NULL,
+    bucket_start TIMESTAMP NOT NULL,
+    incident_id UUID NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    PRIMARY KEY (tenant_id, bucket_start, incident_id, user_id)
+);
+
+INSERT INTO check_rollups(tenant_id, bucket_start, checks, dangerous_checks)
+SELECT tenant_id, date_trunc('minute', created_date), COUNT(*), COUNT(*) FILTER (WHERE is_danger)
+FROM checks GROUP BY 1, 2
+ON CONFLICT DO NOTHING;
+INSERT INTO check_rollup_users(tenant_id, bucket_start, user_id)
+SELECT DISTINCT tenant_id, date_trunc('minute', created_date), user_id FROM checks
+ON CONFLICT DO NOTHING;
+INSERT INTO incident_check_rollups(tenant_id, bucket_start, incident_id, dangerous_checks, first_check_date, last_check_date)
+SELECT c.tenant_id, date_trunc('minute', c.created_date), d.incident_id, COUNT(*), MIN(c.created_date), MAX(c.created_date)
+FROM checks c CROSS JOIN LATERAL unnest(c.detected_incident_ids) AS d(incident_id)
+WHERE c.is_danger = true
+GROUP BY 1, 2, 3
+ON CONFLICT DO NOTHING;
+INSERT INTO incident_check_rollup_users(tenant_id, bucket_start, incident_id, user_id)
+SELECT DISTINCT c.tenant_id, date_trunc('minute', c.created_date), d.incident_id, c.user_id
+FROM checks c CROSS JOIN LATERAL unnest(c.detected_incident_ids) AS d(incident_id)
+WHERE c.is_danger = true
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incident_check_rollup_users;
+DROP TABLE IF EXISTS incident_check_rollups;
+DROP TABLE IF EXISTS check_rollup_users;
+DROP TABLE IF EXISTS check_rollups;
+-- +goose StatementEnd
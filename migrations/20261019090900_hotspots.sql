@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- clusters of checks without detected incidents, candidates for new incidents
+CREATE TABLE IF NOT EXISTS hotspots(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    latitude DECIMAL(10, 8) NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DECIMAL(11, 8) NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    coordinates GEOGRAPHY(POINT, 4326) GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)) STORED,
+    radius INTEGER NOT NULL,
+    checks INTEGER NOT NULL,
+    unique_users INTEGER NOT NULL,
+    first_check_date TIMESTAMP NOT NULL,
+    last_check_date TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'candidate' CHECK (status IN ('candidate', 'promoted', 'dismissed')),
+    incident_id UUID REFERENCES incidents(id) ON DELETE SET NULL,
+    detected_date TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_date TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_hotspots_tenant_status ON hotspots (tenant_id, status, last_check_date);
+CREATE INDEX IF NOT EXISTS idx_hotspots_coordinates ON hotspots USING GIST (coordinates);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS hotspots;
+-- +goose StatementEnd
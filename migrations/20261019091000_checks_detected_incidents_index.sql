@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- exposure report looks up checks by detected incident
+CREATE INDEX IF NOT EXISTS idx_checks_detected_incidents ON checks USING GIN (detected_incident_ids) WHERE is_danger = true;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_checks_detected_incidents;
+-- +goose StatementEnd
@@ -0,0 +1,78 @@
+-- +goose Up
+-- +goose StatementBegin
+-- checks are partitioned by created_date, so that old months are dropped or archived as a whole
+-- instead of deleting rows. The service creates new partitions ahead of time, checks_default only
+-- catches rows of a period without a partition.
+ALTER TABLE checks RENAME TO checks_unpartitioned;
+ALTER TABLE checks_unpartitioned RENAME CONSTRAINT checks_pkey TO checks_unpartitioned_pkey;
+DROP INDEX IF EXISTS idx_checks_coords;
+DROP INDEX IF EXISTS idx_checks_time_danger;
+DROP INDEX IF EXISTS idx_checks_tenant_created;
+DROP INDEX IF EXISTS idx_checks_detected_incidents;
+
+CREATE TABLE checks(
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id VARCHAR(100) NOT NULL,
+    latitude DECIMAL(10, 8) NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DECIMAL(11, 8) NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    coordinates GEOGRAPHY(POINT, 4326) GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)
+    ) STORED,
+    is_danger BOOLEAN DEFAULT false,
+    detected_incident_ids UUID[] DEFAULT '{}',
+    created_date TIMESTAMP NOT NULL DEFAULT NOW(),
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default',
+    PRIMARY KEY (id, created_date)
+) PARTITION BY RANGE (created_date);
+CREATE INDEX IF NOT EXISTS idx_checks_coords ON checks USING GIST (coordinates);
+CREATE INDEX IF NOT EXISTS idx_checks_time_danger ON checks (created_date DESC) WHERE is_danger = true;
+CREATE INDEX IF NOT EXISTS idx_checks_tenant_created ON checks (tenant_id, created_date);
+CREATE INDEX IF NOT EXISTS idx_checks_detected_incidents ON checks USING GIN (detected_incident_ids) WHERE is_danger = true;
+CREATE TABLE IF NOT EXISTS checks_default PARTITION OF checks DEFAULT;
+
+-- monthly partitions for the existing checks and the next three months
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+BEGIN
+    month_start := date_trunc('month', LEAST(COALESCE((SELECT MIN(created_date) FROM checks_unpartitioned), NOW()), NOW()));
+    WHILE month_start < date_trunc('month', NOW()) + INTERVAL '4 months' LOOP
+        EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF checks FOR VALUES FROM (%L) TO (%L)',
+            'checks_p' || to_char(month_start, 'YYYYMM'), month_start, month_start + INTERVAL '1 month');
+        month_start := month_start + INTERVAL '1 month';
+    END LOOP;
+END $$;
+
+INSERT INTO checks(id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date, tenant_id)
+SELECT id, user_id, latitude, longitude, is_danger, detected_incident_ids, COALESCE(created_date, NOW()), tenant_id
+FROM checks_unpartitioned;
+DROP TABLE checks_unpartitioned;
+
+-- detached partitions are moved here by CHECKS_RETENTION_MODE=archive
+CREATE SCHEMA IF NOT EXISTS checks_archive;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE checks RENAME TO checks_partitioned;
+CREATE TABLE checks(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(100) NOT NULL,
+    latitude DECIMAL(10, 8) NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DECIMAL(11, 8) NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    coordinates GEOGRAPHY(POINT, 4326) GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)
+    ) STORED,
+    is_danger BOOLEAN DEFAULT false,
+    detected_incident_ids UUID[] DEFAULT '{}',
+    created_date TIMESTAMP DEFAULT NOW(),
+    tenant_id VARCHAR(63) NOT NULL DEFAULT 'default'
+);
+INSERT INTO checks(id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date, tenant_id)
+SELECT id, user_id, latitude, longitude, is_danger, detected_incident_ids, created_date, tenant_id
+FROM checks_partitioned;
+DROP TABLE checks_partitioned;
+CREATE INDEX IF NOT EXISTS idx_checks_coords ON checks USING GIST (coordinates);
+CREATE INDEX IF NOT EXISTS idx_checks_time_danger ON checks (created_date DESC) WHERE is_danger = true;
+CREATE INDEX IF NOT EXISTS idx_checks_tenant_created ON checks (tenant_id, created_date);
+CREATE INDEX IF NOT EXISTS idx_checks_detected_incidents ON checks USING GIN (detected_incident_ids) WHERE is_danger = true;
+-- archived partitions are kept, they are plain tables after detach
+-- +goose StatementEnd
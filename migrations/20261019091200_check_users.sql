@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one row per user of a tenant, total_unique_user of the statistics is counted here instead of checks
+CREATE TABLE IF NOT EXISTS check_users(
+    tenant_id VARCHAR(63) NOT NULL,
+    user_id VARCHAR(100) NOT NULL,
+    last_check_date TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_check_users_last_check ON check_users (last_check_date);
+
+INSERT INTO check_users(tenant_id, user_id, last_check_date)
+SELECT tenant_id, user_id, MAX(bucket_start) FROM check_rollup_users GROUP BY 1, 2
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS check_users;
+-- +goose StatementEnd
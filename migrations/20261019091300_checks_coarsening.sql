@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a single row with the progress of coordinate coarsening, every run starts where the previous one stopped
+CREATE TABLE IF NOT EXISTS checks_coarsening(
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    coarsened_before TIMESTAMP NOT NULL,
+    decimals INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_checks_created ON checks (created_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_checks_created;
+DROP TABLE IF EXISTS checks_coarsening;
+-- +goose StatementEnd
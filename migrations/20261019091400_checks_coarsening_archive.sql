@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- coarsening now covers checks_archive as well, the progress is reset so that checks archived
+-- before are rounded by the next run
+DELETE FROM checks_coarsening;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
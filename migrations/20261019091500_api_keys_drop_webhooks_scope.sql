@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- webhooks are configured per tenant in TENANTS_FILE, the scope guarded nothing
+UPDATE api_keys SET scopes = array_remove(scopes, 'webhooks:admin')
+WHERE 'webhooks:admin' = ANY(scopes);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd